@@ -39,10 +39,19 @@ func (s *AppServeAppServer) CreateAppServeApp(ctx context.Context, in *pb.Create
 
 	log.Info("Handling request 'CreateAppServeApp' for contract id ", contractId)
 
-	id, taskId, err := asaAccessor.Create(contractId, appServeApp, appServeAppTask)
+	if code, err := validateContract(ctx, contractId); err != nil {
+		return &pb.CreateAppServeAppResponse{
+			Code: code,
+			Error: &pb.Error{
+				Msg: err.Error(),
+			},
+		}, err
+	}
+
+	id, taskId, err := asaAccessor.WithContext(ctx).Create(contractId, appServeApp, appServeAppTask)
 	if err != nil {
 		return &pb.CreateAppServeAppResponse{
-			Code: pb.Code_INTERNAL,
+			Code: errorCode(err, pb.Code_INTERNAL),
 			Error: &pb.Error{
 				Msg: err.Error(),
 			},
@@ -71,7 +80,7 @@ func (s *AppServeAppServer) UpdateAppServeApp(ctx context.Context, in *pb.Update
 
 	log.Info("Handling request 'UpdateAppServeApp' for AppServeApp ID ", appServeAppId)
 
-	taskId, err := asaAccessor.Update(appServeAppId, in.GetAppServeAppTask())
+	taskId, err := asaAccessor.WithContext(ctx).Update(appServeAppId, in.GetAppServeAppTask())
 	if err != nil {
 		return &pb.UpdateAppServeAppResponse{
 			Code: pb.Code_INTERNAL,
@@ -100,7 +109,7 @@ func (s *AppServeAppServer) UpdateAppServeAppStatus(ctx context.Context, in *pb.
 		}, err
 	}
 
-	err = asaAccessor.UpdateStatus(appServeAppTaskId, in.GetStatus(), in.GetOutput())
+	err = asaAccessor.WithContext(ctx).UpdateStatus(appServeAppTaskId, in.GetStatus(), in.GetOutput())
 	if err != nil {
 		return &pb.SimpleResponse{
 			Code: pb.Code_INTERNAL,
@@ -109,6 +118,7 @@ func (s *AppServeAppServer) UpdateAppServeAppStatus(ctx context.Context, in *pb.
 			},
 		}, err
 	}
+	notifyAppServeAppStatus(ctx, appServeAppTaskId, in.GetStatus(), in.GetOutput())
 	return &pb.SimpleResponse{
 		Code:  pb.Code_OK_UNSPECIFIED,
 		Error: nil,
@@ -136,7 +146,7 @@ func (s *AppServeAppServer) UpdateAppServeAppEndpoint(ctx context.Context, in *p
 		}, err
 	}
 
-	err = asaAccessor.UpdateEndpoint(appServeAppId, appServeAppTaskId, in.GetEndpoint(), in.GetPreviewEndpoint(), in.GetHelmRevision())
+	err = asaAccessor.WithContext(ctx).UpdateEndpoint(appServeAppId, appServeAppTaskId, in.GetEndpoint(), in.GetPreviewEndpoint(), in.GetHelmRevision())
 	if err != nil {
 		return &pb.SimpleResponse{
 			Code: pb.Code_INTERNAL,
@@ -167,7 +177,16 @@ func (s *AppServeAppServer) GetAppServeApps(ctx context.Context, in *pb.GetAppSe
 
 	log.Info("GetAppServeApps request for contractId: ", contractId)
 
-	appServeApps, err := asaAccessor.GetAppServeApps(contractId, showAll)
+	if code, err := validateContract(ctx, contractId); err != nil {
+		return &pb.GetAppServeAppsResponse{
+			Code: code,
+			Error: &pb.Error{
+				Msg: err.Error(),
+			},
+		}, err
+	}
+
+	appServeApps, err := asaAccessor.WithContext(ctx).GetAppServeApps(contractId, showAll)
 	if err != nil {
 		return &pb.GetAppServeAppsResponse{
 			Code: pb.Code_INTERNAL,
@@ -196,7 +215,7 @@ func (s *AppServeAppServer) GetAppServeApp(ctx context.Context, in *pb.GetAppSer
 	}
 	log.Info("Received GetAppServeApp request for ID: ", id)
 
-	appServeAppCombined, err := asaAccessor.GetAppServeApp(id)
+	appServeAppCombined, err := asaAccessor.WithContext(ctx).GetAppServeApp(id)
 	if err != nil {
 		return &pb.GetAppServeAppResponse{
 			Code: pb.Code_INTERNAL,
@@ -37,10 +37,10 @@ func (s *AppInfoServer) CreateAppGroup(ctx context.Context, in *pb.CreateAppGrou
 	log.Info("Request 'CreateAppGroup' for cluster id ", clusterID)
 	appGroup := in.GetAppGroup()
 
-	id, err := acc.Create(clusterID, appGroup)
+	id, err := acc.WithContext(ctx).Create(clusterID, appGroup)
 	if err != nil {
 		return &pb.IDResponse{
-			Code: pb.Code_INTERNAL,
+			Code: errorCode(err, pb.Code_INTERNAL),
 			Error: &pb.Error{
 				Msg: err.Error(),
 			},
@@ -67,7 +67,7 @@ func (s *AppInfoServer) GetAppGroupsByClusterID(ctx context.Context, in *pb.IDRe
 	}
 	log.Info("GetAppGroupsByClusterID request for clusterId: ", clusterID)
 
-	appGroups, err := acc.GetAppGroupsByClusterID(clusterID, 0, 10)
+	appGroups, err := acc.WithContext(ctx).GetAppGroupsByClusterID(clusterID, 0, 10)
 	if err != nil {
 		return &pb.GetAppGroupsResponse{
 			Code: pb.Code_INTERNAL,
@@ -96,7 +96,7 @@ func (s *AppInfoServer) GetAppGroups(ctx context.Context, in *pb.GetAppGroupsReq
 	}
 	log.Info("GetAppGroups request for app name: ", in.GetAppGroupName())
 
-	appGroups, err := acc.GetAppGroups(in.GetAppGroupName(), in.GetType())
+	appGroups, err := acc.WithContext(ctx).GetAppGroups(in.GetAppGroupName(), in.GetType())
 	if err != nil {
 		return &pb.GetAppGroupsResponse{
 			Code: pb.Code_INTERNAL,
@@ -126,7 +126,7 @@ func (s *AppInfoServer) GetAppGroup(ctx context.Context, in *pb.GetAppGroupReque
 	}
 
 	log.Info("GetAppGroup request for app group ID: ", appGroupID)
-	appGroup, err := acc.GetAppGroup(appGroupID)
+	appGroup, err := acc.WithContext(ctx).GetAppGroup(appGroupID)
 	if err != nil {
 		return &pb.GetAppGroupResponse{
 			Code: pb.Code_INTERNAL,
@@ -156,7 +156,7 @@ func (*AppInfoServer) UpdateAppGroupStatus(ctx context.Context, in *pb.UpdateApp
 	}
 
 	log.Info("UpdateAppGroupStatus request for app group ID: ", appGroupID)
-	if err := acc.UpdateAppGroupStatus(appGroupID, in.GetStatus(), in.GetStatusDesc(), in.GetWorkflowId()); err != nil {
+	if err := acc.WithContext(ctx).UpdateAppGroupStatus(appGroupID, in.GetStatus(), in.GetStatusDesc(), in.GetWorkflowId()); err != nil {
 		return &pb.SimpleResponse{
 			Code: pb.Code_INTERNAL,
 			Error: &pb.Error{
@@ -164,6 +164,7 @@ func (*AppInfoServer) UpdateAppGroupStatus(ctx context.Context, in *pb.UpdateApp
 			},
 		}, err
 	}
+	notifyAppGroupStatus(ctx, appGroupID, in.GetStatus(), in.GetStatusDesc())
 	return &pb.SimpleResponse{
 		Code:  pb.Code_OK_UNSPECIFIED,
 		Error: nil,
@@ -181,7 +182,7 @@ func (s *AppInfoServer) DeleteAppGroup(ctx context.Context, in *pb.DeleteAppGrou
 		}, fmt.Errorf("invalid app group ID %s", in.GetAppGroupId())
 	}
 	log.Info("DeleteAppGroup request for app group ID: ", appGroupID)
-	if err := acc.DeleteAppGroup(appGroupID); err != nil {
+	if err := acc.WithContext(ctx).DeleteAppGroup(appGroupID); err != nil {
 		return &pb.SimpleResponse{
 			Code: pb.Code_INTERNAL,
 			Error: &pb.Error{
@@ -206,7 +207,7 @@ func (*AppInfoServer) GetAppsByAppGroupID(ctx context.Context, in *pb.IDRequest)
 		}, fmt.Errorf("invalid app group ID %s", in.GetId())
 	}
 	log.Info("GetAppsByAppGroupID request for app group ID: ", appGroupID)
-	apps, err := acc.GetAppsByAppGroupID(appGroupID)
+	apps, err := acc.WithContext(ctx).GetAppsByAppGroupID(appGroupID)
 	if err != nil {
 		return &pb.GetAppsResponse{
 			Code: pb.Code_INTERNAL,
@@ -234,7 +235,7 @@ func (*AppInfoServer) GetApps(ctx context.Context, in *pb.GetAppsRequest) (*pb.G
 	}
 
 	log.Info("GetApps request for app group ID: ", appGroupID)
-	apps, err := acc.GetApps(appGroupID, in.GetType())
+	apps, err := acc.WithContext(ctx).GetApps(appGroupID, in.GetType())
 	if err != nil {
 		return &pb.GetAppsResponse{
 			Code: pb.Code_INTERNAL,
@@ -262,7 +263,7 @@ func (*AppInfoServer) UpdateApp(ctx context.Context, in *pb.UpdateAppRequest) (*
 	}
 	log.Info("UpdateApp request for app group ID: ", appGroupID)
 	log.Info(">>> endpoint: ", in.GetEndpoint())
-	if err := acc.UpdateApp(appGroupID, in.GetAppType(), in.GetEndpoint(), in.GetMetadata()); err != nil {
+	if err := acc.WithContext(ctx).UpdateApp(appGroupID, in.GetAppType(), in.GetEndpoint(), in.GetMetadata()); err != nil {
 		return &pb.SimpleResponse{
 			Code: pb.Code_INTERNAL,
 			Error: &pb.Error{
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+
+	"github.com/openinfradev/tks-info/pkg/audit"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// auditedMethods are the RPCs and gateway routes which create, update or delete resources.
+var auditedMethods = []string{
+	methodName(pb.ClusterInfoService_ServiceDesc.ServiceName, "AddClusterInfo"),
+	methodName(pb.ClusterInfoService_ServiceDesc.ServiceName, "UpdateClusterConf"),
+	methodName(pb.ClusterInfoService_ServiceDesc.ServiceName, "UpdateClusterStatus"),
+	methodName(pb.CspInfoService_ServiceDesc.ServiceName, "CreateCSPInfo"),
+	methodName(pb.CspInfoService_ServiceDesc.ServiceName, "UpdateCSPAuth"),
+	methodName(pb.AppInfoService_ServiceDesc.ServiceName, "CreateAppGroup"),
+	methodName(pb.AppInfoService_ServiceDesc.ServiceName, "UpdateAppGroupStatus"),
+	methodName(pb.AppInfoService_ServiceDesc.ServiceName, "DeleteAppGroup"),
+	methodName(pb.AppInfoService_ServiceDesc.ServiceName, "UpdateApp"),
+	methodName(pb.AppServeAppService_ServiceDesc.ServiceName, "CreateAppServeApp"),
+	methodName(pb.AppServeAppService_ServiceDesc.ServiceName, "UpdateAppServeApp"),
+	methodName(pb.AppServeAppService_ServiceDesc.ServiceName, "UpdateAppServeAppStatus"),
+	methodName(pb.AppServeAppService_ServiceDesc.ServiceName, "UpdateAppServeAppEndpoint"),
+	methodName(pb.KeycloakInfoService_ServiceDesc.ServiceName, "CreateKeycloakInfo"),
+	methodName(cspAuthService, "RollbackCSPAuth"),
+	methodName(labelService, "UpdateLabels"),
+}
+
+// auditPolicy returns the audited RPCs with the contract lookups of the auth policy.
+func auditPolicy(r *auth.Resolver) audit.Policy {
+	rules := authPolicy(r)
+	policy := audit.Policy{}
+	for _, method := range auditedMethods {
+		policy[method] = audit.ContractFunc(rules[method].Contract)
+	}
+
+	// The auth policy doesn't look up contracts of RPCs only for admins.
+	csp := pb.CspInfoService_ServiceDesc.ServiceName
+	policy[methodName(csp, "CreateCSPInfo")] = func(ctx context.Context, req interface{}) (string, error) {
+		return req.(*pb.CreateCSPInfoRequest).GetContractId(), nil
+	}
+	policy[methodName(csp, "UpdateCSPAuth")] = func(ctx context.Context, req interface{}) (string, error) {
+		return r.CSPContract(ctx, req.(*pb.UpdateCSPAuthRequest).GetCspId())
+	}
+	policy[methodName(cspAuthService, "RollbackCSPAuth")] = func(ctx context.Context, req interface{}) (string, error) {
+		return r.CSPContract(ctx, req.(*RollbackCSPAuthRequest).CspId)
+	}
+	return policy
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/openinfradev/tks-info/pkg/auth"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+func TestAuditPolicyCoversMutatingMethods(t *testing.T) {
+	// RPCs which are not implemented change nothing.
+	unimplemented := map[string]bool{
+		methodName(pb.KeycloakInfoService_ServiceDesc.ServiceName, "UpdateKeycloakInfo"): true,
+		methodName(pb.KeycloakInfoService_ServiceDesc.ServiceName, "DeleteKeycloakInfo"): true,
+	}
+
+	policy := auditPolicy(auth.NewResolver(nil))
+	methods := map[string]bool{}
+	for _, desc := range []grpc.ServiceDesc{
+		pb.AppInfoService_ServiceDesc,
+		pb.AppServeAppService_ServiceDesc,
+		pb.ClusterInfoService_ServiceDesc,
+		pb.CspInfoService_ServiceDesc,
+		pb.KeycloakInfoService_ServiceDesc,
+	} {
+		for _, m := range desc.Methods {
+			name := methodName(desc.ServiceName, m.MethodName)
+			methods[name] = true
+			if strings.HasPrefix(m.MethodName, "Get") || unimplemented[name] {
+				continue
+			}
+			contractFunc, ok := policy[name]
+			require.True(t, ok, "%s is not audited", name)
+			require.NotNil(t, contractFunc, "%s has no contract lookup", name)
+		}
+	}
+	for _, route := range routes() {
+		name := route.FullMethod()
+		methods[name] = true
+		if strings.HasPrefix(route.Method, "Get") || strings.HasPrefix(route.Method, "List") || route.Method == "Search" {
+			continue
+		}
+		contractFunc, ok := policy[name]
+		require.True(t, ok, "%s is not audited", name)
+		require.NotNil(t, contractFunc, "%s has no contract lookup", name)
+	}
+	for name := range policy {
+		require.True(t, methods[name], "%s is not an RPC or a route", name)
+	}
+}
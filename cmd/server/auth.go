@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+
+	"github.com/openinfradev/tks-info/pkg/auth"
+	"github.com/openinfradev/tks-info/pkg/search"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// publicMethods can be called without credentials.
+var publicMethods = []string{
+	"/grpc.health.v1.Health/",
+}
+
+func methodName(service string, method string) string {
+	return "/" + service + "/" + method
+}
+
+// canReadSecrets returns true if the caller may see credentials, such as CSP auths and
+// kubeconfigs, in responses of RPCs open to readers. Every caller may if authentication is disabled.
+func canReadSecrets(ctx context.Context) bool {
+	identity, ok := auth.FromContext(ctx)
+	return !ok || identity.HasRole(auth.RoleWorkflowWriter)
+}
+
+// authPolicy returns the role and the contract scope of each RPC.
+// RPCs which are not listed are allowed only to admins.
+func authPolicy(r *auth.Resolver) auth.Policy {
+	direct := func(get func(req interface{}) string) auth.ContractFunc {
+		return func(ctx context.Context, req interface{}) (string, error) {
+			return get(req), nil
+		}
+	}
+	lookup := func(resolve func(ctx context.Context, id string) (string, error), get func(req interface{}) string) auth.ContractFunc {
+		return func(ctx context.Context, req interface{}) (string, error) {
+			return resolve(ctx, get(req))
+		}
+	}
+	id := func(req interface{}) string {
+		return req.(*pb.IDRequest).GetId()
+	}
+
+	cluster := pb.ClusterInfoService_ServiceDesc.ServiceName
+	csp := pb.CspInfoService_ServiceDesc.ServiceName
+	app := pb.AppInfoService_ServiceDesc.ServiceName
+	asa := pb.AppServeAppService_ServiceDesc.ServiceName
+	keycloak := pb.KeycloakInfoService_ServiceDesc.ServiceName
+
+	return auth.Policy{
+		methodName(cluster, "AddClusterInfo"): {Role: auth.RoleWorkflowWriter, Contract: direct(func(req interface{}) string {
+			return req.(*pb.AddClusterInfoRequest).GetContractId()
+		})},
+		methodName(cluster, "UpdateClusterConf"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.ClusterContract, func(req interface{}) string {
+			return req.(*pb.UpdateClusterConfRequest).GetClusterId()
+		})},
+		methodName(cluster, "GetCluster"): {Role: auth.RoleReader, Contract: lookup(r.ClusterContract, func(req interface{}) string {
+			return req.(*pb.GetClusterRequest).GetClusterId()
+		})},
+		methodName(cluster, "GetClusters"): {Role: auth.RoleReader, Contract: func(ctx context.Context, req interface{}) (string, error) {
+			in := req.(*pb.GetClustersRequest)
+			if in.GetContractId() != "" {
+				return in.GetContractId(), nil
+			}
+			if in.GetCspId() == "" {
+				// GetClusters returns clusters of the default contract.
+				contract, err := getDefaultContract(ctx)
+				if err != nil {
+					return "", err
+				}
+				return contract.GetContractId(), nil
+			}
+			return r.CSPContract(ctx, in.GetCspId())
+		}},
+		methodName(cluster, "UpdateClusterStatus"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.ClusterContract, func(req interface{}) string {
+			return req.(*pb.UpdateClusterStatusRequest).GetClusterId()
+		})},
+
+		methodName(csp, "CreateCSPInfo"):         {Role: auth.RoleAdmin},
+		methodName(csp, "GetCSPInfo"):            {Role: auth.RoleReader, Contract: lookup(r.CSPContract, id)},
+		methodName(csp, "GetCSPIDs"):             {Role: auth.RoleReader},
+		methodName(csp, "GetCSPIDsByContractID"): {Role: auth.RoleReader, Contract: direct(id)},
+		methodName(csp, "UpdateCSPAuth"):         {Role: auth.RoleAdmin},
+		// CSP credentials are needed by workflows which provision clusters, but not by readers.
+		methodName(csp, "GetCSPAuth"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.CSPContract, id)},
+
+		methodName(cspAuthService, "RollbackCSPAuth"): {Role: auth.RoleAdmin},
+
+		methodName(app, "CreateAppGroup"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.ClusterContract, func(req interface{}) string {
+			return req.(*pb.CreateAppGroupRequest).GetClusterId()
+		})},
+		methodName(app, "GetAppGroupsByClusterID"): {Role: auth.RoleReader, Contract: lookup(r.ClusterContract, id)},
+		methodName(app, "GetAppGroups"):            {Role: auth.RoleReader},
+		methodName(app, "GetAppGroup"): {Role: auth.RoleReader, Contract: lookup(r.AppGroupContract, func(req interface{}) string {
+			return req.(*pb.GetAppGroupRequest).GetAppGroupId()
+		})},
+		methodName(app, "UpdateAppGroupStatus"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.AppGroupContract, func(req interface{}) string {
+			return req.(*pb.UpdateAppGroupStatusRequest).GetAppGroupId()
+		})},
+		methodName(app, "DeleteAppGroup"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.AppGroupContract, func(req interface{}) string {
+			return req.(*pb.DeleteAppGroupRequest).GetAppGroupId()
+		})},
+		methodName(app, "GetAppsByAppGroupID"): {Role: auth.RoleReader, Contract: lookup(r.AppGroupContract, id)},
+		methodName(app, "GetApps"): {Role: auth.RoleReader, Contract: lookup(r.AppGroupContract, func(req interface{}) string {
+			return req.(*pb.GetAppsRequest).GetAppGroupId()
+		})},
+		methodName(app, "UpdateApp"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.AppGroupContract, func(req interface{}) string {
+			return req.(*pb.UpdateAppRequest).GetAppGroupId()
+		})},
+
+		methodName(asa, "CreateAppServeApp"): {Role: auth.RoleWorkflowWriter, Contract: direct(func(req interface{}) string {
+			return req.(*pb.CreateAppServeAppRequest).GetAppServeApp().GetContractId()
+		})},
+		methodName(asa, "UpdateAppServeApp"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.AppServeAppContract, func(req interface{}) string {
+			return req.(*pb.UpdateAppServeAppRequest).GetAppServeAppId()
+		})},
+		methodName(asa, "GetAppServeApp"): {Role: auth.RoleReader, Contract: lookup(r.AppServeAppContract, func(req interface{}) string {
+			return req.(*pb.GetAppServeAppRequest).GetAppServeAppId()
+		})},
+		methodName(asa, "GetAppServeApps"): {Role: auth.RoleReader, Contract: direct(func(req interface{}) string {
+			return req.(*pb.GetAppServeAppsRequest).GetContractId()
+		})},
+		methodName(asa, "UpdateAppServeAppStatus"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.AppServeAppTaskContract, func(req interface{}) string {
+			return req.(*pb.UpdateAppServeAppStatusRequest).GetAppServeAppTaskId()
+		})},
+		methodName(asa, "UpdateAppServeAppEndpoint"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.AppServeAppContract, func(req interface{}) string {
+			return req.(*pb.UpdateAppServeAppEndpointRequest).GetAppServeAppId()
+		})},
+
+		methodName(keycloak, "CreateKeycloakInfo"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.ClusterContract, func(req interface{}) string {
+			return req.(*pb.CreateKeycloakInfoRequest).GetClusterId()
+		})},
+		// Keycloak infos have client secrets and private keys.
+		methodName(keycloak, "GetKeycloakInfoByClusterId"): {Role: auth.RoleWorkflowWriter, Contract: lookup(r.ClusterContract, id)},
+
+		methodName(inventoryService, "GetInventory"): {Role: auth.RoleReader, Contract: direct(func(req interface{}) string {
+			return req.(*GetInventoryRequest).ContractId
+		})},
+		// Searches without a contract are of all contracts.
+		methodName(searchService, "Search"): {Role: auth.RoleReader, Contract: direct(func(req interface{}) string {
+			return req.(*search.Query).ContractID
+		})},
+
+		methodName(labelService, "GetLabels"): {Role: auth.RoleReader, Contract: func(ctx context.Context, req interface{}) (string, error) {
+			in := req.(*GetLabelsRequest)
+			return labeledContract(ctx, r, in.Kind, in.Id)
+		}},
+		methodName(labelService, "UpdateLabels"): {Role: auth.RoleWorkflowWriter, Contract: func(ctx context.Context, req interface{}) (string, error) {
+			in := req.(*UpdateLabelsRequest)
+			return labeledContract(ctx, r, in.Kind, in.Id)
+		}},
+		methodName(labelService, "ListBySelector"): {Role: auth.RoleReader, Contract: func(ctx context.Context, req interface{}) (string, error) {
+			in := req.(*ListBySelectorRequest)
+			if in.Kind == kindAppGroup {
+				return r.ClusterContract(ctx, in.ClusterId)
+			}
+			return in.ContractId, nil
+		}},
+	}
+}
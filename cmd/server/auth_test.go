@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/openinfradev/tks-info/pkg/auth"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+	mocktks "github.com/openinfradev/tks-proto/tks_pb/mock"
+)
+
+func TestAuthPolicyCoversServices(t *testing.T) {
+	// RPCs which are not implemented are left to admins.
+	adminOnly := map[string]bool{
+		methodName(pb.KeycloakInfoService_ServiceDesc.ServiceName, "UpdateKeycloakInfo"): true,
+		methodName(pb.KeycloakInfoService_ServiceDesc.ServiceName, "DeleteKeycloakInfo"): true,
+	}
+
+	policy := authPolicy(auth.NewResolver(nil))
+	for _, desc := range []grpc.ServiceDesc{
+		pb.AppInfoService_ServiceDesc,
+		pb.AppServeAppService_ServiceDesc,
+		pb.ClusterInfoService_ServiceDesc,
+		pb.CspInfoService_ServiceDesc,
+		pb.KeycloakInfoService_ServiceDesc,
+	} {
+		for _, m := range desc.Methods {
+			name := methodName(desc.ServiceName, m.MethodName)
+			_, ok := policy[name]
+			require.True(t, ok || adminOnly[name], "%s is not in the auth policy", name)
+		}
+	}
+	for name := range policy {
+		require.False(t, adminOnly[name], "%s should not be in the auth policy", name)
+	}
+}
+
+func TestAuthPolicyCoversRoutes(t *testing.T) {
+	policy := authPolicy(auth.NewResolver(nil))
+	for _, route := range routes() {
+		_, ok := policy[route.FullMethod()]
+		require.True(t, ok, "%s is not in the auth policy", route.FullMethod())
+	}
+}
+
+func TestCanReadSecrets(t *testing.T) {
+	require.True(t, canReadSecrets(context.Background()))
+
+	reader := auth.NewContext(context.Background(), &auth.Identity{Roles: []auth.Role{auth.RoleReader}})
+	require.False(t, canReadSecrets(reader))
+
+	writer := auth.NewContext(context.Background(), &auth.Identity{Roles: []auth.Role{auth.RoleWorkflowWriter}})
+	require.True(t, canReadSecrets(writer))
+}
+
+func TestAuthPolicyDefaultContract(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockContractClient := mocktks.NewMockContractServiceClient(ctrl)
+	mockContractClient.EXPECT().GetDefaultContract(gomock.Any(), gomock.Any()).Times(1).
+		Return(&pb.GetContractResponse{
+			Code:     pb.Code_OK_UNSPECIFIED,
+			Contract: &pb.Contract{ContractId: "P0010010a"},
+		}, nil)
+	saved := contractClient
+	contractClient = mockContractClient
+	defer func() { contractClient = saved }()
+
+	rule := authPolicy(auth.NewResolver(nil))[methodName(pb.ClusterInfoService_ServiceDesc.ServiceName, "GetClusters")]
+	contractId, err := rule.Contract(context.Background(), &pb.GetClustersRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "P0010010a", contractId)
+}
+
+func TestLabeledContractOfUnknownKind(t *testing.T) {
+	_, err := labeledContract(context.Background(), auth.NewResolver(nil), "csp", "C1234abcd")
+	require.ErrorIs(t, err, integrity.ErrNotFound)
+}
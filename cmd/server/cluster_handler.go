@@ -53,11 +53,9 @@ func (s *ClusterInfoServer) AddClusterInfo(ctx context.Context, in *pb.AddCluste
 		return &res, fmt.Errorf("invalid contract ID %s", contractId)
 	}
 
-	// Return an error if csp id does not exist.
-	// TODO: Need to add logic to check if the contractID exists using GRPC call to tks-contract.
-	if _, err := cspInfoAccessor.GetCSPInfo(cspId); err != nil {
+	if code, err := validateContract(ctx, contractId); err != nil {
 		return &pb.IDResponse{
-			Code: pb.Code_NOT_FOUND,
+			Code: code,
 			Error: &pb.Error{
 				Msg: err.Error(),
 			},
@@ -78,10 +76,11 @@ func (s *ClusterInfoServer) AddClusterInfo(ctx context.Context, in *pb.AddCluste
 			return &res, err
 		}
 	}
-	cID, err := clusterAccessor.CreateClusterInfo(contractId, cspId, in.GetName(), in.GetConf(), creator, in.GetDescription())
+	// Return an error if csp id does not exist or belongs to another contract.
+	cID, err := clusterAccessor.WithContext(ctx).CreateClusterInfo(contractId, cspId, in.GetName(), in.GetConf(), creator, in.GetDescription())
 	if err != nil {
 		return &pb.IDResponse{
-			Code: pb.Code_INTERNAL,
+			Code: errorCode(err, pb.Code_INTERNAL),
 			Error: &pb.Error{
 				Msg: err.Error(),
 			},
@@ -109,7 +108,7 @@ func (s *ClusterInfoServer) GetCluster(ctx context.Context, in *pb.GetClusterReq
 		return &res, fmt.Errorf("invalid cluster ID %s", clusterId)
 	}
 
-	cluster, err := clusterAccessor.GetCluster(clusterId)
+	cluster, err := clusterAccessor.WithContext(ctx).GetCluster(clusterId)
 	if err != nil {
 		return &pb.GetClusterResponse{
 			Code: pb.Code_NOT_FOUND,
@@ -119,6 +118,7 @@ func (s *ClusterInfoServer) GetCluster(ctx context.Context, in *pb.GetClusterReq
 		}, err
 	}
 
+	hideKubeconfigs(ctx, cluster)
 	return &pb.GetClusterResponse{
 		Code:    pb.Code_OK_UNSPECIFIED,
 		Error:   nil,
@@ -133,7 +133,7 @@ func (s *ClusterInfoServer) GetClusters(ctx context.Context, in *pb.GetClustersR
 
 	// use default contract if both contractId and cspId was not provided
 	if contractId == "" && cspId == "" {
-		contract, err := s.getDefaultContract(ctx)
+		contract, err := getDefaultContract(ctx)
 		if err != nil {
 			log.Error("Failed to get default contract. err : ", err)
 			return &pb.GetClustersResponse{
@@ -173,7 +173,7 @@ func (s *ClusterInfoServer) GetClusters(ctx context.Context, in *pb.GetClustersR
 			}, fmt.Errorf("invalid contract ID %s", conIdParsed)
 		}
 
-		clusters, err := clusterAccessor.GetClustersByContractID(conIdParsed)
+		clusters, err := clusterAccessor.WithContext(ctx).GetClustersByContractID(conIdParsed)
 		if err != nil {
 			return &pb.GetClustersResponse{
 				Code: pb.Code_NOT_FOUND,
@@ -185,6 +185,7 @@ func (s *ClusterInfoServer) GetClusters(ctx context.Context, in *pb.GetClustersR
 		}
 
 		// Successfully return GetClustersResponse
+		hideKubeconfigs(ctx, clusters...)
 		return &pb.GetClustersResponse{
 			Code:     pb.Code_OK_UNSPECIFIED,
 			Error:    nil,
@@ -205,7 +206,7 @@ func (s *ClusterInfoServer) GetClusters(ctx context.Context, in *pb.GetClustersR
 			}, err
 		}
 
-		clusters, err := clusterAccessor.GetClustersByCspID(cspIdParsed)
+		clusters, err := clusterAccessor.WithContext(ctx).GetClustersByCspID(cspIdParsed)
 		if err != nil {
 			return &pb.GetClustersResponse{
 				Code: pb.Code_NOT_FOUND,
@@ -217,6 +218,7 @@ func (s *ClusterInfoServer) GetClusters(ctx context.Context, in *pb.GetClustersR
 		}
 
 		// Successfully return GetClustersResponse
+		hideKubeconfigs(ctx, clusters...)
 		return &pb.GetClustersResponse{
 			Code:     pb.Code_OK_UNSPECIFIED,
 			Error:    nil,
@@ -237,7 +239,7 @@ func (s *ClusterInfoServer) UpdateClusterStatus(ctx context.Context, in *pb.Upda
 		}, fmt.Errorf("invalid cluster ID %s", clusterId)
 	}
 
-	err := clusterAccessor.UpdateStatus(clusterId, in.GetStatus(), in.GetStatusDesc(), in.GetWorkflowId())
+	err := clusterAccessor.WithContext(ctx).UpdateStatus(clusterId, in.GetStatus(), in.GetStatusDesc(), in.GetWorkflowId())
 	if err != nil {
 		return &pb.SimpleResponse{
 			Code: pb.Code_INTERNAL,
@@ -246,13 +248,14 @@ func (s *ClusterInfoServer) UpdateClusterStatus(ctx context.Context, in *pb.Upda
 			},
 		}, err
 	}
+	notifyClusterStatus(ctx, clusterId, in.GetStatus(), in.GetStatusDesc())
 	return &pb.SimpleResponse{
 		Code:  pb.Code_OK_UNSPECIFIED,
 		Error: nil,
 	}, nil
 }
 
-func (s *ClusterInfoServer) getDefaultContract(ctx context.Context) (*pb.Contract, error) {
+func getDefaultContract(ctx context.Context) (*pb.Contract, error) {
 	resContract, err := contractClient.GetDefaultContract(ctx, &empty.Empty{})
 	if err != nil {
 		log.Error("Failed to get contract info err : ", err)
@@ -261,3 +264,13 @@ func (s *ClusterInfoServer) getDefaultContract(ctx context.Context) (*pb.Contrac
 
 	return resContract.GetContract(), nil
 }
+
+// hideKubeconfigs clears kubeconfigs of the clusters unless the caller may read secrets.
+func hideKubeconfigs(ctx context.Context, clusters ...*pb.Cluster) {
+	if canReadSecrets(ctx) {
+		return
+	}
+	for _, c := range clusters {
+		c.Kubeconfig = ""
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-info/pkg/contract"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 	mocktks "github.com/openinfradev/tks-proto/tks_pb/mock"
 )
@@ -100,6 +104,56 @@ func TestAddClusterInfo(t *testing.T) {
 	}
 }
 
+func TestAddClusterInfoWithContractValidation(t *testing.T) {
+	testCases := []struct {
+		name          string
+		buildStubs    func(mockContractClient *mocktks.MockContractServiceClient)
+		checkResponse func(res *pb.IDResponse, err error)
+	}{
+		{
+			name: "NOT_EXISTED_CONTRACT_ID",
+			buildStubs: func(mockContractClient *mocktks.MockContractServiceClient) {
+				mockContractClient.EXPECT().GetContract(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, status.Error(codes.NotFound, "NOT_EXISTED_CONTRACT_ID"))
+			},
+			checkResponse: func(res *pb.IDResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, res.Code, pb.Code_NOT_FOUND)
+			},
+		},
+		{
+			name: "CONTRACT_SERVICE_UNAVAILABLE",
+			buildStubs: func(mockContractClient *mocktks.MockContractServiceClient) {
+				mockContractClient.EXPECT().GetContract(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, status.Error(codes.Unavailable, "CONTRACT_SERVICE_UNAVAILABLE"))
+			},
+			checkResponse: func(res *pb.IDResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, res.Code, pb.Code_UNAVAILABLE)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockContractClient := mocktks.NewMockContractServiceClient(ctrl)
+			contractValidator = contract.NewValidator(mockContractClient, time.Minute, false)
+			defer func() { contractValidator = nil }()
+
+			tc.buildStubs(mockContractClient)
+
+			s := ClusterInfoServer{}
+			res, err := s.AddClusterInfo(context.Background(), randomAddClusterInfoRequest())
+			tc.checkResponse(res, err)
+		})
+	}
+}
+
 func TestGetCluster(t *testing.T) {
 	testCases := []struct {
 		name          string
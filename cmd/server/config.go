@@ -0,0 +1,124 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/config"
+	"github.com/openinfradev/tks-info/pkg/limits"
+	"github.com/openinfradev/tks-info/pkg/reconcile"
+	"github.com/openinfradev/tks-info/pkg/tracing"
+)
+
+const envPrefix = "TKS_INFO"
+
+// secretFlags are not printed on startup.
+var secretFlags = map[string]bool{
+	"dbpassword": true,
+}
+
+// loadConfig completes the flags from the environment and the config file, and validates them.
+func loadConfig() error {
+	path := configPath
+	if path == "" {
+		path = os.Getenv(config.EnvName(envPrefix, "config"))
+	}
+	if err := config.Load(flag.CommandLine, path, envPrefix); err != nil {
+		return err
+	}
+	return validateConfig()
+}
+
+func validateConfig() error {
+	problems := []string{}
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(port > 0 && port < 65536, "port must be between 1 and 65535")
+	check(contractPort > 0 && contractPort < 65536, "contract-port must be between 1 and 65535")
+	check(metricsPort >= 0 && metricsPort < 65536, "metrics-port must be between 0 and 65535")
+	check(metricsPort != port, "metrics-port must differ from port")
+	check(httpPort >= 0 && httpPort < 65536, "http-port must be between 0 and 65535")
+	check(httpPort == 0 || (httpPort != port && httpPort != metricsPort), "http-port must differ from port and metrics-port")
+	if err := dbConfig.Validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	check(contractCacheTTL >= 0, "contract-cache-ttl must not be negative")
+	check(cspAuthOverlap >= 0, "csp-auth-overlap must not be negative")
+	check(healthProbeInterval > 0, "health-probe-interval must be positive")
+	check(healthProbeTimeout > 0, "health-probe-timeout must be positive")
+	check(shutdownTimeout > 0, "shutdown-timeout must be positive")
+	check(isOneOf(traceConfig.Exporter, tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout, tracing.ExporterFile),
+		"trace-exporter %q is not valid", traceConfig.Exporter)
+	check(traceConfig.SampleRatio >= 0 && traceConfig.SampleRatio <= 1, "trace-sample-ratio must be between 0 and 1")
+
+	check(tlsClientCAPath == "" || tlsEnabled, "tls-client-ca-path needs tlsEnabled")
+	check(tlsAllowedSANs == "" || tlsClientCAPath != "", "tls-allowed-sans needs tls-client-ca-path")
+	check(tlsReloadInterval > 0, "tls-reload-interval must be positive")
+	if rateLimit != "" {
+		_, err := limits.ParseLimit(rateLimit)
+		check(err == nil, "rate-limit: %v", err)
+	}
+	_, err := limits.ParseMethodLimits(rateLimitMethods)
+	check(err == nil, "rate-limit-methods: %v", err)
+	check(rateLimitMethods == "" || rateLimit != "", "rate-limit-methods needs rate-limit")
+	check(idempotencyTTL >= 0, "idempotency-ttl must not be negative")
+	check(leaderLeaseDuration >= 3*time.Second, "leader-lease-duration must be at least 3s")
+	check(reconcileInterval >= 0, "reconcile-interval must not be negative")
+	_, err = reconcile.ParseClusterTimeouts(reconcileClusters)
+	check(err == nil, "reconcile-cluster-timeouts: %v", err)
+	_, err = reconcile.ParseAppGroupTimeouts(reconcileAppGroups)
+	check(err == nil, "reconcile-app-group-timeouts: %v", err)
+	check(webhookTimeout > 0 && webhookTimeout < webhookInterval, "webhook-timeout must be positive and shorter than webhook-interval")
+	check(webhookMaxAttempts > 0, "webhook-max-attempts must be positive")
+	check(webhookMinBackoff > 0, "webhook-min-backoff must be positive")
+	check(maxRecvMsgSize > 0, "max-recv-msg-size must be positive")
+	check(!authEnabled || authJWKSURL != "" || tlsClientCAPath != "", "auth-enabled needs auth-jwks-url or tls-client-ca-path")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func logConfig() {
+	log.Info("*** Arguments *** ")
+	flag.VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		if secretFlags[f.Name] && value != "" {
+			value = "********"
+		}
+		log.Info(f.Name, " : ", value)
+	})
+	log.Info("****************** ")
+	if dbConfig.Password == flag.Lookup("dbpassword").DefValue {
+		log.Warn("dbpassword is the default value. set ", config.EnvName(envPrefix, "dbpassword-file"), " or ", config.EnvName(envPrefix, "dbpassword"))
+	}
+}
+
+// splitList splits a comma separated flag value.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func isOneOf(value string, candidates ...string) bool {
+	for _, c := range candidates {
+		if value == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/openinfradev/tks-info/pkg/contract"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+var (
+	contractValidator *contract.Validator
+)
+
+// validateContract checks that the contract exists in tks-contract.
+// It returns the response code to use when the contract is rejected.
+// Validation is skipped when no validator is configured.
+func validateContract(ctx context.Context, contractId string) (pb.Code, error) {
+	if contractValidator == nil {
+		return pb.Code_OK_UNSPECIFIED, nil
+	}
+
+	err := contractValidator.Validate(ctx, contractId)
+	switch {
+	case err == nil:
+		return pb.Code_OK_UNSPECIFIED, nil
+	case errors.Is(err, contract.ErrNotFound):
+		return pb.Code_NOT_FOUND, err
+	default:
+		return pb.Code_UNAVAILABLE, err
+	}
+}
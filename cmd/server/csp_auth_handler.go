@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	"github.com/openinfradev/tks-info/pkg/csp_info"
+	"github.com/openinfradev/tks-info/pkg/gateway"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+)
+
+// cspAuthService is the service of the gateway routes of CSP auth versions, which have no RPCs in tks-proto.
+const cspAuthService = "tks_info.CspAuthService"
+
+type RollbackCSPAuthRequest struct {
+	CspId string `json:"csp_id"`
+}
+
+type RollbackCSPAuthResponse struct {
+	CspId string `json:"csp_id"`
+	// Version is the version of the auth which became active.
+	Version int `json:"version"`
+}
+
+// cspAuthRoutes returns the gateway routes of CSP auth versions.
+func cspAuthRoutes() []gateway.Route {
+	return []gateway.Route{{
+		Service:    cspAuthService,
+		Method:     "RollbackCSPAuth",
+		Summary:    "revoke the active auth of a CSP and reactivate the previous one",
+		NewRequest: func() interface{} { return &RollbackCSPAuthRequest{} },
+		Handler:    rollbackCSPAuth,
+	}}
+}
+
+// rollbackCSPAuth revokes the active auth in the name of the caller, so it needs an authenticated caller.
+func rollbackCSPAuth(ctx context.Context, req interface{}) (interface{}, error) {
+	in := req.(*RollbackCSPAuthRequest)
+	log.Info("request RollbackCSPAuth for CSP ID ", in.CspId)
+
+	caller := auth.Subject(ctx)
+	if caller == "" {
+		return nil, status.Error(codes.Unauthenticated, "rolling back CSP auths requires an authenticated caller")
+	}
+	id, err := uuid.Parse(in.CspId)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid CSP ID %s", in.CspId)
+	}
+
+	version, err := cspInfoAccessor.WithContext(ctx).RollbackCSPAuth(id, caller)
+	switch {
+	case errors.Is(err, integrity.ErrNotFound):
+		return nil, status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, csp_info.ErrNoPreviousAuth):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case err != nil:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &RollbackCSPAuthResponse{CspId: in.CspId, Version: version}, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+func TestRollbackCSPAuth(t *testing.T) {
+	cspId, err := cspInfoAccessor.Create(helper.GenerateContractId(), "csp", "v1", pb.CspType_CSPTYPE_UNSPECIFIED)
+	require.NoError(t, err)
+	_, err = cspInfoAccessor.RotateCSPAuth(cspId, "v2", "admin", 0)
+	require.NoError(t, err)
+
+	admin := auth.NewContext(context.Background(), &auth.Identity{Subject: "admin", Roles: []auth.Role{auth.RoleAdmin}})
+	testCases := []struct {
+		name          string
+		ctx           context.Context
+		in            *RollbackCSPAuthRequest
+		checkResponse func(res interface{}, err error)
+	}{
+		{
+			name: "NO_CALLER",
+			ctx:  context.Background(),
+			in:   &RollbackCSPAuthRequest{CspId: cspId.String()},
+			checkResponse: func(res interface{}, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name: "OK",
+			ctx:  admin,
+			in:   &RollbackCSPAuthRequest{CspId: cspId.String()},
+			checkResponse: func(res interface{}, err error) {
+				require.NoError(t, err)
+				require.Equal(t, 1, res.(*RollbackCSPAuthResponse).Version)
+				history, err := cspInfoAccessor.GetCSPAuthHistory(cspId)
+				require.NoError(t, err)
+				require.Equal(t, "admin", history[0].RevokedBy)
+			},
+		},
+		{
+			name: "NO_PREVIOUS_AUTH",
+			ctx:  admin,
+			in:   &RollbackCSPAuthRequest{CspId: cspId.String()},
+			checkResponse: func(res interface{}, err error) {
+				require.Equal(t, codes.FailedPrecondition, status.Code(err))
+			},
+		},
+		{
+			name: "INVALID_CSP_ID",
+			ctx:  admin,
+			in:   &RollbackCSPAuthRequest{CspId: "NO_ID_STRING"},
+			checkResponse: func(res interface{}, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := rollbackCSPAuth(tc.ctx, tc.in)
+			tc.checkResponse(res, err)
+		})
+	}
+}
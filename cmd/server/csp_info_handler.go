@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"gorm.io/gorm"
 
 	"github.com/openinfradev/tks-common/pkg/helper"
 	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/auth"
 	"github.com/openinfradev/tks-info/pkg/csp_info"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 )
@@ -17,6 +22,11 @@ var (
 	cspInfoAccessor *csp_info.CspInfoAccessor
 )
 
+// cspAuthVersionKey is the gRPC metadata key of the version of a CSP auth.
+// GetCSPAuth sends the version it returns in the response header, and returns the version
+// given in the request metadata instead of the active one while it is in its overlap window.
+const cspAuthVersionKey = "csp-auth-version"
+
 type CspInfoServer struct {
 	pb.UnimplementedCspInfoServiceServer
 }
@@ -39,7 +49,25 @@ func (s *CspInfoServer) CreateCSPInfo(ctx context.Context, in *pb.CreateCSPInfoR
 		}, fmt.Errorf("invalid contract ID %s", contractId)
 	}
 
-	id, err := cspInfoAccessor.Create(contractId, in.GetCspName(), in.GetAuth(), in.GetCspType())
+	if err := csp_info.ValidateAuth(in.GetCspType(), in.GetAuth()); err != nil {
+		return &pb.IDResponse{
+			Code: pb.Code_INVALID_ARGUMENT,
+			Error: &pb.Error{
+				Msg: err.Error(),
+			},
+		}, err
+	}
+
+	if code, err := validateContract(ctx, contractId); err != nil {
+		return &pb.IDResponse{
+			Code: code,
+			Error: &pb.Error{
+				Msg: err.Error(),
+			},
+		}, err
+	}
+
+	id, err := cspInfoAccessor.WithContext(ctx).Create(contractId, in.GetCspName(), in.GetAuth(), in.GetCspType())
 	if err != nil {
 		return &pb.IDResponse{
 			Code: pb.Code_INTERNAL,
@@ -70,7 +98,7 @@ func (s *CspInfoServer) GetCSPInfo(ctx context.Context, in *pb.IDRequest) (*pb.G
 		}, err
 	}
 
-	cspInfo, err2 := cspInfoAccessor.GetCSPInfo(cspId)
+	cspInfo, err2 := cspInfoAccessor.WithContext(ctx).GetCSPInfo(cspId)
 	if err2 != nil {
 		return &pb.GetCSPInfoResponse{
 			Code: pb.Code_NOT_FOUND,
@@ -80,12 +108,17 @@ func (s *CspInfoServer) GetCSPInfo(ctx context.Context, in *pb.IDRequest) (*pb.G
 		}, err2
 	}
 
+	// Readers see the CSP but not its credentials, which GetCSPAuth returns to workflows.
+	cspAuth := cspInfo.Auth
+	if !canReadSecrets(ctx) {
+		cspAuth = ""
+	}
 	return &pb.GetCSPInfoResponse{
 		Code:       pb.Code_OK_UNSPECIFIED,
 		Error:      nil,
 		ContractId: cspInfo.ContractID,
 		CspName:    cspInfo.Name,
-		Auth:       cspInfo.Auth,
+		Auth:       cspAuth,
 		CspType:    cspInfo.CspType,
 	}, nil
 }
@@ -105,7 +138,7 @@ func (s *CspInfoServer) GetCSPIDsByContractID(ctx context.Context, in *pb.IDRequ
 		}, fmt.Errorf("invalid contract ID %s", contractId)
 	}
 
-	ids, err := cspInfoAccessor.GetCSPIDsByContractID(contractId)
+	ids, err := cspInfoAccessor.WithContext(ctx).GetCSPIDsByContractID(contractId)
 	if err != nil {
 		return &pb.IDsResponse{
 			Code: pb.Code_NOT_FOUND,
@@ -138,7 +171,26 @@ func (s *CspInfoServer) UpdateCSPAuth(ctx context.Context, in *pb.UpdateCSPAuthR
 		return &res, err
 	}
 
-	if err := cspInfoAccessor.UpdateCSPAuth(cspId, in.GetAuth()); err != nil {
+	cspInfo, err := cspInfoAccessor.WithContext(ctx).GetCSPInfo(cspId)
+	if err != nil {
+		return &pb.SimpleResponse{
+			Code: pb.Code_NOT_FOUND,
+			Error: &pb.Error{
+				Msg: err.Error(),
+			},
+		}, err
+	}
+
+	if err := csp_info.ValidateAuth(cspInfo.CspType, in.GetAuth()); err != nil {
+		return &pb.SimpleResponse{
+			Code: pb.Code_INVALID_ARGUMENT,
+			Error: &pb.Error{
+				Msg: err.Error(),
+			},
+		}, err
+	}
+
+	if _, err := cspInfoAccessor.WithContext(ctx).RotateCSPAuth(cspId, in.GetAuth(), auth.Subject(ctx), cspAuthOverlap); err != nil {
 		return &pb.SimpleResponse{
 			Code: pb.Code_INTERNAL,
 			Error: &pb.Error{
@@ -168,7 +220,11 @@ func (s *CspInfoServer) GetCSPAuth(ctx context.Context, in *pb.IDRequest) (*pb.G
 		return &res, err
 	}
 
-	cspInfo, err2 := cspInfoAccessor.GetCSPInfo(cspId)
+	if md, _ := metadata.FromIncomingContext(ctx); len(md.Get(cspAuthVersionKey)) > 0 {
+		return getCSPAuthVersion(ctx, cspId, md.Get(cspAuthVersionKey)[0])
+	}
+
+	cspInfo, err2 := cspInfoAccessor.WithContext(ctx).GetCSPInfo(cspId)
 	if err2 != nil {
 		res := pb.GetCSPAuthResponse{
 			Code: pb.Code_NOT_FOUND,
@@ -179,9 +235,54 @@ func (s *CspInfoServer) GetCSPAuth(ctx context.Context, in *pb.IDRequest) (*pb.G
 		return &res, err2
 	}
 
+	if cspInfo.AuthVersion > 0 {
+		setCSPAuthVersionHeader(ctx, cspInfo.AuthVersion)
+	}
 	return &pb.GetCSPAuthResponse{
 		Code:  pb.Code_OK_UNSPECIFIED,
 		Error: nil,
 		Auth:  cspInfo.Auth,
 	}, nil
 }
+
+// getCSPAuthVersion returns the auth of the version if it is still valid.
+func getCSPAuthVersion(ctx context.Context, cspId uuid.UUID, value string) (*pb.GetCSPAuthResponse, error) {
+	version, err := strconv.Atoi(value)
+	if err != nil || version <= 0 {
+		err = fmt.Errorf("invalid %s %q", cspAuthVersionKey, value)
+		return &pb.GetCSPAuthResponse{
+			Code: pb.Code_INVALID_ARGUMENT,
+			Error: &pb.Error{
+				Msg: err.Error(),
+			},
+		}, err
+	}
+
+	cspAuth, err := cspInfoAccessor.WithContext(ctx).GetValidCSPAuth(cspId, version)
+	if err != nil {
+		code := pb.Code_NOT_FOUND
+		if errors.Is(err, csp_info.ErrInvalidAuthVersion) {
+			code = pb.Code_FAILED_PRECONDITION
+		}
+		return &pb.GetCSPAuthResponse{
+			Code: code,
+			Error: &pb.Error{
+				Msg: err.Error(),
+			},
+		}, err
+	}
+
+	setCSPAuthVersionHeader(ctx, cspAuth.Version)
+	return &pb.GetCSPAuthResponse{
+		Code:  pb.Code_OK_UNSPECIFIED,
+		Error: nil,
+		Auth:  cspAuth.Auth,
+	}, nil
+}
+
+func setCSPAuthVersionHeader(ctx context.Context, version int) {
+	// Calls without a gRPC stream, such as those in tests, have no header to set.
+	if err := grpc.SetHeader(ctx, metadata.Pairs(cspAuthVersionKey, strconv.Itoa(version))); err != nil {
+		log.Debug("failed to set header of CSP auth version: ", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/openinfradev/tks-common/pkg/helper"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
@@ -52,6 +53,18 @@ func TestCreateCSPInfo(t *testing.T) {
 				require.Equal(t, res.Code, pb.Code_INVALID_ARGUMENT)
 			},
 		},
+		{
+			name: "INVALID_AUTH",
+			in: &pb.CreateCSPInfoRequest{
+				ContractId: requestCreateCSPInfo.ContractId,
+				Auth:       `{"accessKeyId":"key"}`,
+				CspType:    pb.CspType_AWS,
+			},
+			checkResponse: func(req *pb.CreateCSPInfoRequest, res *pb.IDResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, res.Code, pb.Code_INVALID_ARGUMENT)
+			},
+		},
 		{
 			name: "OK_DUPLICATE",
 			in:   requestCreateCSPInfo,
@@ -197,13 +210,24 @@ func TestUpdateCSPAuth(t *testing.T) {
 			name: "OK",
 			in: &pb.UpdateCSPAuthRequest{
 				CspId: createdCspInfoId,
-				Auth:  "updated_pem",
+				Auth:  `{"accessKeyId":"updated","secretAccessKey":"updated"}`,
 			},
 			checkResponse: func(req *pb.UpdateCSPAuthRequest, res *pb.SimpleResponse, err error) {
 				require.NoError(t, err)
 				require.Equal(t, res.Code, pb.Code_OK_UNSPECIFIED)
 
-				requestCreateCSPInfo.Auth = "updated_pem"
+				requestCreateCSPInfo.Auth = req.Auth
+			},
+		},
+		{
+			name: "INVALID_AUTH",
+			in: &pb.UpdateCSPAuthRequest{
+				CspId: createdCspInfoId,
+				Auth:  `{"accessKeyId":"updated"}`,
+			},
+			checkResponse: func(req *pb.UpdateCSPAuthRequest, res *pb.SimpleResponse, err error) {
+				require.Error(t, err)
+				require.Equal(t, res.Code, pb.Code_INVALID_ARGUMENT)
 			},
 		},
 		{
@@ -223,7 +247,7 @@ func TestUpdateCSPAuth(t *testing.T) {
 			},
 			checkResponse: func(req *pb.UpdateCSPAuthRequest, res *pb.SimpleResponse, err error) {
 				require.Error(t, err)
-				require.Equal(t, res.Code, pb.Code_INTERNAL)
+				require.Equal(t, res.Code, pb.Code_NOT_FOUND)
 			},
 		},
 	}
@@ -296,13 +320,37 @@ func TestGetCSPAuth(t *testing.T) {
 	}
 }
 
+func TestGetCSPAuthVersion(t *testing.T) {
+	testCases := []struct {
+		name    string
+		version string
+		code    pb.Code
+	}{
+		{name: "INVALID_VERSION", version: "latest", code: pb.Code_INVALID_ARGUMENT},
+		{name: "NOT_VALID_VERSION", version: "100", code: pb.Code_FAILED_PRECONDITION},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(cspAuthVersionKey, tc.version))
+
+			s := CspInfoServer{}
+			res, err := s.GetCSPAuth(ctx, &pb.IDRequest{Id: createdCspInfoId})
+			require.Error(t, err)
+			require.Equal(t, tc.code, res.Code)
+		})
+	}
+}
+
 // Helpers
 
 func randomCreateCSPInfoRequest() *pb.CreateCSPInfoRequest {
 	return &pb.CreateCSPInfoRequest{
 		ContractId: helper.GenerateContractId(),
 		CspName:    randomString("cspname"),
-		Auth:       "premkey",
+		Auth:       `{"accessKeyId":"key","secretAccessKey":"secret"}`,
 		CspType:    pb.CspType_AWS,
 	}
 }
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/openinfradev/tks-info/pkg/integrity"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// errorCode returns the response code for an error returned by accessors.
+// fallback is used for errors which are not related to referential integrity.
+func errorCode(err error, fallback pb.Code) pb.Code {
+	switch {
+	case errors.Is(err, integrity.ErrNotFound):
+		return pb.Code_NOT_FOUND
+	case errors.Is(err, integrity.ErrContractMismatch), errors.Is(err, integrity.ErrInUse):
+		return pb.Code_FAILED_PRECONDITION
+	default:
+		return fallback
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// createServer works like grpc_server.CreateServer of tks-common,
+// but runs the given interceptors after recovery and IO logging.
+// The server serves plaintext if creds is nil.
+func createServer(port int, creds credentials.TransportCredentials,
+	interceptors []grpc.UnaryServerInterceptor, opts ...grpc.ServerOption) (*grpc.Server, net.Listener, error) {
+	log.Info("Starting to listen port ", port)
+
+	lis, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		log.Error("failed to listen:", err)
+		return nil, nil, err
+	}
+
+	serverOptions := append([]grpc.ServerOption{
+		grpc.UnaryInterceptor(chainUnaryServer(interceptors)),
+	}, opts...)
+
+	if creds != nil {
+		log.Info("TLS enabled!!!")
+		serverOptions = append(serverOptions, grpc.Creds(creds))
+	}
+
+	return grpc.NewServer(serverOptions...), lis, nil
+}
+
+// chainUnaryServer chains recovery, IO logging and the given interceptors.
+func chainUnaryServer(interceptors []grpc.UnaryServerInterceptor) grpc.UnaryServerInterceptor {
+	chain := append([]grpc.UnaryServerInterceptor{
+		grpc_recovery.UnaryServerInterceptor(),
+		log.IOLoggingForServerSide(),
+	}, interceptors...)
+	return grpc_middleware.ChainUnaryServer(chain...)
+}
+
+// createContractClient works like grpc_client.CreateContractClient of tks-common,
+// but runs the given interceptors after IO logging.
+func createContractClient(address string, port int, tlsEnabled bool, certPath string,
+	interceptors ...grpc.UnaryClientInterceptor) (*grpc.ClientConn, pb.ContractServiceClient, error) {
+	creds := insecure.NewCredentials()
+	if tlsEnabled {
+		var err error
+		creds, err = credentials.NewClientTLSFromFile(certPath, "")
+		if err != nil {
+			log.Error("Fail to load client credentials: ", err)
+			return nil, nil, err
+		}
+	}
+
+	chain := append([]grpc.UnaryClientInterceptor{
+		log.IOLoggingForClientSide(),
+	}, interceptors...)
+	cc, err := grpc.Dial(
+		fmt.Sprintf("%s:%d", address, port),
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(grpc_middleware.ChainUnaryClient(chain...)),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cc, pb.NewContractServiceClient(cc), nil
+}
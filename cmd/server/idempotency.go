@@ -0,0 +1,15 @@
+package main
+
+import (
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// idempotentMethods are the RPCs creating resources with new ids, which replay
+// the first result when called again with the same idempotency key.
+var idempotentMethods = []string{
+	methodName(pb.ClusterInfoService_ServiceDesc.ServiceName, "AddClusterInfo"),
+	methodName(pb.CspInfoService_ServiceDesc.ServiceName, "CreateCSPInfo"),
+	methodName(pb.AppInfoService_ServiceDesc.ServiceName, "CreateAppGroup"),
+	methodName(pb.AppServeAppService_ServiceDesc.ServiceName, "CreateAppServeApp"),
+	methodName(pb.KeycloakInfoService_ServiceDesc.ServiceName, "CreateKeycloakInfo"),
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/gateway"
+	"github.com/openinfradev/tks-info/pkg/inventory"
+)
+
+// inventoryService is the service of the gateway routes of inventories, which have no RPCs in tks-proto.
+const inventoryService = "tks_info.InventoryService"
+
+var (
+	inventoryAccessor *inventory.InventoryAccessor
+)
+
+func InitInventoryHandler(db *gorm.DB) {
+	inventoryAccessor = inventory.New(db)
+}
+
+type GetInventoryRequest struct {
+	ContractId string `json:"contract_id"`
+	// ShowAll includes deleted app serve apps.
+	ShowAll bool `json:"show_all"`
+}
+
+// inventoryRoutes returns the gateway routes of inventories.
+func inventoryRoutes() []gateway.Route {
+	return []gateway.Route{{
+		Service:    inventoryService,
+		Method:     "GetInventory",
+		Summary:    "every CSP, cluster, app group, application, keycloak info and app serve app of a contract as a tree",
+		NewRequest: func() interface{} { return &GetInventoryRequest{} },
+		Handler:    getInventory,
+	}}
+}
+
+// getInventory returns the resource tree of a contract, loading each kind of resource at once.
+func getInventory(ctx context.Context, req interface{}) (interface{}, error) {
+	in := req.(*GetInventoryRequest)
+	log.Info("request GetInventory for contract ID ", in.ContractId)
+
+	if !helper.ValidateContractId(in.ContractId) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid contract ID %s", in.ContractId)
+	}
+
+	inv, err := inventoryAccessor.WithContext(ctx).GetInventory(in.ContractId, in.ShowAll)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !canReadSecrets(ctx) {
+		inv.StripSecrets()
+	}
+	return inv, nil
+}
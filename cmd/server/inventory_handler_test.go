@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	modelCluster "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/inventory"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+func TestGetInventory(t *testing.T) {
+	contractId := helper.GenerateContractId()
+	cspId, err := cspInfoAccessor.Create(contractId, "csp", "auth", pb.CspType_CSPTYPE_UNSPECIFIED)
+	require.NoError(t, err)
+	cluster := modelCluster.Cluster{ContractID: contractId, CspID: cspId, Name: randomString("Name"), Kubeconfig: "kubeconfig"}
+	require.NoError(t, db.Create(&cluster).Error)
+	_, err = keycloakInfoAccessor.Create(cluster.ID, "realm", "client", "secret", "private key")
+	require.NoError(t, err)
+
+	reader := auth.NewContext(context.Background(), &auth.Identity{Roles: []auth.Role{auth.RoleReader}})
+	testCases := []struct {
+		name          string
+		ctx           context.Context
+		in            *GetInventoryRequest
+		checkResponse func(res interface{}, err error)
+	}{
+		{
+			name: "OK",
+			ctx:  context.Background(),
+			in:   &GetInventoryRequest{ContractId: contractId},
+			checkResponse: func(res interface{}, err error) {
+				require.NoError(t, err)
+				inv := res.(*inventory.Inventory)
+				require.Len(t, inv.Csps, 1)
+				require.Len(t, inv.Csps[0].Clusters, 1)
+				ci := inv.Csps[0].Clusters[0]
+				require.Equal(t, "kubeconfig", ci.Cluster.GetKubeconfig())
+				require.Equal(t, "secret", ci.KeycloakInfos[0].GetSecret())
+			},
+		},
+		{
+			name: "READER_WITHOUT_SECRETS",
+			ctx:  reader,
+			in:   &GetInventoryRequest{ContractId: contractId},
+			checkResponse: func(res interface{}, err error) {
+				require.NoError(t, err)
+				ci := res.(*inventory.Inventory).Csps[0].Clusters[0]
+				require.Empty(t, ci.Cluster.GetKubeconfig())
+				require.Empty(t, ci.KeycloakInfos[0].GetSecret())
+				require.Empty(t, ci.KeycloakInfos[0].GetPrivateKey())
+			},
+		},
+		{
+			name: "INVALID_CONTRACT_ID",
+			ctx:  context.Background(),
+			in:   &GetInventoryRequest{ContractId: "NO_ID_STRING"},
+			checkResponse: func(res interface{}, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := getInventory(tc.ctx, tc.in)
+			tc.checkResponse(res, err)
+		})
+	}
+}
@@ -37,10 +37,10 @@ func (s *KeycloakInfoServer) CreateKeycloakInfo(ctx context.Context, in *pb.Crea
 		}, fmt.Errorf("invalid cluster ID %s", clusterId)
 	}
 
-	id, err := keycloakInfoAccessor.Create(clusterId, in.GetRealm(), in.GetClientId(), in.GetSecret(), in.GetPrivateKey())
+	id, err := keycloakInfoAccessor.WithContext(ctx).Create(clusterId, in.GetRealm(), in.GetClientId(), in.GetSecret(), in.GetPrivateKey())
 	if err != nil {
 		return &pb.IDResponse{
-			Code: pb.Code_INTERNAL,
+			Code: errorCode(err, pb.Code_INTERNAL),
 			Error: &pb.Error{
 				Msg: err.Error(),
 			},
@@ -66,7 +66,7 @@ func (s *KeycloakInfoServer) GetKeycloakInfoByClusterId(ctx context.Context, in
 		}, fmt.Errorf("invalid cluster ID %s", clusterId)
 	}
 
-	keycloakInfos, err := keycloakInfoAccessor.GetKeycloakInfos(clusterId)
+	keycloakInfos, err := keycloakInfoAccessor.WithContext(ctx).GetKeycloakInfos(clusterId)
 	if err != nil {
 		return &pb.GetKeycloakInfoResponse{
 			Code: pb.Code_INTERNAL,
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	"github.com/openinfradev/tks-info/pkg/gateway"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+	"github.com/openinfradev/tks-info/pkg/label"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// labelService is the service of the gateway routes of labels, which have no RPCs in tks-proto.
+const labelService = "tks_info.LabelService"
+
+// Kinds of resources which have labels.
+const (
+	kindCluster     = "cluster"
+	kindAppGroup    = "app_group"
+	kindAppServeApp = "app_serve_app"
+)
+
+type GetLabelsRequest struct {
+	Kind string `json:"kind"`
+	Id   string `json:"id"`
+}
+
+type GetLabelsResponse struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type UpdateLabelsRequest struct {
+	Kind string `json:"kind"`
+	Id   string `json:"id"`
+	// Labels and Annotations replace those of the resource. A missing field leaves them as they are,
+	// and an empty object removes all of them.
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type ListBySelectorRequest struct {
+	Kind string `json:"kind"`
+	// ContractId is the contract of clusters and app serve apps, and ClusterId is the cluster of app groups.
+	ContractId string `json:"contract_id"`
+	ClusterId  string `json:"cluster_id"`
+	// Selector is a label selector such as "env=prod,team in (infra,sre),!deprecated". Empty selects all.
+	Selector string `json:"selector"`
+	// ShowAll includes deleted app serve apps.
+	ShowAll bool `json:"show_all"`
+}
+
+// ListBySelectorResponse has the resources of the requested kind.
+type ListBySelectorResponse struct {
+	Clusters     []*pb.Cluster     `json:"clusters,omitempty"`
+	AppGroups    []*pb.AppGroup    `json:"app_groups,omitempty"`
+	AppServeApps []*pb.AppServeApp `json:"app_serve_apps,omitempty"`
+}
+
+// labelRoutes returns the gateway routes of labels.
+func labelRoutes() []gateway.Route {
+	return []gateway.Route{
+		{
+			Service:    labelService,
+			Method:     "GetLabels",
+			Summary:    "labels and annotations of a cluster, an app group or an app serve app",
+			NewRequest: func() interface{} { return &GetLabelsRequest{} },
+			Handler:    getLabels,
+		},
+		{
+			Service:    labelService,
+			Method:     "UpdateLabels",
+			Summary:    "replace labels or annotations of a cluster, an app group or an app serve app",
+			NewRequest: func() interface{} { return &UpdateLabelsRequest{} },
+			Handler:    updateLabels,
+		},
+		{
+			Service:    labelService,
+			Method:     "ListBySelector",
+			Summary:    "clusters, app groups or app serve apps whose labels match a selector",
+			NewRequest: func() interface{} { return &ListBySelectorRequest{} },
+			Handler:    listBySelector,
+		},
+	}
+}
+
+// labeledContract returns the contract of the resource with labels.
+func labeledContract(ctx context.Context, r *auth.Resolver, kind string, id string) (string, error) {
+	switch kind {
+	case kindCluster:
+		return r.ClusterContract(ctx, id)
+	case kindAppGroup:
+		return r.AppGroupContract(ctx, id)
+	case kindAppServeApp:
+		return r.AppServeAppContract(ctx, id)
+	}
+	return "", fmt.Errorf("%w: %s %s", integrity.ErrNotFound, kind, id)
+}
+
+func getLabels(ctx context.Context, req interface{}) (interface{}, error) {
+	in := req.(*GetLabelsRequest)
+	log.Info("request GetLabels for ", in.Kind, " ", in.Id)
+
+	res := &GetLabelsResponse{}
+	var err error
+	switch in.Kind {
+	case kindCluster:
+		res.Labels, res.Annotations, err = clusterAccessor.WithContext(ctx).GetLabels(in.Id)
+	case kindAppGroup:
+		res.Labels, res.Annotations, err = acc.WithContext(ctx).GetAppGroupLabels(in.Id)
+	case kindAppServeApp:
+		id, parseErr := uuid.Parse(in.Id)
+		if parseErr != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid app serve app ID %s", in.Id)
+		}
+		res.Labels, res.Annotations, err = asaAccessor.WithContext(ctx).GetLabels(id)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown kind %s", in.Kind)
+	}
+	if err != nil {
+		return nil, labelStatus(err)
+	}
+	return res, nil
+}
+
+func updateLabels(ctx context.Context, req interface{}) (interface{}, error) {
+	in := req.(*UpdateLabelsRequest)
+	log.Info("request UpdateLabels for ", in.Kind, " ", in.Id)
+
+	if in.Labels == nil && in.Annotations == nil {
+		return nil, status.Error(codes.InvalidArgument, "labels or annotations are required")
+	}
+	if err := label.Validate(in.Labels); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := label.ValidateAnnotations(in.Annotations); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var err error
+	switch in.Kind {
+	case kindCluster:
+		err = clusterAccessor.WithContext(ctx).UpdateLabels(in.Id, in.Labels, in.Annotations)
+	case kindAppGroup:
+		err = acc.WithContext(ctx).UpdateAppGroupLabels(in.Id, in.Labels, in.Annotations)
+	case kindAppServeApp:
+		id, parseErr := uuid.Parse(in.Id)
+		if parseErr != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid app serve app ID %s", in.Id)
+		}
+		err = asaAccessor.WithContext(ctx).UpdateLabels(id, in.Labels, in.Annotations)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown kind %s", in.Kind)
+	}
+	if err != nil {
+		return nil, labelStatus(err)
+	}
+	return &GetLabelsResponse{Labels: in.Labels, Annotations: in.Annotations}, nil
+}
+
+func listBySelector(ctx context.Context, req interface{}) (interface{}, error) {
+	in := req.(*ListBySelectorRequest)
+	log.Info("request ListBySelector for ", in.Kind, " with selector ", in.Selector)
+
+	selector, err := label.ParseSelector(in.Selector)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	res := &ListBySelectorResponse{}
+	switch in.Kind {
+	case kindCluster:
+		if res.Clusters, err = clusterAccessor.WithContext(ctx).GetClustersBySelector(in.ContractId, selector); err == nil {
+			hideKubeconfigs(ctx, res.Clusters...)
+		}
+	case kindAppGroup:
+		res.AppGroups, err = acc.WithContext(ctx).GetAppGroupsBySelector(in.ClusterId, selector)
+	case kindAppServeApp:
+		res.AppServeApps, err = asaAccessor.WithContext(ctx).GetAppServeAppsBySelector(in.ContractId, selector, in.ShowAll)
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown kind %s", in.Kind)
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return res, nil
+}
+
+// labelStatus returns the status of an error of a resource with labels.
+func labelStatus(err error) error {
+	if errors.Is(err, integrity.ErrNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
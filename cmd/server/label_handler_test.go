@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	modelCluster "github.com/openinfradev/tks-info/pkg/cluster/model"
+)
+
+func TestLabels(t *testing.T) {
+	contractId := helper.GenerateContractId()
+	cluster := modelCluster.Cluster{ContractID: contractId, Name: randomString("Name"), Kubeconfig: "kubeconfig"}
+	require.NoError(t, db.Create(&cluster).Error)
+
+	res, err := updateLabels(context.Background(), &UpdateLabelsRequest{
+		Kind:        kindCluster,
+		Id:          cluster.ID,
+		Labels:      map[string]string{"env": "prod"},
+		Annotations: map[string]string{"owner": "infra team"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "prod", res.(*GetLabelsResponse).Labels["env"])
+
+	res, err = getLabels(context.Background(), &GetLabelsRequest{Kind: kindCluster, Id: cluster.ID})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"env": "prod"}, res.(*GetLabelsResponse).Labels)
+	require.Equal(t, map[string]string{"owner": "infra team"}, res.(*GetLabelsResponse).Annotations)
+
+	reader := auth.NewContext(context.Background(), &auth.Identity{Roles: []auth.Role{auth.RoleReader}})
+	res, err = listBySelector(reader, &ListBySelectorRequest{Kind: kindCluster, ContractId: contractId, Selector: "env in (prod,stage)"})
+	require.NoError(t, err)
+	clusters := res.(*ListBySelectorResponse).Clusters
+	require.Len(t, clusters, 1)
+	require.Equal(t, cluster.ID, clusters[0].GetId())
+	require.Empty(t, clusters[0].GetKubeconfig())
+
+	testCases := []struct {
+		name string
+		call func() error
+		code codes.Code
+	}{
+		{
+			name: "INVALID_LABEL",
+			call: func() error {
+				_, err := updateLabels(context.Background(), &UpdateLabelsRequest{Kind: kindCluster, Id: cluster.ID, Labels: map[string]string{"env": "not valid"}})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "NOTHING_TO_UPDATE",
+			call: func() error {
+				_, err := updateLabels(context.Background(), &UpdateLabelsRequest{Kind: kindCluster, Id: cluster.ID})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "UNKNOWN_KIND",
+			call: func() error {
+				_, err := getLabels(context.Background(), &GetLabelsRequest{Kind: "csp", Id: cluster.ID})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+		{
+			name: "NOT_FOUND",
+			call: func() error {
+				_, err := getLabels(context.Background(), &GetLabelsRequest{Kind: kindCluster, Id: helper.GenerateClusterId()})
+				return err
+			},
+			code: codes.NotFound,
+		},
+		{
+			name: "INVALID_SELECTOR",
+			call: func() error {
+				_, err := listBySelector(context.Background(), &ListBySelectorRequest{Kind: kindCluster, ContractId: contractId, Selector: "env in (prod"})
+				return err
+			},
+			code: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.code, status.Code(tc.call()))
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/audit"
+	"github.com/openinfradev/tks-info/pkg/audit/model"
+	"github.com/openinfradev/tks-info/pkg/reconcile"
+	"github.com/openinfradev/tks-info/pkg/webhook"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// reconcilerMethod is the method of audit logs of changes made by the reconciler, which is not an RPC.
+const reconcilerMethod = "tks-info/reconciler"
+
+// reconcileEventHandler records the changes made by the reconciler in the audit log, unless auditor is nil,
+// and notifies webhooks of them.
+func reconcileEventHandler(auditor *audit.Accessor) func(reconcile.Event) {
+	return func(e reconcile.Event) {
+		if auditor != nil {
+			if err := auditor.Record(newReconcileAuditLog(e)); err != nil {
+				log.Warn("failed to record audit log of ", e.Kind, " ", e.ID, ". err : ", err)
+			}
+		}
+		notify(context.Background(), webhook.NewStatusEvent(e.Kind, e.ID, "", e.ContractID, e.ToStatus, e.StatusDesc))
+	}
+}
+
+func newReconcileAuditLog(e reconcile.Event) *model.AuditLog {
+	resourceIDs, _ := json.Marshal([]string{e.ID})
+	request, _ := json.Marshal(e)
+	return &model.AuditLog{
+		Caller:      "tks-info",
+		Method:      reconcilerMethod,
+		ContractID:  e.ContractID,
+		ResourceIDs: resourceIDs,
+		Request:     request,
+		Code:        pb.Code_OK_UNSPECIFIED.String(),
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openinfradev/tks-info/pkg/reconcile"
+)
+
+func TestNewReconcileAuditLog(t *testing.T) {
+	e := reconcile.Event{
+		Kind:       reconcile.KindCluster,
+		ID:         "c0000001",
+		ContractID: "P0000001",
+		FromStatus: "INSTALLING",
+		ToStatus:   "ERROR",
+	}
+	auditLog := newReconcileAuditLog(e)
+	require.Equal(t, reconcilerMethod, auditLog.Method)
+	require.Equal(t, "P0000001", auditLog.ContractID)
+	require.JSONEq(t, `["c0000001"]`, string(auditLog.ResourceIDs))
+	require.Equal(t, "OK_UNSPECIFIED", auditLog.Code)
+
+	var recorded reconcile.Event
+	require.NoError(t, json.Unmarshal(auditLog.Request, &recorded))
+	require.Equal(t, e, recorded)
+
+	// Without audit logging, events are only logged.
+	reconcileEventHandler(nil)(e)
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+)
+
+// job is background work which runs periodically on a single replica.
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(ctx context.Context) error
+}
+
+// scheduler runs jobs on the replica which is the leader, so that jobs don't run
+// on every replica. Without leader election, every replica is the leader.
+type scheduler struct {
+	isLeader func() bool
+	jobs     []job
+}
+
+func newScheduler(isLeader func() bool) *scheduler {
+	if isLeader == nil {
+		isLeader = func() bool { return true }
+	}
+	return &scheduler{isLeader: isLeader}
+}
+
+// add registers a job which runs at every interval. A run is given the interval to complete.
+func (s *scheduler) add(name string, interval time.Duration, run func(ctx context.Context) error) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Run runs the jobs until ctx is done, and returns when runs in progress complete.
+func (s *scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, j := range s.jobs {
+		wg.Add(1)
+		go func(j job) {
+			defer wg.Done()
+			s.runJob(ctx, j)
+		}(j)
+	}
+	wg.Wait()
+}
+
+func (s *scheduler) runJob(ctx context.Context, j job) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !s.isLeader() {
+			continue
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, j.interval)
+		start := time.Now()
+		if err := j.run(runCtx); err != nil && ctx.Err() == nil {
+			log.Warn("job ", j.name, " failed. err : ", err)
+		} else {
+			log.Debug("job ", j.name, " completed in ", time.Since(start))
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedulerRunsJobsOnLeader(t *testing.T) {
+	var leader int32
+	s := newScheduler(func() bool { return atomic.LoadInt32(&leader) == 1 })
+
+	var runs, failures int32
+	s.add("count", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	s.add("fail", 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&failures, 1)
+		return errors.New("failed")
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(t, atomic.LoadInt32(&runs), "jobs ran on a follower")
+
+	atomic.StoreInt32(&leader, 1)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) >= 2 && atomic.LoadInt32(&failures) >= 2
+	}, time.Second, 10*time.Millisecond, "failed jobs must not stop")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler didn't stop")
+	}
+}
+
+func TestSchedulerWithoutElection(t *testing.T) {
+	s := newScheduler(nil)
+	require.True(t, s.isLeader())
+}
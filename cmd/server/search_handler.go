@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/gateway"
+	"github.com/openinfradev/tks-info/pkg/search"
+)
+
+// searchService is the service of the gateway route of searches, which has no RPC in tks-proto.
+const searchService = "tks_info.SearchService"
+
+var (
+	searchAccessor *search.Accessor
+)
+
+func InitSearchHandler(db *gorm.DB) {
+	searchAccessor = search.New(db)
+}
+
+// searchRoutes returns the gateway routes of searches.
+func searchRoutes() []gateway.Route {
+	return []gateway.Route{{
+		Service:    searchService,
+		Method:     "Search",
+		Summary:    "clusters and app groups matching the text, statuses, creator and label selector",
+		NewRequest: func() interface{} { return &search.Query{} },
+		Handler:    searchResources,
+	}}
+}
+
+// searchResources returns the clusters and app groups matching the query.
+// Searches of all contracts are allowed only to callers with access to all contracts.
+func searchResources(ctx context.Context, req interface{}) (interface{}, error) {
+	in := req.(*search.Query)
+	log.Info("request Search for text ", in.Text, " in contract ID ", in.ContractID)
+
+	result, err := searchAccessor.WithContext(ctx).Search(*in)
+	if errors.Is(err, search.ErrInvalidQuery) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	} else if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return result, nil
+}
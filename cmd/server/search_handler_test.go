@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	modelCluster "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/search"
+)
+
+func TestSearch(t *testing.T) {
+	contractId := helper.GenerateContractId()
+	name := randomString("Searched")
+	cluster := modelCluster.Cluster{ContractID: contractId, Name: name, Kubeconfig: "kubeconfig"}
+	require.NoError(t, db.Create(&cluster).Error)
+
+	testCases := []struct {
+		name          string
+		in            *search.Query
+		checkResponse func(res interface{}, err error)
+	}{
+		{
+			name: "OK",
+			in:   &search.Query{Text: name[:len(name)-2], ContractID: contractId},
+			checkResponse: func(res interface{}, err error) {
+				require.NoError(t, err)
+				result := res.(*search.Result)
+				require.Len(t, result.Clusters, 1)
+				require.Equal(t, cluster.ID, result.Clusters[0].GetId())
+				require.Empty(t, result.Clusters[0].GetKubeconfig())
+				require.EqualValues(t, 1, result.TotalClusters)
+			},
+		},
+		{
+			name: "INVALID_QUERY",
+			in:   &search.Query{ContractID: contractId, SortBy: "name"},
+			checkResponse: func(res interface{}, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := searchResources(context.Background(), tc.in)
+			tc.checkResponse(res, err)
+		})
+	}
+}
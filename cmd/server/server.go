@@ -1,75 +1,223 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"errors"
 	"flag"
-	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
 
-	"gorm.io/driver/postgres"
-	"gorm.io/gorm"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 
-	"github.com/openinfradev/tks-common/pkg/grpc_client"
-	"github.com/openinfradev/tks-common/pkg/grpc_server"
 	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/audit"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	"github.com/openinfradev/tks-info/pkg/contract"
+	"github.com/openinfradev/tks-info/pkg/database"
+	"github.com/openinfradev/tks-info/pkg/gateway"
+	"github.com/openinfradev/tks-info/pkg/health"
+	"github.com/openinfradev/tks-info/pkg/idempotency"
+	"github.com/openinfradev/tks-info/pkg/leader"
+	"github.com/openinfradev/tks-info/pkg/lifecycle"
+	"github.com/openinfradev/tks-info/pkg/limits"
+	"github.com/openinfradev/tks-info/pkg/metrics"
+	"github.com/openinfradev/tks-info/pkg/reconcile"
+	"github.com/openinfradev/tks-info/pkg/tlsconfig"
+	"github.com/openinfradev/tks-info/pkg/tracing"
+	"github.com/openinfradev/tks-info/pkg/webhook"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 )
 
 var (
+	configPath        string
 	port              int
 	tlsEnabled        bool
 	tlsClientCertPath string
 	tlsCertPath       string
 	tlsKeyPath        string
+	tlsClientCAPath   string
+	tlsAllowedSANs    string
+	tlsReloadInterval time.Duration
 
-	contractAddress string
-	contractPort    int
-	dbhost          string
-	dbport          string
-	dbuser          string
-	dbpassword      string
+	contractAddress       string
+	contractPort          int
+	contractCheckEnabled  bool
+	contractCheckFailOpen bool
+	contractCacheTTL      time.Duration
+	cspAuthOverlap        time.Duration
+	healthProbeInterval   time.Duration
+	healthProbeTimeout    time.Duration
+	metricsPort           int
+	httpPort              int
+	shutdownTimeout       time.Duration
+	traceConfig           tracing.Config
+	auditEnabled          bool
+	idempotencyTTL        time.Duration
+	leaderElection        bool
+	leaderLeaseDuration   time.Duration
+	leaderID              string
+	reconcileInterval     time.Duration
+	reconcileClusters     string
+	reconcileAppGroups    string
+	webhookEnabled        bool
+	webhookInterval       time.Duration
+	webhookTimeout        time.Duration
+	webhookMaxAttempts    int
+	webhookMinBackoff     time.Duration
+	rateLimit             string
+	rateLimitMethods      string
+	maxRecvMsgSize        int
+	authEnabled           bool
+	authJWKSURL           string
+	authJWKSRefresh       time.Duration
+	authJWTIssuer         string
+	authJWTAudience       string
+	authRolesClaim        string
+	authContractsClaim    string
+	dbConfig              database.Config
 )
 
 var (
 	contractClient pb.ContractServiceClient
 )
 
+// contractDependentServices returns the services which can't serve requests without tks-contract.
+func contractDependentServices() []string {
+	services := []string{pb.ClusterInfoService_ServiceDesc.ServiceName}
+	if contractCheckEnabled && !contractCheckFailOpen {
+		services = append(services,
+			pb.AppServeAppService_ServiceDesc.ServiceName,
+			pb.CspInfoService_ServiceDesc.ServiceName)
+	}
+	return services
+}
+
+// registerServices registers the tks-info services to the gRPC server or the HTTP gateway.
+func registerServices(r grpc.ServiceRegistrar) {
+	pb.RegisterAppInfoServiceServer(r, &AppInfoServer{})
+	pb.RegisterAppServeAppServiceServer(r, &AppServeAppServer{})
+	pb.RegisterClusterInfoServiceServer(r, &ClusterInfoServer{})
+	pb.RegisterCspInfoServiceServer(r, &CspInfoServer{})
+	pb.RegisterKeycloakInfoServiceServer(r, &KeycloakInfoServer{})
+}
+
+// routes returns the gateway routes which have no RPCs in tks-proto.
+func routes() []gateway.Route {
+	routes := append(inventoryRoutes(), searchRoutes()...)
+	routes = append(routes, labelRoutes()...)
+	return append(routes, cspAuthRoutes()...)
+}
+
+// registerRoutes registers the routes to the HTTP gateway.
+func registerRoutes(gw *gateway.Gateway) {
+	for _, route := range routes() {
+		gw.HandleRoute(route)
+	}
+}
+
 func init() {
+	flag.StringVar(&configPath, "config", "", "path of YAML or TOML config file")
 	flag.IntVar(&port, "port", 9111, "service port")
 	flag.BoolVar(&tlsEnabled, "tlsEnabled", false, "enabled tls")
 	flag.StringVar(&tlsClientCertPath, "tls-client-cert-path", "../../cert/tks-ca.crt", "path of ca cert file for tls")
 	flag.StringVar(&tlsCertPath, "tls-cert-path", "../../cert/tks-server.crt", "path of cert file for tls")
 	flag.StringVar(&tlsKeyPath, "tls-key-path", "../../cert/tks-server.key", "path of key file for tls")
+	flag.StringVar(&tlsClientCAPath, "tls-client-ca-path", "", "path of CA bundle to verify client certs, client certs are not required if empty")
+	flag.StringVar(&tlsAllowedSANs, "tls-allowed-sans", "", "comma separated patterns of client cert SANs allowed to connect, such as *.tks.svc")
+	flag.DurationVar(&tlsReloadInterval, "tls-reload-interval", 30*time.Second, "interval of checking cert, key and CA files for changes")
 	flag.StringVar(&contractAddress, "contract-address", "localhost", "service address for tks-contract")
 	flag.IntVar(&contractPort, "contract-port", 9110, "service port for tks-contract")
-	flag.StringVar(&dbhost, "dbhost", "localhost", "host of postgreSQL")
-	flag.StringVar(&dbport, "dbport", "5432", "port of postgreSQL")
-	flag.StringVar(&dbuser, "dbuser", "postgres", "postgreSQL user")
-	flag.StringVar(&dbpassword, "dbpassword", "password", "password for postgreSQL user")
+	flag.BoolVar(&contractCheckEnabled, "contract-check-enabled", true, "check that contracts exist in tks-contract on create requests")
+	flag.BoolVar(&contractCheckFailOpen, "contract-check-fail-open", false, "accept contracts when tks-contract is unreachable")
+	flag.DurationVar(&contractCacheTTL, "contract-cache-ttl", time.Minute, "how long an existing contract is cached")
+	flag.DurationVar(&cspAuthOverlap, "csp-auth-overlap", time.Hour, "how long the previous CSP auth stays valid after rotation")
+	flag.DurationVar(&healthProbeInterval, "health-probe-interval", 10*time.Second, "interval of health checks on database and tks-contract")
+	flag.DurationVar(&healthProbeTimeout, "health-probe-timeout", 3*time.Second, "timeout of each health check")
+	flag.IntVar(&metricsPort, "metrics-port", 0, "port to expose prometheus metrics on /metrics, 0 to disable")
+	flag.IntVar(&httpPort, "http-port", 0, "port to serve RPCs as JSON over HTTP under /v1/, 0 to disable")
+	flag.DurationVar(&shutdownTimeout, "shutdown-timeout", 30*time.Second, "how long in-flight requests are waited for on shutdown")
+	flag.BoolVar(&auditEnabled, "audit-enabled", true, "record mutating RPCs in the audit_logs table")
+	flag.DurationVar(&idempotencyTTL, "idempotency-ttl", 24*time.Hour, "duration to replay results of create RPCs called with the same idempotency-key metadata, disabled if 0")
+	flag.BoolVar(&leaderElection, "leader-election", false, "run background jobs only on the replica elected as the leader with the leases table")
+	flag.DurationVar(&leaderLeaseDuration, "leader-lease-duration", 15*time.Second, "how long the leader holds the lease without renewing it, which is renewed at a third of it")
+	flag.StringVar(&leaderID, "leader-id", "", "ID of the replica in leader election, the host name with a random suffix if empty")
+	flag.DurationVar(&reconcileInterval, "reconcile-interval", 5*time.Minute, "interval of marking clusters and app groups stuck in an in-progress status as errored, disabled if 0")
+	flag.StringVar(&reconcileClusters, "reconcile-cluster-timeouts", "INSTALLING=6h,DELETING=3h", "how long clusters may stay in in-progress statuses before marked as errored")
+	flag.StringVar(&reconcileAppGroups, "reconcile-app-group-timeouts", "INSTALLING=3h,DELETING=3h", "how long app groups may stay in in-progress statuses before marked as errored")
+	flag.BoolVar(&webhookEnabled, "webhook-enabled", false, "notify webhooks in the webhook_subscriptions table of status updates of clusters, app groups and app serve apps")
+	flag.DurationVar(&webhookInterval, "webhook-interval", 10*time.Second, "interval of sending queued webhook notifications")
+	flag.DurationVar(&webhookTimeout, "webhook-timeout", 5*time.Second, "timeout of each webhook request")
+	flag.IntVar(&webhookMaxAttempts, "webhook-max-attempts", 8, "number of attempts to send a notification before it is moved to webhook_dead_letters")
+	flag.DurationVar(&webhookMinBackoff, "webhook-min-backoff", 30*time.Second, "delay of the first retry of a notification, which doubles for each failure up to an hour")
+	flag.StringVar(&rateLimit, "rate-limit", "", "default limit of requests per caller and RPC as <rate per second>:<burst>, disabled if empty")
+	flag.StringVar(&rateLimitMethods, "rate-limit-methods", "", "limits of RPCs overriding rate-limit, such as UpdateAppServeAppStatus=1:5,GetClusters=50:100")
+	flag.IntVar(&maxRecvMsgSize, "max-recv-msg-size", 4<<20, "maximum size of a request in bytes")
+	flag.BoolVar(&authEnabled, "auth-enabled", false, "authenticate and authorize requests with JWT or client certificates")
+	flag.StringVar(&authJWKSURL, "auth-jwks-url", "", "JWKS URL to verify JWT, such as https://keycloak/realms/<realm>/protocol/openid-connect/certs")
+	flag.DurationVar(&authJWKSRefresh, "auth-jwks-refresh", 5*time.Minute, "interval of refreshing keys from JWKS URL")
+	flag.StringVar(&authJWTIssuer, "auth-jwt-issuer", "", "expected issuer of JWT, not checked if empty")
+	flag.StringVar(&authJWTAudience, "auth-jwt-audience", "", "expected audience of JWT, not checked if empty")
+	flag.StringVar(&authRolesClaim, "auth-roles-claim", "realm_access.roles", "claim of JWT holding roles")
+	flag.StringVar(&authContractsClaim, "auth-contracts-claim", "contracts", "claim of JWT holding contract ids")
+	flag.StringVar(&traceConfig.Exporter, "trace-exporter", tracing.ExporterNone, "exporter of traces: none, otlp, stdout or file")
+	flag.StringVar(&traceConfig.Endpoint, "trace-endpoint", "localhost:4317", "address of OTLP gRPC collector")
+	flag.BoolVar(&traceConfig.Insecure, "trace-insecure", true, "disable TLS to OTLP collector")
+	flag.StringVar(&traceConfig.File, "trace-file", "traces.json", "path of file to write traces to with file exporter")
+	flag.Float64Var(&traceConfig.SampleRatio, "trace-sample-ratio", 1.0, "ratio of requests to trace")
+	dbConfig.RegisterFlags(flag.CommandLine)
 }
 
 func main() {
 	flag.Parse()
 
-	log.Info("*** Arguments *** ")
-	log.Info("port : ", port)
-	log.Info("tlsEnabled : ", tlsEnabled)
-	log.Info("tlsClientCertPath : ", tlsClientCertPath)
-	log.Info("tlsCertPath : ", tlsCertPath)
-	log.Info("tlsKeyPath : ", tlsKeyPath)
-	log.Info("contractAddress : ", contractAddress)
-	log.Info("contractPort : ", contractPort)
-	log.Info("dbhost : ", dbhost)
-	log.Info("dbport : ", dbport)
-	log.Info("dbuser : ", dbuser)
-	log.Info("dbpassword : ", dbpassword)
-	log.Info("****************** ")
+	if err := loadConfig(); err != nil {
+		log.Fatal("failed to load configuration : ", err)
+	}
+	logConfig()
+
+	lc := lifecycle.New(shutdownTimeout)
 
 	// initialize database
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=tks port=%s sslmode=disable TimeZone=Asia/Seoul",
-		dbhost, dbuser, dbpassword, dbport)
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := database.Open(dbConfig)
 	if err != nil {
 		log.Fatal("failed to open database ", err)
 	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatal("failed to get database pool ", err)
+	}
+	lc.OnShutdown("database", func(ctx context.Context) error {
+		return sqlDB.Close()
+	})
+
+	// initialize background jobs
+	var elector *leader.Elector
+	var isLeader func() bool
+	if leaderElection {
+		if leaderID == "" {
+			leaderID = leader.DefaultID()
+		}
+		elector = leader.NewElector(db, "tks-info", leaderID, leaderLeaseDuration)
+		isLeader = elector.IsLeader
+	}
+	jobs := newScheduler(isLeader)
+
+	// initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), traceConfig)
+	if err != nil {
+		log.Fatal("failed to initialize tracing : ", err)
+	}
+	lc.OnShutdown("tracing", shutdownTracing)
+	if err := db.Use(tracing.NewGormPlugin()); err != nil {
+		log.Fatal("failed to register database tracing : ", err)
+	}
 
 	// initialize handlers
 	InitAppInfoHandler(db)
@@ -77,24 +225,210 @@ func main() {
 	InitClusterInfoHandler(db)
 	InitCspInfoHandler(db)
 	InitKeycloakInfoHandler(db)
+	InitInventoryHandler(db)
+	InitSearchHandler(db)
 
 	// initialize clients
-	if _, contractClient, err = grpc_client.CreateContractClient(contractAddress, contractPort, tlsEnabled, tlsClientCertPath); err != nil {
+	var contractConn *grpc.ClientConn
+	contractConn, contractClient, err = createContractClient(contractAddress, contractPort, tlsEnabled, tlsClientCertPath,
+		otelgrpc.UnaryClientInterceptor())
+	if err != nil {
 		log.Fatal("failed to create contract client : ", err)
 	}
+	lc.OnShutdown("contract client", func(ctx context.Context) error {
+		return contractConn.Close()
+	})
+	if contractCheckEnabled {
+		contractValidator = contract.NewValidator(contractClient, contractCacheTTL, contractCheckFailOpen)
+	}
+
+	// initialize metrics
+	interceptors := []grpc.UnaryServerInterceptor{otelgrpc.UnaryServerInterceptor()}
+	if metricsPort != 0 {
+		m := metrics.New()
+		if err := m.RegisterDB(db); err != nil {
+			log.Fatal("failed to register database metrics : ", err)
+		}
+		interceptors = append(interceptors, m.UnaryServerInterceptor())
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", m.Handler())
+		metricsServer := &http.Server{Addr: ":" + strconv.Itoa(metricsPort), Handler: mux}
+		lc.Go("metrics server", func() error {
+			if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+		lc.OnShutdown("metrics server", metricsServer.Shutdown)
+	}
+
+	// initialize authentication
+	if authEnabled {
+		authenticators := auth.Authenticators{}
+		if authJWKSURL != "" {
+			jwks := auth.NewJWKS(authJWKSURL, authJWKSRefresh)
+			authenticators = append(authenticators, auth.NewJWTAuthenticator(jwks.Keyfunc,
+				authJWTIssuer, authJWTAudience, authRolesClaim, authContractsClaim))
+		}
+		authenticators = append(authenticators, auth.TLSAuthenticator{})
+		interceptors = append(interceptors,
+			auth.UnaryServerInterceptor(authenticators, authPolicy(auth.NewResolver(db)), publicMethods...))
+	}
+
+	// initialize limits
+	if rateLimit != "" {
+		defaultLimit, _ := limits.ParseLimit(rateLimit)
+		methodLimits, _ := limits.ParseMethodLimits(rateLimitMethods)
+		rateLimiter := limits.NewRateLimiter(defaultLimit, methodLimits)
+		evictCtx, stopEvict := context.WithCancel(context.Background())
+		go rateLimiter.Run(evictCtx, 10*time.Minute)
+		lc.OnShutdown("rate limiter", func(ctx context.Context) error {
+			stopEvict()
+			return nil
+		})
+		interceptors = append(interceptors, rateLimiter.UnaryServerInterceptor())
+	}
+	interceptors = append(interceptors, limits.FieldLengthInterceptor())
+
+	// initialize audit logging
+	var auditor *audit.Accessor
+	if auditEnabled {
+		auditor = audit.New(db)
+		interceptors = append(interceptors, audit.UnaryServerInterceptor(auditor, auditPolicy(auth.NewResolver(db))))
+	}
+
+	// initialize webhooks
+	if webhookEnabled {
+		webhookAccessor = webhook.New(db)
+		dispatcher := webhook.NewDispatcher(webhookAccessor, webhookTimeout, webhookMaxAttempts, webhookMinBackoff)
+		jobs.add("deliver webhooks", webhookInterval, dispatcher.Deliver)
+	}
+
+	// initialize stale status reconciler
+	if reconcileInterval > 0 {
+		clusterTimeouts, _ := reconcile.ParseClusterTimeouts(reconcileClusters)
+		appGroupTimeouts, _ := reconcile.ParseAppGroupTimeouts(reconcileAppGroups)
+		reconciler := reconcile.New(db, clusterTimeouts, appGroupTimeouts, reconcileEventHandler(auditor))
+		jobs.add("reconcile stale statuses", reconcileInterval, reconciler.Reconcile)
+	}
+
+	// initialize idempotency keys
+	if idempotencyTTL > 0 {
+		guard := idempotency.NewGuard(idempotency.New(db), idempotencyTTL, idempotentMethods...)
+		jobs.add("delete expired idempotency keys", 10*time.Minute, guard.DeleteExpired)
+		interceptors = append(interceptors, guard.UnaryServerInterceptor())
+	}
+
+	// start background jobs
+	if elector != nil {
+		electCtx, stopElect := context.WithCancel(context.Background())
+		electDone := make(chan struct{})
+		go func() {
+			elector.Run(electCtx)
+			close(electDone)
+		}()
+		lc.OnShutdown("leader election", func(ctx context.Context) error {
+			stopElect()
+			<-electDone
+			return elector.Release(ctx)
+		})
+	}
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	jobsDone := make(chan struct{})
+	go func() {
+		jobs.Run(jobsCtx)
+		close(jobsDone)
+	}()
+	lc.OnShutdown("background jobs", func(ctx context.Context) error {
+		stopJobs()
+		select {
+		case <-jobsDone:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
 
 	// start server
-	s, conn, err := grpc_server.CreateServer(port, tlsEnabled, tlsCertPath, tlsKeyPath)
+	var creds credentials.TransportCredentials
+	var httpTLSConfig *tls.Config
+	if tlsEnabled {
+		reloader, err := tlsconfig.NewReloader(tlsCertPath, tlsKeyPath, tlsClientCAPath, tlsReloadInterval)
+		if err != nil {
+			log.Fatal("failed to load TLS credentials : ", err)
+		}
+		reloadCtx, stopReload := context.WithCancel(context.Background())
+		go reloader.Run(reloadCtx)
+		lc.OnShutdown("tls reloader", func(ctx context.Context) error {
+			stopReload()
+			return nil
+		})
+		creds = credentials.NewTLS(reloader.ServerConfig(splitList(tlsAllowedSANs)))
+		httpTLSConfig = reloader.HTTPServerConfig(splitList(tlsAllowedSANs))
+	}
+	s, conn, err := createServer(port, creds, interceptors, grpc.MaxRecvMsgSize(maxRecvMsgSize))
 	if err != nil {
 		log.Fatal("failed to crate grpc_server : ", err)
 	}
 
-	pb.RegisterAppInfoServiceServer(s, &AppInfoServer{})
-	pb.RegisterAppServeAppServiceServer(s, &AppServeAppServer{})
-	pb.RegisterClusterInfoServiceServer(s, &ClusterInfoServer{})
-	pb.RegisterCspInfoServiceServer(s, &CspInfoServer{})
-	pb.RegisterKeycloakInfoServiceServer(s, &KeycloakInfoServer{})
-	if err := s.Serve(conn); err != nil {
-		log.Fatal("failed to serve: ", err)
+	registerServices(s)
+
+	// health checking
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(s, healthServer)
+	prober := health.NewProber(healthServer, []string{
+		pb.AppInfoService_ServiceDesc.ServiceName,
+		pb.AppServeAppService_ServiceDesc.ServiceName,
+		pb.ClusterInfoService_ServiceDesc.ServiceName,
+		pb.CspInfoService_ServiceDesc.ServiceName,
+		pb.KeycloakInfoService_ServiceDesc.ServiceName,
+	}, healthProbeInterval, healthProbeTimeout)
+	prober.AddCheck("database", health.DBCheck(db))
+	prober.AddCheck("tks-contract", health.ConnCheck(contractConn), contractDependentServices()...)
+	probeCtx, stopProbe := context.WithCancel(context.Background())
+	go prober.Run(probeCtx)
+
+	lc.Go("grpc server", func() error {
+		return s.Serve(conn)
+	})
+	lc.OnShutdown("grpc server", lifecycle.StopGRPCServer(s))
+	if httpPort != 0 {
+		gw := gateway.New(chainUnaryServer(interceptors))
+		gw.SetMaxBodySize(maxRecvMsgSize)
+		registerServices(gw)
+		registerRoutes(gw)
+		openAPI, err := gw.OpenAPIHandler("tks-info", "v1")
+		if err != nil {
+			log.Fatal("failed to generate OpenAPI document : ", err)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle(gateway.PathPrefix, gw)
+		mux.Handle("/openapi.json", openAPI)
+		httpServer := &http.Server{Addr: ":" + strconv.Itoa(httpPort), Handler: mux, TLSConfig: httpTLSConfig}
+		lc.Go("http gateway", func() error {
+			var err error
+			if httpTLSConfig != nil {
+				err = httpServer.ListenAndServeTLS("", "")
+			} else {
+				err = httpServer.ListenAndServe()
+			}
+			if !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		})
+		lc.OnShutdown("http gateway", httpServer.Shutdown)
+	}
+	// Report NOT_SERVING first so that clients stop sending new requests while draining.
+	lc.OnShutdown("health checking", func(ctx context.Context) error {
+		stopProbe()
+		healthServer.Shutdown()
+		return nil
+	})
+
+	if err := lc.Wait(context.Background()); err != nil {
+		os.Exit(1)
 	}
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/openinfradev/tks-common/pkg/helper"
 	"github.com/openinfradev/tks-common/pkg/log"
 
+	modelAsa "github.com/openinfradev/tks-info/pkg/app_serve_app/model"
 	modelApplication "github.com/openinfradev/tks-info/pkg/application/model"
 	modelCluster "github.com/openinfradev/tks-info/pkg/cluster/model"
 	modelCspInfoInfo "github.com/openinfradev/tks-info/pkg/csp_info/model"
@@ -57,14 +58,31 @@ func TestMain(m *testing.M) {
 	if err := db.AutoMigrate(&modelCspInfoInfo.CSPInfo{}); err != nil {
 		os.Exit(-1)
 	}
+	if err := db.AutoMigrate(&modelCspInfoInfo.CSPAuth{}); err != nil {
+		os.Exit(-1)
+	}
 	if err := db.AutoMigrate(&modelKeyCloackInfo.KeycloakInfo{}); err != nil {
 		os.Exit(-1)
 	}
+	if err := db.AutoMigrate(&modelAsa.AppServeApp{}, &modelAsa.AppServeAppTask{}); err != nil {
+		os.Exit(-1)
+	}
 
 	InitAppInfoHandler(db)
 	InitKeycloakInfoHandler(db)
 	InitClusterInfoHandler(db)
+	InitAppServeAppHandler(db)
 	InitCspInfoHandler(db)
+	InitInventoryHandler(db)
+	InitSearchHandler(db)
+
+	// App groups and keycloak infos can only be created on an existing cluster.
+	cluster := modelCluster.Cluster{Name: randomString("Name")}
+	if res := db.Create(&cluster); res.Error != nil {
+		os.Exit(-1)
+	}
+	requestCreateAppGroup.ClusterId = cluster.ID
+	requestCreateKeycloakInfo.ClusterId = cluster.ID
 
 	code := m.Run()
 
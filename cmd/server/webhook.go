@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/webhook"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+var (
+	// webhookAccessor queues notifications of status updates, which are not sent if it is nil.
+	webhookAccessor *webhook.Accessor
+)
+
+// notify queues the event for the webhooks subscribing to it. Failures don't fail the status update.
+func notify(ctx context.Context, e webhook.Event) {
+	if webhookAccessor == nil {
+		return
+	}
+	if _, err := webhookAccessor.WithContext(ctx).Publish(e); err != nil {
+		log.Warn("failed to publish event of ", e.ResourceType, " ", e.ResourceID, " to webhooks. err : ", err)
+	}
+}
+
+func notifyClusterStatus(ctx context.Context, clusterId string, status pb.ClusterStatus, statusDesc string) {
+	if webhookAccessor == nil {
+		return
+	}
+	cluster, err := clusterAccessor.WithContext(ctx).GetCluster(clusterId)
+	if err != nil {
+		log.Warn("failed to get cluster ", clusterId, " to notify webhooks. err : ", err)
+		return
+	}
+	notify(ctx, webhook.NewStatusEvent(webhook.ResourceCluster, clusterId, cluster.GetName(), cluster.GetContractId(), status.String(), statusDesc))
+}
+
+func notifyAppGroupStatus(ctx context.Context, appGroupId string, status pb.AppGroupStatus, statusDesc string) {
+	if webhookAccessor == nil {
+		return
+	}
+	appGroup, err := acc.WithContext(ctx).GetAppGroup(appGroupId)
+	if err != nil {
+		log.Warn("failed to get app group ", appGroupId, " to notify webhooks. err : ", err)
+		return
+	}
+	cluster, err := clusterAccessor.WithContext(ctx).GetCluster(appGroup.GetClusterId())
+	if err != nil {
+		log.Warn("failed to get cluster of app group ", appGroupId, " to notify webhooks. err : ", err)
+		return
+	}
+	notify(ctx, webhook.NewStatusEvent(webhook.ResourceAppGroup, appGroupId, appGroup.GetAppGroupName(), cluster.GetContractId(), status.String(), statusDesc))
+}
+
+func notifyAppServeAppStatus(ctx context.Context, taskId uuid.UUID, status string, output string) {
+	if webhookAccessor == nil {
+		return
+	}
+	app, err := asaAccessor.WithContext(ctx).GetAppServeAppByTaskId(taskId)
+	if err != nil {
+		log.Warn("failed to get app serve app of task ", taskId, " to notify webhooks. err : ", err)
+		return
+	}
+	notify(ctx, webhook.NewStatusEvent(webhook.ResourceAppServeApp, app.GetId(), app.GetName(), app.GetContractId(), status, output))
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-info/pkg/audit"
+	"github.com/openinfradev/tks-info/pkg/audit/model"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// adminMethodPrefix is the prefix of the methods of audit logs of changes made by commands, which are not RPCs.
+const adminMethodPrefix = "tks-info-admin/"
+
+func runAudit(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	filter := audit.Filter{}
+	var since, until, output string
+	fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&filter.ContractID, "contract-id", "", "id of the contract, all contracts if empty")
+	fs.StringVar(&filter.ResourceID, "resource-id", "", "id of a resource such as a cluster or a CSP, which the calls had in the request or created")
+	fs.StringVar(&since, "since", "", "RFC3339 time or duration before now, such as 24h, of the earliest calls")
+	fs.StringVar(&until, "until", "", "RFC3339 time or duration before now of the end of the calls, exclusive")
+	fs.IntVar(&filter.Limit, "limit", 100, "maximum number of audit logs, up to 1000")
+	fs.StringVar(&output, "o", "table", "output format: table or json, which includes the requests")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	if output != "table" && output != "json" {
+		return fmt.Errorf("unknown output format %q", output)
+	}
+	var err error
+	now := time.Now()
+	if filter.Since, err = parseTime(since, now); err != nil {
+		return fmt.Errorf("invalid -since: %w", err)
+	}
+	if filter.Until, err = parseTime(until, now); err != nil {
+		return fmt.Errorf("invalid -until: %w", err)
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	auditLogs, err := audit.New(db).Query(filter)
+	if err != nil {
+		return err
+	}
+	if output == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(auditLogs)
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tCALLER\tPEER\tMETHOD\tCONTRACT\tRESOURCES\tCODE\tERROR")
+	for _, l := range auditLogs {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", l.CreatedAt.Format(time.RFC3339), l.Caller, l.Peer, l.Method,
+			l.ContractID, string(l.ResourceIDs), l.Code, l.Error)
+	}
+	return tw.Flush()
+}
+
+// parseTime parses an RFC3339 time, or a duration before now. The empty string is the zero time.
+func parseTime(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// registerBy registers -by of the commands which change resources.
+func registerBy(fs *flag.FlagSet, by *string) {
+	fs.StringVar(by, "by", "", "who makes the change, recorded as the caller in the audit log, the OS user if empty")
+}
+
+// callerOf returns the caller of audit logs of a command, which is by, or the OS user running the command.
+func callerOf(by string) (string, error) {
+	if by != "" {
+		return by, nil
+	}
+	u, err := user.Current()
+	if err != nil || u.Username == "" {
+		return "", errors.New("-by is required, as the OS user is unknown")
+	}
+	return u.Username, nil
+}
+
+// recordAudit records a change made by the command in the audit log, as the server does for RPCs.
+// db should be the transaction of the change, so that the change is not made without its audit log.
+func recordAudit(db *gorm.DB, caller string, command string, contractId string, resourceIds []string, request interface{}) error {
+	if resourceIds == nil {
+		resourceIds = []string{}
+	}
+	resourceIDs, err := json.Marshal(resourceIds)
+	if err != nil {
+		return err
+	}
+	req, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	// Commands run on the host of the operator, which is recorded instead of a peer address.
+	host, _ := os.Hostname()
+	return audit.New(db).Record(&model.AuditLog{
+		Caller:      caller,
+		Peer:        host,
+		Method:      adminMethodPrefix + command,
+		ContractID:  contractId,
+		ResourceIDs: resourceIDs,
+		Request:     req,
+		Code:        pb.Code_OK_UNSPECIFIED.String(),
+	})
+}
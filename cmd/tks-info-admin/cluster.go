@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-info/pkg/cluster"
+)
+
+// deletePolicies are the values of -policy of cluster delete.
+var deletePolicies = map[string]cluster.DeletePolicy{
+	"refuse":  cluster.DeleteRefuse,
+	"cascade": cluster.DeleteCascade,
+	"orphan":  cluster.DeleteOrphan,
+}
+
+func runCluster(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		printClusterUsage(stderr)
+		return errors.New("a cluster command is required")
+	}
+	switch args[0] {
+	case "dependents":
+		return runClusterDependents(args[1:], stdout, stderr)
+	case "delete":
+		return runClusterDelete(args[1:], stdout, stderr)
+	}
+	printClusterUsage(stderr)
+	return fmt.Errorf("unknown cluster command %q", args[0])
+}
+
+func runClusterDependents(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	fs := flag.NewFlagSet("cluster dependents", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin cluster dependents [flags] <cluster id>")
+		fs.PrintDefaults()
+	}
+	id, err := parseClusterIDArg(fs, args)
+	if err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	accessor := cluster.New(db)
+	if _, err := accessor.GetCluster(id); err != nil {
+		return err
+	}
+	dependents, err := accessor.GetDependents(id)
+	if err != nil {
+		return err
+	}
+	return printDependents(stdout, dependents)
+}
+
+func runClusterDelete(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var policyFlag, by string
+	fs := flag.NewFlagSet("cluster delete", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	registerBy(fs, &by)
+	fs.StringVar(&policyFlag, "policy", "refuse", "what to do with app groups, keycloak infos and AppServeApps of the cluster: refuse to delete, cascade to delete them, or orphan them")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin cluster delete [flags] <cluster id>")
+		fs.PrintDefaults()
+	}
+	id, err := parseClusterIDArg(fs, args)
+	if err != nil {
+		return err
+	}
+	policy, ok := deletePolicies[policyFlag]
+	if !ok {
+		return fmt.Errorf("unknown policy %q", policyFlag)
+	}
+	caller, err := callerOf(by)
+	if err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	var dependents *cluster.Dependents
+	err = db.Transaction(func(tx *gorm.DB) error {
+		accessor := cluster.New(tx)
+		c, err := accessor.GetCluster(id)
+		if err != nil {
+			return err
+		}
+		if dependents, err = accessor.DeleteCluster(id, policy); err != nil {
+			return err
+		}
+		return recordAudit(tx, caller, "cluster delete", c.GetContractId(), []string{id},
+			map[string]interface{}{"cluster_id": id, "policy": policyFlag, "dependents": dependents})
+	})
+	var dependentsErr *cluster.DependentsError
+	if errors.As(err, &dependentsErr) {
+		fmt.Fprintln(stderr, "The cluster still has dependents. Delete them first, or use -policy cascade or orphan.")
+		if err := printDependents(stderr, dependentsErr.Dependents); err != nil {
+			return err
+		}
+		return cluster.ErrHasDependents
+	} else if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(stdout, "Deleted cluster", id)
+	if dependents.Empty() {
+		return nil
+	}
+	if policy == cluster.DeleteCascade {
+		fmt.Fprintln(stdout, "Deleted dependents:")
+	} else {
+		fmt.Fprintln(stdout, "Orphaned dependents:")
+	}
+	return printDependents(stdout, dependents)
+}
+
+// parseClusterIDArg parses the flags followed by a cluster id.
+func parseClusterIDArg(fs *flag.FlagSet, args []string) (string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return "", errors.New("a cluster id is required")
+	}
+	if !helper.ValidateClusterId(fs.Arg(0)) {
+		return "", fmt.Errorf("invalid cluster ID %q", fs.Arg(0))
+	}
+	return fs.Arg(0), nil
+}
+
+func printDependents(w io.Writer, dependents *cluster.Dependents) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tID")
+	for _, id := range dependents.AppGroupIds {
+		fmt.Fprintf(tw, "%s\t%s\n", kindAppGroup, id)
+	}
+	for _, id := range dependents.KeycloakInfoIds {
+		fmt.Fprintf(tw, "keycloak_info\t%s\n", id)
+	}
+	for _, id := range dependents.AppServeAppIds {
+		fmt.Fprintf(tw, "%s\t%s\n", kindAppServeApp, id)
+	}
+	return tw.Flush()
+}
+
+func printClusterUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tks-info-admin cluster <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	fmt.Fprintln(w, "  dependents  list app groups, keycloak infos and AppServeApps of a cluster")
+	fmt.Fprintln(w, "  delete      delete a cluster, refusing while it has dependents unless -policy says otherwise")
+}
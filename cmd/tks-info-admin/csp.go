@@ -0,0 +1,257 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-info/pkg/csp_info"
+)
+
+func runCsp(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		printCspUsage(stderr)
+		return errors.New("a csp command is required")
+	}
+	switch args[0] {
+	case "list":
+		return runCspList(args[1:], stdout, stderr)
+	case "rename":
+		return runCspRename(args[1:], stdout, stderr)
+	case "delete":
+		return runCspDelete(args[1:], stdout, stderr)
+	case "auth-history":
+		return runCspAuthHistory(args[1:], stdout, stderr)
+	case "rollback":
+		return runCspRollback(args[1:], stdout, stderr)
+	}
+	printCspUsage(stderr)
+	return fmt.Errorf("unknown csp command %q", args[0])
+}
+
+func runCspList(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var contractId string
+	fs := flag.NewFlagSet("csp list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&contractId, "contract-id", "", "id of the contract")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !helper.ValidateContractId(contractId) {
+		return fmt.Errorf("invalid contract ID %q", contractId)
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	cspInfos, err := csp_info.New(db).GetCSPInfosByContractID(contractId)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tTYPE\tCREATED")
+	for _, c := range cspInfos {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", c.ID, c.Name, c.CspType, c.CreatedAt.Format(time.RFC3339))
+	}
+	return tw.Flush()
+}
+
+func runCspRename(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var name, by string
+	fs := flag.NewFlagSet("csp rename", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	registerBy(fs, &by)
+	fs.StringVar(&name, "name", "", "new name of the CSP")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin csp rename [flags] <csp id>")
+		fs.PrintDefaults()
+	}
+	id, err := parseIDArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return errors.New("-name is required")
+	}
+	caller, err := callerOf(by)
+	if err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		accessor := csp_info.New(tx)
+		cspInfo, err := accessor.GetCSPInfo(id)
+		if err != nil {
+			return err
+		}
+		if err := accessor.UpdateName(id, name); err != nil {
+			return err
+		}
+		return recordAudit(tx, caller, "csp rename", cspInfo.ContractID, []string{id.String()},
+			map[string]interface{}{"csp_id": id.String(), "name": name})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Renamed csp", id, "to", name)
+	return nil
+}
+
+func runCspDelete(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var by string
+	fs := flag.NewFlagSet("csp delete", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	registerBy(fs, &by)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin csp delete [flags] <csp id>")
+		fs.PrintDefaults()
+	}
+	id, err := parseIDArg(fs, args)
+	if err != nil {
+		return err
+	}
+	caller, err := callerOf(by)
+	if err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		accessor := csp_info.New(tx)
+		cspInfo, err := accessor.GetCSPInfo(id)
+		if err != nil {
+			return err
+		}
+		// Delete refuses while clusters are still created on the CSP.
+		if err := accessor.Delete(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, caller, "csp delete", cspInfo.ContractID, []string{id.String()},
+			map[string]interface{}{"csp_id": id.String()})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Deleted csp", id)
+	return nil
+}
+
+func runCspAuthHistory(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	fs := flag.NewFlagSet("csp auth-history", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin csp auth-history [flags] <csp id>")
+		fs.PrintDefaults()
+	}
+	id, err := parseIDArg(fs, args)
+	if err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	accessor := csp_info.New(db)
+	cspInfo, err := accessor.GetCSPInfo(id)
+	if err != nil {
+		return err
+	}
+	history, err := accessor.GetCSPAuthHistory(id)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tACTIVE\tROTATED\tROTATED BY\tVALID UNTIL\tREVOKED\tREVOKED BY")
+	for _, a := range history {
+		active := ""
+		if a.Version == cspInfo.AuthVersion {
+			active = "*"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", a.Version, active, a.RotatedAt.Format(time.RFC3339), a.RotatedBy,
+			formatTime(a.ValidUntil), formatTime(a.RevokedAt), a.RevokedBy)
+	}
+	return tw.Flush()
+}
+
+func runCspRollback(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var by string
+	fs := flag.NewFlagSet("csp rollback", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&by, "by", "", "who rolls back, recorded as the revoker of the active auth and the caller in the audit log, the OS user if empty")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin csp rollback [flags] <csp id>")
+		fs.PrintDefaults()
+	}
+	id, err := parseIDArg(fs, args)
+	if err != nil {
+		return err
+	}
+	if by, err = callerOf(by); err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	var version int
+	err = db.Transaction(func(tx *gorm.DB) error {
+		accessor := csp_info.New(tx)
+		cspInfo, err := accessor.GetCSPInfo(id)
+		if err != nil {
+			return err
+		}
+		if version, err = accessor.RollbackCSPAuth(id, by); err != nil {
+			return err
+		}
+		return recordAudit(tx, by, "csp rollback", cspInfo.ContractID, []string{id.String()},
+			map[string]interface{}{"csp_id": id.String(), "version": version})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Rolled back auth of csp", id, "to version", version)
+	return nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func printCspUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tks-info-admin csp <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	fmt.Fprintln(w, "  list          list CSPs of a contract")
+	fmt.Fprintln(w, "  rename        rename a CSP")
+	fmt.Fprintln(w, "  delete        delete a CSP which no cluster is created on")
+	fmt.Fprintln(w, "  auth-history  list versions of the auth of a CSP, without the auths")
+	fmt.Fprintln(w, "  rollback      revoke the active auth of a CSP and reactivate the previous one")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-info/pkg/inventory"
+)
+
+func runInventory(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var contractId string
+	var showAll bool
+	fs := flag.NewFlagSet("inventory", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&contractId, "contract-id", "", "id of the contract")
+	fs.BoolVar(&showAll, "show-all", false, "include deleted AppServeApps")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	if !helper.ValidateContractId(contractId) {
+		return fmt.Errorf("invalid contract ID %q", contractId)
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	inv, err := inventory.New(db).GetInventory(contractId, showAll)
+	if err != nil {
+		return err
+	}
+	printInventory(stdout, inv)
+	return nil
+}
+
+// printInventory prints the resource tree of a contract, indenting resources under their parents.
+// Secrets such as kubeconfigs and keycloak secrets are not printed.
+func printInventory(w io.Writer, inv *inventory.Inventory) {
+	line := func(depth int, format string, a ...interface{}) {
+		fmt.Fprintf(w, "%s"+format+"\n", append([]interface{}{strings.Repeat("  ", depth)}, a...)...)
+	}
+	printCluster := func(depth int, ci *inventory.ClusterInventory) {
+		c := ci.Cluster
+		line(depth, "cluster %s %s %s", c.GetId(), c.GetName(), c.GetStatus())
+		for _, g := range c.GetAppGroups() {
+			line(depth+1, "app_group %s %s %s %s", g.GetAppGroupId(), g.GetAppGroupName(), g.GetType(), g.GetStatus())
+			for _, app := range ci.Applications[g.GetAppGroupId()] {
+				line(depth+2, "app %s %s", app.GetType(), app.GetEndpoint())
+			}
+		}
+		for _, k := range ci.KeycloakInfos {
+			line(depth+1, "keycloak %s %s", k.GetRealm(), k.GetClientId())
+		}
+	}
+
+	line(0, "contract %s", inv.ContractId)
+	for _, csp := range inv.Csps {
+		line(1, "csp %s %s %s", csp.Id, csp.Name, csp.CspType)
+		for _, ci := range csp.Clusters {
+			printCluster(2, ci)
+		}
+	}
+	if len(inv.UnassignedClusters) > 0 {
+		line(1, "clusters without a csp of the contract")
+		for _, ci := range inv.UnassignedClusters {
+			printCluster(2, ci)
+		}
+	}
+	for _, asa := range inv.AppServeApps {
+		line(1, "app_serve_app %s %s %s %s", asa.GetId(), asa.GetName(), asa.GetStatus(), asa.GetTargetClusterId())
+	}
+}
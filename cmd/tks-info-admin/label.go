@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-info/pkg/app_serve_app"
+	"github.com/openinfradev/tks-info/pkg/application"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	"github.com/openinfradev/tks-info/pkg/cluster"
+	"github.com/openinfradev/tks-info/pkg/label"
+)
+
+// Kinds of resources which have labels.
+const (
+	kindCluster     = "cluster"
+	kindAppGroup    = "app_group"
+	kindAppServeApp = "app_serve_app"
+)
+
+func runLabel(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		printLabelUsage(stderr)
+		return errors.New("a label command is required")
+	}
+	switch args[0] {
+	case "set":
+		return runLabelSet(args[1:], stdout, stderr)
+	case "get":
+		return runLabelGet(args[1:], stdout, stderr)
+	case "list":
+		return runLabelList(args[1:], stdout, stderr)
+	}
+	printLabelUsage(stderr)
+	return fmt.Errorf("unknown label command %q", args[0])
+}
+
+func runLabelSet(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var labelsFlag, annotationsFlag, by string
+	fs := flag.NewFlagSet("label set", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	registerBy(fs, &by)
+	fs.StringVar(&labelsFlag, "labels", "", "comma separated key=value labels which replace the labels, or empty to remove them")
+	fs.StringVar(&annotationsFlag, "annotations", "", `JSON object of annotations which replace the annotations, such as {"note":"any text"}, or {} to remove them`)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin label set [flags] <cluster|app_group|app_serve_app> <id>")
+		fs.PrintDefaults()
+	}
+	kind, id, err := parseKindIDArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	// Labels and annotations are left as they are unless their flags are given.
+	var labels, annotations map[string]string
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "labels":
+			labels, err = parseLabels(labelsFlag)
+		case "annotations":
+			annotations = map[string]string{}
+			if jsonErr := json.Unmarshal([]byte(annotationsFlag), &annotations); jsonErr != nil {
+				err = fmt.Errorf("annotations must be a JSON object of strings: %w", jsonErr)
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if labels == nil && annotations == nil {
+		return errors.New("-labels or -annotations is required")
+	}
+	caller, err := callerOf(by)
+	if err != nil {
+		return err
+	}
+
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		resolver := auth.NewResolver(tx)
+		var contractId string
+		var err error
+		switch kind {
+		case kindCluster:
+			if contractId, err = resolver.ClusterContract(context.Background(), id); err == nil {
+				err = cluster.New(tx).UpdateLabels(id, labels, annotations)
+			}
+		case kindAppGroup:
+			if contractId, err = resolver.AppGroupContract(context.Background(), id); err == nil {
+				err = application.New(tx).UpdateAppGroupLabels(id, labels, annotations)
+			}
+		case kindAppServeApp:
+			var asaId uuid.UUID
+			if asaId, err = uuid.Parse(id); err != nil {
+				return err
+			}
+			if contractId, err = resolver.AppServeAppContract(context.Background(), id); err == nil {
+				err = app_serve_app.New(tx).UpdateLabels(asaId, labels, annotations)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, caller, "label set", contractId, []string{id},
+			map[string]interface{}{"kind": kind, "id": id, "labels": labels, "annotations": annotations})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Updated", kind, id)
+	return nil
+}
+
+func runLabelGet(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	fs := flag.NewFlagSet("label get", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin label get [flags] <cluster|app_group|app_serve_app> <id>")
+		fs.PrintDefaults()
+	}
+	kind, id, err := parseKindIDArgs(fs, args)
+	if err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	labels, annotations, err := getLabels(db, kind, id)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tKEY\tVALUE")
+	for _, key := range sortedKeys(labels) {
+		fmt.Fprintf(tw, "label\t%s\t%s\n", key, labels[key])
+	}
+	for _, key := range sortedKeys(annotations) {
+		fmt.Fprintf(tw, "annotation\t%s\t%s\n", key, annotations[key])
+	}
+	return tw.Flush()
+}
+
+func runLabelList(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var contractId, clusterId, selectorFlag string
+	var showAll bool
+	fs := flag.NewFlagSet("label list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&contractId, "contract-id", "", "id of the contract of clusters and AppServeApps")
+	fs.StringVar(&clusterId, "cluster-id", "", "id of the cluster of app groups")
+	fs.StringVar(&selectorFlag, "selector", "", "label selector such as \"env=prod,team in (infra,sre),!deprecated\", all resources if empty")
+	fs.BoolVar(&showAll, "show-all", false, "include deleted AppServeApps")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin label list [flags] <cluster|app_group|app_serve_app>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("a kind of resources is required")
+	}
+	kind := fs.Arg(0)
+	selector, err := label.ParseSelector(selectorFlag)
+	if err != nil {
+		return err
+	}
+	switch {
+	case kind != kindCluster && kind != kindAppGroup && kind != kindAppServeApp:
+		return fmt.Errorf("unknown kind %q", kind)
+	case kind == kindAppGroup && clusterId == "":
+		return errors.New("-cluster-id is required to list app groups")
+	case (kind == kindCluster || kind == kindAppServeApp) && contractId == "":
+		return fmt.Errorf("-contract-id is required to list %ss", kind)
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tSTATUS\tLABELS")
+	switch kind {
+	case kindCluster:
+		clusters, err := cluster.New(db).GetClustersBySelector(contractId, selector)
+		if err != nil {
+			return err
+		}
+		for _, c := range clusters {
+			if err := printLabeled(tw, db, kind, c.Id, c.Name, c.Status.String()); err != nil {
+				return err
+			}
+		}
+	case kindAppGroup:
+		appGroups, err := application.New(db).GetAppGroupsBySelector(clusterId, selector)
+		if err != nil {
+			return err
+		}
+		for _, g := range appGroups {
+			if err := printLabeled(tw, db, kind, g.AppGroupId, g.AppGroupName, g.Status.String()); err != nil {
+				return err
+			}
+		}
+	case kindAppServeApp:
+		apps, err := app_serve_app.New(db).GetAppServeAppsBySelector(contractId, selector, showAll)
+		if err != nil {
+			return err
+		}
+		for _, a := range apps {
+			if err := printLabeled(tw, db, kind, a.Id, a.Name, a.Status); err != nil {
+				return err
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+// parseKindIDArgs parses the flags followed by a kind of resources and an id.
+func parseKindIDArgs(fs *flag.FlagSet, args []string) (string, string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", "", err
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return "", "", errors.New("a kind of resources and an id are required")
+	}
+	kind := fs.Arg(0)
+	if kind != kindCluster && kind != kindAppGroup && kind != kindAppServeApp {
+		return "", "", fmt.Errorf("unknown kind %q", kind)
+	}
+	return kind, fs.Arg(1), nil
+}
+
+// parseLabels parses comma separated key=value labels. The empty string is no labels.
+func parseLabels(value string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, item := range splitList(value) {
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("label %q must be key=value", item)
+		}
+		labels[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return labels, nil
+}
+
+func getLabels(db *gorm.DB, kind string, id string) (map[string]string, map[string]string, error) {
+	switch kind {
+	case kindCluster:
+		return cluster.New(db).GetLabels(id)
+	case kindAppGroup:
+		return application.New(db).GetAppGroupLabels(id)
+	}
+	asaId, err := uuid.Parse(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	return app_serve_app.New(db).GetLabels(asaId)
+}
+
+func printLabeled(tw *tabwriter.Writer, db *gorm.DB, kind string, id string, name string, status string) error {
+	labels, _, err := getLabels(db, kind, id)
+	if err != nil {
+		return err
+	}
+	items := []string{}
+	for _, key := range sortedKeys(labels) {
+		items = append(items, key+"="+labels[key])
+	}
+	fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", id, name, status, strings.Join(items, ","))
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func printLabelUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tks-info-admin label <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	fmt.Fprintln(w, "  set   replace labels or annotations of a cluster, an app group or an AppServeApp")
+	fmt.Fprintln(w, "  get   show labels and annotations of a resource")
+	fmt.Fprintln(w, "  list  list resources whose labels match a selector")
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/bundle"
+	"github.com/openinfradev/tks-info/pkg/config"
+	"github.com/openinfradev/tks-info/pkg/database"
+)
+
+// envPrefix is the prefix of the server, so that the admin commands connect to the same database in its environment.
+const envPrefix = "TKS_INFO"
+
+const (
+	secretsStrip   = "strip"
+	secretsEncrypt = "encrypt"
+	secretsPlain   = "plain"
+)
+
+// options are the flags shared by the commands.
+type options struct {
+	configPath string
+	passphrase string
+	db         database.Config
+}
+
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.configPath, "config", "", "path of YAML or TOML config file with the database flags")
+	fs.StringVar(&o.passphrase, "passphrase", "", "passphrase of encrypted secrets, better given by "+config.EnvName(envPrefix, "passphrase-file"))
+	o.db.RegisterFlags(fs)
+}
+
+// load completes the flags from the environment and the config file, and connects to the database.
+func (o *options) load(fs *flag.FlagSet) (*gorm.DB, error) {
+	path := o.configPath
+	if path == "" {
+		path = os.Getenv(config.EnvName(envPrefix, "config"))
+	}
+	if err := config.Load(fs, path, envPrefix); err != nil {
+		return nil, err
+	}
+	if err := o.db.Validate(); err != nil {
+		return nil, err
+	}
+	db, err := database.Open(o.db)
+	if err != nil {
+		return nil, err
+	}
+	// Bundles may be written to stdout, which gorm logs to.
+	db.Logger = logger.Default.LogMode(logger.Silent)
+	return db, nil
+}
+
+func main() {
+	log.Disable()
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) int {
+	if len(args) == 0 {
+		printUsage(stderr)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "export":
+		err = runExport(args[1:], stdout, stderr)
+	case "import":
+		err = runImport(args[1:], stdin, stdout, stderr)
+	case "cluster":
+		err = runCluster(args[1:], stdout, stderr)
+	case "csp":
+		err = runCsp(args[1:], stdout, stderr)
+	case "audit":
+		err = runAudit(args[1:], stdout, stderr)
+	case "search":
+		err = runSearch(args[1:], stdout, stderr)
+	case "inventory":
+		err = runInventory(args[1:], stdout, stderr)
+	case "webhook":
+		err = runWebhook(args[1:], stdout, stderr)
+	case "label":
+		err = runLabel(args[1:], stdout, stderr)
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		printUsage(stderr)
+		return 2
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if err != nil {
+		fmt.Fprintln(stderr, "Error:", err)
+		return 1
+	}
+	return 0
+}
+
+func runExport(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var contractIds, secrets, format, output string
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&contractIds, "contract-id", "", "comma separated ids of contracts to export, all contracts if empty")
+	fs.StringVar(&secrets, "secrets", secretsStrip, "how secrets are exported: strip, encrypt with the passphrase, or plain")
+	fs.StringVar(&format, "format", "", "format of the bundle: json or yaml, by the extension of -o if empty")
+	fs.StringVar(&output, "o", "", "path of the bundle, stdout if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	if format == "" {
+		format = bundle.FormatJSON
+		if ext := strings.ToLower(filepath.Ext(output)); ext == ".yaml" || ext == ".yml" {
+			format = bundle.FormatYAML
+		}
+	}
+	if format != bundle.FormatJSON && format != bundle.FormatYAML {
+		return fmt.Errorf("unknown format %q", format)
+	}
+
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+	if secrets == secretsEncrypt && opts.passphrase == "" {
+		return errors.New("-secrets=encrypt needs a passphrase")
+	}
+
+	b, err := bundle.New(db).Export(splitList(contractIds))
+	if err != nil {
+		return err
+	}
+	switch secrets {
+	case secretsStrip:
+		err = b.StripSecrets()
+	case secretsEncrypt:
+		err = b.EncryptSecrets([]byte(opts.passphrase))
+	case secretsPlain:
+	default:
+		err = fmt.Errorf("unknown secrets %q", secrets)
+	}
+	if err != nil {
+		return err
+	}
+
+	data, err := bundle.Marshal(b, format)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(output, data, 0600)
+}
+
+func runImport(args []string, stdin io.Reader, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var onConflict, by string
+	var dryRun bool
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	registerBy(fs, &by)
+	fs.StringVar(&onConflict, "on-conflict", bundle.ConflictFail, "what to do with resources which already exist: fail, skip or overwrite")
+	fs.BoolVar(&dryRun, "dry-run", false, "report what would be imported without importing")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin import [flags] <bundle file, or - for stdin>")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("a bundle file is required")
+	}
+
+	var data []byte
+	var err error
+	if fs.Arg(0) == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	b, err := bundle.Unmarshal(data)
+	if err != nil {
+		return err
+	}
+	caller, err := callerOf(by)
+	if err != nil {
+		return err
+	}
+
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+	if b.Secrets == bundle.SecretsEncrypted {
+		if opts.passphrase == "" {
+			return errors.New("secrets of the bundle are encrypted, and need a passphrase")
+		}
+		if err := b.DecryptSecrets([]byte(opts.passphrase)); err != nil {
+			return err
+		}
+	}
+
+	var results []bundle.Result
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if results, err = bundle.New(tx).Import(b, onConflict, dryRun); err != nil || dryRun {
+			return err
+		}
+		// Bundles of all contracts have no contract ids, and are recorded without a contract.
+		contractId := ""
+		if len(b.ContractIds) == 1 {
+			contractId = b.ContractIds[0]
+		}
+		return recordAudit(tx, caller, "import", contractId, b.ContractIds, map[string]interface{}{
+			"contract_ids": b.ContractIds, "exported_at": b.ExportedAt, "on_conflict": onConflict, "results": results,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tCREATED\tUPDATED\tSKIPPED")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", r.Kind, r.Created, r.Updated, r.Skipped)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if dryRun {
+		fmt.Fprintln(stdout, "Dry run, nothing was imported.")
+	} else if b.Secrets == bundle.SecretsStripped {
+		fmt.Fprintln(stdout, "Secrets were stripped from the bundle. Set CSP auths and keycloak secrets of new resources again.")
+	}
+	return nil
+}
+
+// splitList splits a comma separated flag value.
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tks-info-admin <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	fmt.Fprintln(w, "  export     write resources of contracts to a bundle")
+	fmt.Fprintln(w, "  import     insert resources of a bundle")
+	fmt.Fprintln(w, "  cluster    delete clusters with their dependents")
+	fmt.Fprintln(w, "  csp        manage CSPs and the history of their auths")
+	fmt.Fprintln(w, "  audit      query the audit log")
+	fmt.Fprintln(w, "  search     search clusters and app groups by name, description and labels")
+	fmt.Fprintln(w, "  inventory  show every resource of a contract as a tree")
+	fmt.Fprintln(w, "  webhook    manage webhook subscriptions and dead letters")
+	fmt.Fprintln(w, "  label      set labels and annotations, and list resources by label selectors")
+	fmt.Fprintln(w, "\nRun 'tks-info-admin <command> -h' for the flags of a command.")
+	fmt.Fprintf(w, "Flags are also read from %s_* environment variables like the server.\n", envPrefix)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/openinfradev/tks-info/pkg/search"
+)
+
+func runSearch(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	q := search.Query{}
+	var kinds, statuses string
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&q.Match, "match", search.MatchSubstring, "how the text is matched: substring or prefix")
+	fs.StringVar(&kinds, "kinds", "", "comma separated kinds of resources to search: cluster and app_group, all kinds if empty")
+	fs.StringVar(&q.ContractID, "contract-id", "", "id of the contract, all contracts if empty")
+	fs.StringVar(&statuses, "statuses", "", "comma separated statuses such as RUNNING,ERROR")
+	fs.StringVar(&q.Creator, "creator", "", "id of the user who created the resources")
+	fs.StringVar(&q.LabelSelector, "selector", "", "label selector such as \"env=prod,!deprecated\"")
+	fs.StringVar(&q.SortBy, "sort", search.SortCreatedAt, "field to sort by: created_at or updated_at")
+	fs.BoolVar(&q.Descending, "desc", false, "sort in descending order")
+	fs.IntVar(&q.Offset, "offset", 0, "number of resources of each kind to skip")
+	fs.IntVar(&q.Limit, "limit", 100, "maximum number of resources of each kind, up to 1000")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin search [flags] [text]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments %v", fs.Args()[1:])
+	}
+	q.Text = fs.Arg(0)
+	q.Kinds = splitList(kinds)
+	q.Statuses = splitList(statuses)
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	result, err := search.New(db).Search(q)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tID\tNAME\tSTATUS\tCONTRACT\tCLUSTER\tDESCRIPTION")
+	for _, c := range result.Clusters {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", search.KindCluster, c.GetId(), c.GetName(), c.GetStatus(),
+			c.GetContractId(), "", c.GetDescription())
+	}
+	for _, g := range result.AppGroups {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", search.KindAppGroup, g.GetAppGroupId(), g.GetAppGroupName(), g.GetStatus(),
+			"", g.GetClusterId(), g.GetDescription())
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "%d of %d clusters, %d of %d app groups\n",
+		len(result.Clusters), result.TotalClusters, len(result.AppGroups), result.TotalAppGroups)
+	return nil
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-info/pkg/webhook"
+	"github.com/openinfradev/tks-info/pkg/webhook/model"
+)
+
+func runWebhook(args []string, stdout io.Writer, stderr io.Writer) error {
+	if len(args) == 0 {
+		printWebhookUsage(stderr)
+		return errors.New("a webhook command is required")
+	}
+	switch args[0] {
+	case "add":
+		return runWebhookAdd(args[1:], stdout, stderr)
+	case "list":
+		return runWebhookList(args[1:], stdout, stderr)
+	case "delete":
+		return runWebhookDelete(args[1:], stdout, stderr)
+	case "dead-letters":
+		return runWebhookDeadLetters(args[1:], stdout, stderr)
+	case "redeliver":
+		return runWebhookRedeliver(args[1:], stdout, stderr)
+	}
+	printWebhookUsage(stderr)
+	return fmt.Errorf("unknown webhook command %q", args[0])
+}
+
+func runWebhookAdd(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	s := model.Subscription{}
+	var by string
+	fs := flag.NewFlagSet("webhook add", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	registerBy(fs, &by)
+	fs.StringVar(&s.ContractID, "contract-id", "", "id of the contract whose resources are notified of")
+	fs.StringVar(&s.URL, "url", "", "URL of the webhook")
+	fs.StringVar(&s.Secret, "secret", "", "key of the HMAC signatures of payloads, generated if empty")
+	fs.StringVar(&s.ResourceTypes, "resource-types", "", "comma separated resource types to notify of: cluster, app_group and app_serve_app, all types if empty")
+	fs.StringVar(&s.Statuses, "statuses", "", "comma separated statuses to notify of, such as RUNNING,ERROR,DEPLOY_FAILED, all statuses if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %v", fs.Args())
+	}
+	if !helper.ValidateContractId(s.ContractID) {
+		return fmt.Errorf("invalid contract ID %q", s.ContractID)
+	}
+	generated := s.Secret == ""
+	if generated {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		s.Secret = hex.EncodeToString(secret)
+	}
+	if err := webhook.ValidateSubscription(s); err != nil {
+		return err
+	}
+	caller, err := callerOf(by)
+	if err != nil {
+		return err
+	}
+
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := webhook.New(tx).CreateSubscription(&s); err != nil {
+			return err
+		}
+		// The secret is left out of the audit log.
+		return recordAudit(tx, caller, "webhook add", s.ContractID, []string{s.ID.String()}, map[string]interface{}{
+			"contract_id": s.ContractID, "url": s.URL, "resource_types": s.ResourceTypes, "statuses": s.Statuses,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Subscription:", s.ID)
+	if generated {
+		fmt.Fprintln(stdout, "Secret:", s.Secret)
+	}
+	return nil
+}
+
+func runWebhookList(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var contractId string
+	fs := flag.NewFlagSet("webhook list", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&contractId, "contract-id", "", "id of the contract, all contracts if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	subscriptions, err := webhook.New(db).GetSubscriptions(contractId)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCONTRACT\tURL\tRESOURCE TYPES\tSTATUSES")
+	for _, s := range subscriptions {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.ID, s.ContractID, s.URL, orAll(s.ResourceTypes), orAll(s.Statuses))
+	}
+	return tw.Flush()
+}
+
+func runWebhookDelete(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var by string
+	fs := flag.NewFlagSet("webhook delete", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	registerBy(fs, &by)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin webhook delete [flags] <subscription id>")
+		fs.PrintDefaults()
+	}
+	id, err := parseIDArg(fs, args)
+	if err != nil {
+		return err
+	}
+	caller, err := callerOf(by)
+	if err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		accessor := webhook.New(tx)
+		subscription, err := accessor.GetSubscription(id)
+		if err != nil {
+			return err
+		}
+		if err := accessor.DeleteSubscription(id); err != nil {
+			return err
+		}
+		return recordAudit(tx, caller, "webhook delete", subscription.ContractID, []string{id.String()},
+			map[string]interface{}{"subscription_id": id.String()})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Deleted subscription", id)
+	return nil
+}
+
+func runWebhookDeadLetters(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var contractId string
+	fs := flag.NewFlagSet("webhook dead-letters", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	fs.StringVar(&contractId, "contract-id", "", "id of the contract, all contracts if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	deadLetters, err := webhook.New(db).GetDeadLetters(contractId)
+	if err != nil {
+		return err
+	}
+	tw := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tCONTRACT\tURL\tATTEMPTS\tCREATED\tLAST ERROR")
+	for _, d := range deadLetters {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\n", d.ID, d.ContractID, d.URL, d.Attempts, d.CreatedAt.Format(time.RFC3339), d.LastError)
+	}
+	return tw.Flush()
+}
+
+func runWebhookRedeliver(args []string, stdout io.Writer, stderr io.Writer) error {
+	opts := &options{}
+	var by string
+	fs := flag.NewFlagSet("webhook redeliver", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	opts.register(fs)
+	registerBy(fs, &by)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: tks-info-admin webhook redeliver [flags] <dead letter id>")
+		fs.PrintDefaults()
+	}
+	id, err := parseIDArg(fs, args)
+	if err != nil {
+		return err
+	}
+	caller, err := callerOf(by)
+	if err != nil {
+		return err
+	}
+	db, err := opts.load(fs)
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		accessor := webhook.New(tx)
+		deadLetter, err := accessor.GetDeadLetter(id)
+		if err != nil {
+			return err
+		}
+		if err := accessor.Redeliver(id, time.Now()); err != nil {
+			return err
+		}
+		return recordAudit(tx, caller, "webhook redeliver", deadLetter.ContractID, []string{id.String(), deadLetter.SubscriptionID.String()},
+			map[string]interface{}{"dead_letter_id": id.String()})
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, "Queued dead letter", id, "to be sent again")
+	return nil
+}
+
+// parseIDArg parses the flags followed by an id.
+func parseIDArg(fs *flag.FlagSet, args []string) (uuid.UUID, error) {
+	if err := fs.Parse(args); err != nil {
+		return uuid.Nil, err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return uuid.Nil, errors.New("an id is required")
+	}
+	return uuid.Parse(fs.Arg(0))
+}
+
+func orAll(filter string) string {
+	if filter == "" {
+		return "*"
+	}
+	return filter
+}
+
+func printWebhookUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tks-info-admin webhook <command> [flags]")
+	fmt.Fprintln(w, "\nCommands:")
+	fmt.Fprintln(w, "  add           subscribe a webhook to status updates of a contract")
+	fmt.Fprintln(w, "  list          list subscriptions")
+	fmt.Fprintln(w, "  delete        delete a subscription")
+	fmt.Fprintln(w, "  dead-letters  list notifications which failed to be sent")
+	fmt.Fprintln(w, "  redeliver     send a dead letter again")
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"google.golang.org/grpc"
+
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// command calls an RPC. Flags of the command are the scalar fields of the request.
+type command struct {
+	group   string
+	name    string
+	service grpc.ServiceDesc
+	method  string
+	// arg is the request field which is given as the argument of the command, if any.
+	arg     string
+	summary string
+}
+
+func (c command) fullMethod() string {
+	return "/" + c.service.ServiceName + "/" + c.method
+}
+
+// commands has a command for every RPC of tks-info.
+var commands = []command{
+	{"clusters", "list", pb.ClusterInfoService_ServiceDesc, "GetClusters", "", "list clusters of a contract or a CSP"},
+	{"clusters", "get", pb.ClusterInfoService_ServiceDesc, "GetCluster", "cluster_id", "get a cluster"},
+	{"clusters", "add", pb.ClusterInfoService_ServiceDesc, "AddClusterInfo", "", "add a cluster"},
+	{"clusters", "update-conf", pb.ClusterInfoService_ServiceDesc, "UpdateClusterConf", "cluster_id", "update the configuration of a cluster"},
+	{"clusters", "status", pb.ClusterInfoService_ServiceDesc, "UpdateClusterStatus", "cluster_id", "update the status of a cluster"},
+
+	{"csp", "create", pb.CspInfoService_ServiceDesc, "CreateCSPInfo", "", "create a CSP info"},
+	{"csp", "get", pb.CspInfoService_ServiceDesc, "GetCSPInfo", "id", "get a CSP info"},
+	{"csp", "ids", pb.CspInfoService_ServiceDesc, "GetCSPIDs", "", "list ids of all CSP infos"},
+	{"csp", "list", pb.CspInfoService_ServiceDesc, "GetCSPIDsByContractID", "id", "list ids of CSP infos of a contract"},
+	{"csp", "auth", pb.CspInfoService_ServiceDesc, "GetCSPAuth", "id", "get the auth of a CSP info"},
+	{"csp", "update-auth", pb.CspInfoService_ServiceDesc, "UpdateCSPAuth", "csp_id", "update the auth of a CSP info"},
+
+	{"appgroups", "list", pb.AppInfoService_ServiceDesc, "GetAppGroups", "", "list app groups by name and type"},
+	{"appgroups", "list-by-cluster", pb.AppInfoService_ServiceDesc, "GetAppGroupsByClusterID", "id", "list app groups of a cluster"},
+	{"appgroups", "get", pb.AppInfoService_ServiceDesc, "GetAppGroup", "app_group_id", "get an app group"},
+	{"appgroups", "create", pb.AppInfoService_ServiceDesc, "CreateAppGroup", "", "create an app group"},
+	{"appgroups", "status", pb.AppInfoService_ServiceDesc, "UpdateAppGroupStatus", "app_group_id", "update the status of an app group"},
+	{"appgroups", "delete", pb.AppInfoService_ServiceDesc, "DeleteAppGroup", "app_group_id", "delete an app group"},
+
+	{"apps", "list", pb.AppInfoService_ServiceDesc, "GetApps", "app_group_id", "list apps of a type in an app group"},
+	{"apps", "list-all", pb.AppInfoService_ServiceDesc, "GetAppsByAppGroupID", "id", "list all apps in an app group"},
+	{"apps", "update", pb.AppInfoService_ServiceDesc, "UpdateApp", "app_group_id", "update or create an app in an app group"},
+
+	{"keycloak", "get", pb.KeycloakInfoService_ServiceDesc, "GetKeycloakInfoByClusterId", "id", "get keycloak infos of a cluster"},
+	{"keycloak", "create", pb.KeycloakInfoService_ServiceDesc, "CreateKeycloakInfo", "", "create a keycloak info"},
+	{"keycloak", "update", pb.KeycloakInfoService_ServiceDesc, "UpdateKeycloakInfo", "id", "update a keycloak info"},
+	{"keycloak", "delete", pb.KeycloakInfoService_ServiceDesc, "DeleteKeycloakInfo", "id", "delete a keycloak info"},
+
+	{"appserve", "list", pb.AppServeAppService_ServiceDesc, "GetAppServeApps", "", "list app serve apps of a contract"},
+	{"appserve", "get", pb.AppServeAppService_ServiceDesc, "GetAppServeApp", "app_serve_app_id", "get an app serve app with its tasks"},
+	{"appserve", "create", pb.AppServeAppService_ServiceDesc, "CreateAppServeApp", "", "create an app serve app with its first task"},
+	{"appserve", "update", pb.AppServeAppService_ServiceDesc, "UpdateAppServeApp", "app_serve_app_id", "add a task to an app serve app"},
+	{"appserve", "status", pb.AppServeAppService_ServiceDesc, "UpdateAppServeAppStatus", "app_serve_app_task_id", "update the status of an app serve app task"},
+	{"appserve", "endpoint", pb.AppServeAppService_ServiceDesc, "UpdateAppServeAppEndpoint", "app_serve_app_id", "update the endpoints of an app serve app"},
+}
+
+func findCommand(group string, name string) (command, bool) {
+	for _, c := range commands {
+		if c.group == group && c.name == name {
+			return c, true
+		}
+	}
+	return command{}, false
+}
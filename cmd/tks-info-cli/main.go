@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/openinfradev/tks-info/pkg/config"
+)
+
+const envPrefix = "TKS_INFO_CLI"
+
+// defaultConfigPath is the config file used if it exists and no other is given.
+var defaultConfigPath = filepath.Join(".tks-info", "cli.yaml")
+
+// options are the flags of every command, which can be set in the config file
+// and the environment as the flags of the server.
+type options struct {
+	configPath     string
+	server         string
+	tlsEnabled     bool
+	tlsCAPath      string
+	tlsCertPath    string
+	tlsKeyPath     string
+	tlsServerName  string
+	token          string
+	timeout        time.Duration
+	output         string
+	idempotencyKey string
+}
+
+func (o *options) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("options", flag.ContinueOnError)
+	fs.StringVar(&o.configPath, "config", "", "path of YAML or TOML config file, ~/"+defaultConfigPath+" if it exists")
+	fs.StringVar(&o.server, "server", "localhost:9111", "address of tks-info")
+	fs.BoolVar(&o.tlsEnabled, "tls-enabled", false, "connect with TLS")
+	fs.StringVar(&o.tlsCAPath, "tls-ca-path", "", "path of CA cert file to verify tks-info, system CAs if empty")
+	fs.StringVar(&o.tlsCertPath, "tls-cert-path", "", "path of client cert file for mTLS")
+	fs.StringVar(&o.tlsKeyPath, "tls-key-path", "", "path of client key file for mTLS")
+	fs.StringVar(&o.tlsServerName, "tls-server-name", "", "name to verify the cert of tks-info, the host of server if empty")
+	fs.StringVar(&o.token, "token", "", "JWT sent as bearer token")
+	fs.DurationVar(&o.timeout, "timeout", 30*time.Second, "timeout of the call")
+	fs.StringVar(&o.output, "o", outputTable, "output format: table, json or yaml")
+	fs.StringVar(&o.idempotencyKey, "idempotency-key", "", "idempotency key of create commands, to be retried safely")
+	return fs
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout io.Writer, stderr io.Writer) int {
+	if len(args) < 2 {
+		printUsage(stderr)
+		return 2
+	}
+	cmd, ok := findCommand(args[0], args[1])
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0]+" "+args[1])
+		printUsage(stderr)
+		return 2
+	}
+
+	err := runCommand(cmd, args[2:], stdout, stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	} else if err != nil {
+		if s, ok := status.FromError(err); ok {
+			fmt.Fprintf(stderr, "Error: %s (%s)\n", s.Message(), s.Code())
+		} else {
+			fmt.Fprintln(stderr, "Error:", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+func runCommand(cmd command, args []string, stdout io.Writer, stderr io.Writer) error {
+	md, err := methodDescriptor(cmd)
+	if err != nil {
+		return err
+	}
+
+	opts := &options{}
+	optionFlags := opts.flagSet()
+	fs := flag.NewFlagSet(cmd.group+" "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	optionFlags.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+	req := newRequest(fs, md.Input())
+	fs.Usage = func() {
+		argument := ""
+		if cmd.arg != "" {
+			argument = " [" + cmd.arg + "]"
+		}
+		fmt.Fprintf(stderr, "Usage: tks-info-cli %s %s [flags]%s\n\n%s by %s.\n\nFlags:\n",
+			cmd.group, cmd.name, argument, cmd.summary, cmd.method)
+		fs.PrintDefaults()
+	}
+
+	positional, err := parseInterleaved(fs, args)
+	if err != nil {
+		return err
+	}
+	arg := ""
+	switch {
+	case len(positional) == 1 && cmd.arg != "":
+		arg = positional[0]
+	case len(positional) > 0:
+		fs.Usage()
+		return fmt.Errorf("unexpected arguments %v", positional)
+	}
+
+	// Options set on the command line take precedence over the environment and the config file.
+	fs.Visit(func(f *flag.Flag) {
+		if optionFlags.Lookup(f.Name) != nil {
+			_ = optionFlags.Set(f.Name, f.Value.String())
+		}
+	})
+	if err := config.Load(optionFlags, opts.configFile(), envPrefix); err != nil {
+		return err
+	}
+	if opts.output != outputTable && opts.output != outputJSON && opts.output != outputYAML {
+		return fmt.Errorf("unknown output format %q", opts.output)
+	}
+
+	in, err := req.build(cmd.arg, arg)
+	if err != nil {
+		return err
+	}
+	out, err := call(opts, cmd.fullMethod(), md.Output(), in)
+	if err != nil {
+		return err
+	}
+	return printResponse(stdout, opts.output, out)
+}
+
+// configFile returns the path of the config file, which is empty if there is none.
+func (o *options) configFile() string {
+	if o.configPath != "" {
+		return o.configPath
+	}
+	if path := os.Getenv(config.EnvName(envPrefix, "config")); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if _, err := os.Stat(filepath.Join(home, defaultConfigPath)); err != nil {
+		return ""
+	}
+	return filepath.Join(home, defaultConfigPath)
+}
+
+// parseInterleaved parses flags given before and after the arguments, and returns the arguments.
+func parseInterleaved(fs *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+func methodDescriptor(cmd command) (protoreflect.MethodDescriptor, error) {
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(cmd.service.ServiceName))
+	if err != nil {
+		return nil, err
+	}
+	md := d.(protoreflect.ServiceDescriptor).Methods().ByName(protoreflect.Name(cmd.method))
+	if md == nil {
+		return nil, fmt.Errorf("unknown RPC %s", cmd.fullMethod())
+	}
+	return md, nil
+}
+
+func call(opts *options, method string, output protoreflect.MessageDescriptor, in proto.Message) (proto.Message, error) {
+	creds, err := opts.transportCredentials()
+	if err != nil {
+		return nil, err
+	}
+	cc, err := grpc.Dial(opts.server, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	defer cc.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.timeout)
+	defer cancel()
+	if opts.token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+opts.token)
+	}
+	if opts.idempotencyKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "idempotency-key", opts.idempotencyKey)
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(output.FullName())
+	if err != nil {
+		return nil, err
+	}
+	out := mt.New().Interface()
+	if err := cc.Invoke(ctx, method, in, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (o *options) transportCredentials() (credentials.TransportCredentials, error) {
+	if !o.tlsEnabled {
+		return insecure.NewCredentials(), nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: o.tlsServerName,
+	}
+	if o.tlsCAPath != "" {
+		pem, err := os.ReadFile(o.tlsCAPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate in %s", o.tlsCAPath)
+		}
+	}
+	if o.tlsCertPath != "" || o.tlsKeyPath != "" {
+		cert, err := tls.LoadX509KeyPair(o.tlsCertPath, o.tlsKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: tks-info-cli <group> <command> [flags] [argument]")
+	fmt.Fprintln(w, "\nCommands:")
+
+	groups := map[string][]command{}
+	names := []string{}
+	for _, c := range commands {
+		if _, ok := groups[c.group]; !ok {
+			names = append(names, c.group)
+		}
+		groups[c.group] = append(groups[c.group], c)
+	}
+	sort.Strings(names)
+	for _, group := range names {
+		fmt.Fprintf(w, "  %s\n", group)
+		for _, c := range groups[group] {
+			fmt.Fprintf(w, "    %-16s %s\n", c.name, c.summary)
+		}
+	}
+	fmt.Fprintln(w, "\nRun 'tks-info-cli <group> <command> -h' for the flags of a command.")
+	fmt.Fprintf(w, "Options are also read from %s_* environment variables and the config file.\n", envPrefix)
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+func TestCommandsCoverRPCs(t *testing.T) {
+	services := []grpc.ServiceDesc{
+		pb.ClusterInfoService_ServiceDesc,
+		pb.CspInfoService_ServiceDesc,
+		pb.AppInfoService_ServiceDesc,
+		pb.KeycloakInfoService_ServiceDesc,
+		pb.AppServeAppService_ServiceDesc,
+	}
+	methods := map[string]int{}
+	for _, c := range commands {
+		methods[c.fullMethod()]++
+	}
+	for _, s := range services {
+		for _, m := range s.Methods {
+			name := "/" + s.ServiceName + "/" + m.MethodName
+			require.Equal(t, 1, methods[name], "commands of %s", name)
+			delete(methods, name)
+		}
+	}
+	require.Empty(t, methods, "commands of unknown RPCs")
+
+	names := map[string]bool{}
+	for _, c := range commands {
+		require.False(t, names[c.group+" "+c.name], "duplicate command %s %s", c.group, c.name)
+		names[c.group+" "+c.name] = true
+
+		md, err := methodDescriptor(c)
+		require.NoError(t, err)
+		if c.arg != "" {
+			fd := md.Input().Fields().ByName(protoreflect.Name(c.arg))
+			require.NotNil(t, fd, "argument %s of %s %s", c.arg, c.group, c.name)
+			require.Equal(t, protoreflect.StringKind, fd.Kind())
+		}
+	}
+}
+
+func TestRequestFlags(t *testing.T) {
+	for _, c := range commands {
+		md, err := methodDescriptor(c)
+		require.NoError(t, err)
+
+		fs := flag.NewFlagSet(c.name, flag.ContinueOnError)
+		(&options{}).flagSet().VisitAll(func(f *flag.Flag) {
+			fs.Var(f.Value, f.Name, f.Usage)
+		})
+		req := newRequest(fs, md.Input())
+		// Every field has a flag, which is not taken by the options.
+		require.Len(t, req.fields, len(leafFields(md.Input(), nil, map[protoreflect.FullName]bool{})),
+			"flags of %s %s", c.group, c.name)
+	}
+}
+
+func newTestRequest(t *testing.T, msg proto.Message, args ...string) proto.Message {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	req := newRequest(fs, msg.ProtoReflect().Descriptor())
+	positional, err := parseInterleaved(fs, args)
+	require.NoError(t, err)
+
+	arg := ""
+	if len(positional) > 0 {
+		arg = positional[0]
+	}
+	in, err := req.build("cluster_id", arg)
+	require.NoError(t, err)
+	return in
+}
+
+func TestBuildRequest(t *testing.T) {
+	in := newTestRequest(t, &pb.UpdateClusterStatusRequest{},
+		"C1234", "-status", "running", "-status-desc", "done")
+	require.True(t, proto.Equal(&pb.UpdateClusterStatusRequest{
+		ClusterId:  "C1234",
+		Status:     pb.ClusterStatus_RUNNING,
+		StatusDesc: "done",
+	}, in), in)
+
+	in = newTestRequest(t, &pb.AddClusterInfoRequest{},
+		"-data", `{"name":"prod","conf":{"region":"ap-northeast-2","numOfAz":3}}`, "-num-of-az", "2", "-contract-id", "P1234")
+	require.True(t, proto.Equal(&pb.AddClusterInfoRequest{
+		ContractId: "P1234",
+		Name:       "prod",
+		Conf:       &pb.ClusterConf{Region: "ap-northeast-2", NumOfAz: 2},
+	}, in), in)
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	newRequest(fs, (&pb.UpdateClusterStatusRequest{}).ProtoReflect().Descriptor())
+	require.Error(t, fs.Parse([]string{"-status", "unknown"}))
+}
+
+func TestPrintResponse(t *testing.T) {
+	res := &pb.GetClustersResponse{
+		Clusters: []*pb.Cluster{
+			{Id: "C1234", Name: "prod", Status: pb.ClusterStatus_RUNNING, CreatedAt: timestamppb.Now()},
+			{Id: "C5678", Name: "dev", StatusDesc: strings.Repeat("x", 100)},
+		},
+	}
+	var b bytes.Buffer
+	require.NoError(t, printResponse(&b, outputTable, res))
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	require.Len(t, lines, 3)
+	require.Contains(t, lines[0], "ID")
+	require.Contains(t, lines[0], "CREATED_AT")
+	require.Contains(t, lines[1], "RUNNING")
+	require.Contains(t, lines[2], strings.Repeat("x", maxCellLength-3)+"...")
+
+	b.Reset()
+	require.NoError(t, printResponse(&b, outputYAML, res))
+	require.Contains(t, b.String(), "clusters:\n  - id: C1234\n")
+
+	b.Reset()
+	require.NoError(t, printResponse(&b, outputTable, &pb.IDsResponse{Ids: []string{"A1", "B2"}}))
+	require.Equal(t, []string{"IDS", "A1", "B2"}, strings.Fields(b.String()))
+
+	b.Reset()
+	require.NoError(t, printResponse(&b, outputTable, &pb.SimpleResponse{}))
+	require.Equal(t, "OK\n", b.String())
+}
+
+type clusterInfoServer struct {
+	pb.UnimplementedClusterInfoServiceServer
+	md metadata.MD
+}
+
+func (s *clusterInfoServer) GetCluster(ctx context.Context, in *pb.GetClusterRequest) (*pb.GetClusterResponse, error) {
+	s.md, _ = metadata.FromIncomingContext(ctx)
+	return &pb.GetClusterResponse{Cluster: &pb.Cluster{Id: in.GetClusterId(), Name: "prod"}}, nil
+}
+
+func TestRun(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	s := grpc.NewServer()
+	server := &clusterInfoServer{}
+	pb.RegisterClusterInfoServiceServer(s, server)
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+
+	t.Setenv("TKS_INFO_CLI_SERVER", lis.Addr().String())
+	t.Setenv("TKS_INFO_CLI_O", "yaml")
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"clusters", "get", "C1234", "-token", "secret", "-o", "json"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	require.Contains(t, stdout.String(), `"id": "C1234"`)
+	require.Equal(t, []string{"Bearer secret"}, server.md.Get("authorization"))
+
+	stdout.Reset()
+	code = run([]string{"clusters", "get", "C1234"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	require.Contains(t, stdout.String(), "id: C1234")
+
+	code = run([]string{"clusters", "add", "-name", "prod"}, &stdout, &stderr)
+	require.Equal(t, 1, code)
+	require.Contains(t, stderr.String(), "Unimplemented")
+
+	require.Equal(t, 2, run([]string{"clusters", "unknown"}, &stdout, &stderr))
+	require.Equal(t, 0, run([]string{"clusters", "get", "-h"}, &stdout, &stderr))
+}
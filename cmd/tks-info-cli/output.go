@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// maxCellLength is the maximum length of a table cell. Use JSON or YAML for whole values.
+const maxCellLength = 40
+
+func printResponse(w io.Writer, format string, res proto.Message) error {
+	switch format {
+	case outputJSON:
+		b, err := marshalJSON(res)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(b))
+		return err
+	case outputYAML:
+		b, err := marshalJSON(res)
+		if err != nil {
+			return err
+		}
+		// Decoding JSON to a node keeps the order of fields.
+		var node yaml.Node
+		if err := yaml.Unmarshal(b, &node); err != nil {
+			return err
+		}
+		blockStyle(&node)
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return err
+		}
+		return enc.Close()
+	case outputTable:
+		return printTable(w, res.ProtoReflect())
+	}
+	return fmt.Errorf("unknown output format %q", format)
+}
+
+// marshalJSON returns indented JSON of res. protojson varies its whitespace on purpose,
+// so the JSON is indented again to be stable.
+func marshalJSON(res proto.Message) ([]byte, error) {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(res)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, b, "", "  "); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// blockStyle clears the flow style and the quotes of JSON from node, so that it is written as block YAML.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		blockStyle(n)
+	}
+}
+
+// printTable prints the payload of the response, which is the field other than code and error,
+// as rows of its scalar fields.
+func printTable(w io.Writer, res protoreflect.Message) error {
+	var payload []protoreflect.FieldDescriptor
+	fields := res.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if name := fields.Get(i).Name(); name != "code" && name != "error" {
+			payload = append(payload, fields.Get(i))
+		}
+	}
+
+	var columns []protoreflect.FieldDescriptor
+	var rows []protoreflect.Message
+	switch {
+	case len(payload) == 0:
+		_, err := fmt.Fprintln(w, "OK")
+		return err
+	case len(payload) == 1 && payload[0].IsList() && payload[0].Kind() == protoreflect.MessageKind:
+		columns = scalarFields(payload[0].Message())
+		list := res.Get(payload[0]).List()
+		for i := 0; i < list.Len(); i++ {
+			rows = append(rows, list.Get(i).Message())
+		}
+	case len(payload) == 1 && payload[0].IsList():
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, header(payload[0]))
+		list := res.Get(payload[0]).List()
+		for i := 0; i < list.Len(); i++ {
+			fmt.Fprintln(tw, cell(payload[0], list.Get(i)))
+		}
+		return tw.Flush()
+	case len(payload) == 1 && payload[0].Kind() == protoreflect.MessageKind:
+		columns = scalarFields(payload[0].Message())
+		if res.Has(payload[0]) {
+			rows = append(rows, res.Get(payload[0]).Message())
+		}
+	default:
+		for _, fd := range payload {
+			if isScalar(fd) {
+				columns = append(columns, fd)
+			}
+		}
+		rows = append(rows, res)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	headers := make([]string, 0, len(columns))
+	for _, fd := range columns {
+		headers = append(headers, header(fd))
+	}
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+	for _, row := range rows {
+		cells := make([]string, 0, len(columns))
+		for _, fd := range columns {
+			cells = append(cells, cell(fd, row.Get(fd)))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func isScalar(fd protoreflect.FieldDescriptor) bool {
+	if fd.IsList() || fd.IsMap() {
+		return false
+	}
+	return fd.Kind() != protoreflect.MessageKind || fd.Message().FullName() == "google.protobuf.Timestamp"
+}
+
+// scalarFields returns the fields of md shown in tables.
+func scalarFields(md protoreflect.MessageDescriptor) []protoreflect.FieldDescriptor {
+	columns := []protoreflect.FieldDescriptor{}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		if isScalar(fields.Get(i)) {
+			columns = append(columns, fields.Get(i))
+		}
+	}
+	return columns
+}
+
+func header(fd protoreflect.FieldDescriptor) string {
+	return strings.ToUpper(string(fd.Name()))
+}
+
+func cell(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	var s string
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			s = string(ev.Name())
+		} else {
+			s = fmt.Sprint(v.Enum())
+		}
+	case protoreflect.MessageKind:
+		ts, ok := v.Message().Interface().(*timestamppb.Timestamp)
+		if !ok || ts == nil || (ts.GetSeconds() == 0 && ts.GetNanos() == 0) {
+			return ""
+		}
+		s = ts.AsTime().Local().Format(time.RFC3339)
+	default:
+		s = v.String()
+	}
+
+	// Keep rows on a line.
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) > maxCellLength {
+		s = string([]rune(s)[:maxCellLength-3]) + "..."
+	}
+	return s
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// fieldFlag sets a scalar field of the request, which may be in nested messages.
+type fieldFlag struct {
+	path  []protoreflect.FieldDescriptor
+	value string
+	set   bool
+}
+
+func (f *fieldFlag) String() string {
+	return f.value
+}
+
+func (f *fieldFlag) Set(value string) error {
+	if _, err := parseField(f.field(), value); err != nil {
+		return err
+	}
+	f.value = value
+	f.set = true
+	return nil
+}
+
+func (f *fieldFlag) field() protoreflect.FieldDescriptor {
+	return f.path[len(f.path)-1]
+}
+
+// apply sets the field of msg, creating the nested messages on the path.
+func (f *fieldFlag) apply(msg protoreflect.Message) error {
+	for _, fd := range f.path[:len(f.path)-1] {
+		msg = msg.Mutable(fd).Message()
+	}
+	v, err := parseField(f.field(), f.value)
+	if err != nil {
+		return err
+	}
+	msg.Set(f.field(), v)
+	return nil
+}
+
+func parseField(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(s), nil
+	case protoreflect.BoolKind:
+		b, err := strconv.ParseBool(s)
+		return protoreflect.ValueOfBool(b), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		n, err := strconv.ParseInt(s, 10, 32)
+		return protoreflect.ValueOfInt32(int32(n)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		n, err := strconv.ParseInt(s, 10, 64)
+		return protoreflect.ValueOfInt64(n), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		n, err := strconv.ParseUint(s, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(n)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		n, err := strconv.ParseUint(s, 10, 64)
+		return protoreflect.ValueOfUint64(n), err
+	case protoreflect.FloatKind:
+		n, err := strconv.ParseFloat(s, 32)
+		return protoreflect.ValueOfFloat32(float32(n)), err
+	case protoreflect.DoubleKind:
+		n, err := strconv.ParseFloat(s, 64)
+		return protoreflect.ValueOfFloat64(n), err
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		if v := values.ByName(protoreflect.Name(strings.ToUpper(s))); v != nil {
+			return protoreflect.ValueOfEnum(v.Number()), nil
+		}
+		if n, err := strconv.ParseInt(s, 10, 32); err == nil && values.ByNumber(protoreflect.EnumNumber(n)) != nil {
+			return protoreflect.ValueOfEnum(protoreflect.EnumNumber(n)), nil
+		}
+		return protoreflect.Value{}, fmt.Errorf("must be one of %s", strings.Join(enumNames(fd.Enum()), ", "))
+	}
+	return protoreflect.Value{}, fmt.Errorf("%s fields can't be set by flags", fd.Kind())
+}
+
+func enumNames(ed protoreflect.EnumDescriptor) []string {
+	values := ed.Values()
+	names := make([]string, 0, values.Len())
+	for i := 0; i < values.Len(); i++ {
+		names = append(names, string(values.Get(i).Name()))
+	}
+	return names
+}
+
+// flagName returns the flag of a field name, such as "cluster-id" for "cluster_id".
+func flagName(names ...string) string {
+	return strings.ReplaceAll(strings.Join(names, "-"), "_", "-")
+}
+
+// leafFields returns the paths of the scalar fields of md and its nested messages.
+func leafFields(md protoreflect.MessageDescriptor, path []protoreflect.FieldDescriptor, seen map[protoreflect.FullName]bool) [][]protoreflect.FieldDescriptor {
+	if seen[md.FullName()] {
+		return nil
+	}
+	seen[md.FullName()] = true
+	defer delete(seen, md.FullName())
+
+	leaves := [][]protoreflect.FieldDescriptor{}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		p := append(append([]protoreflect.FieldDescriptor{}, path...), fd)
+		switch {
+		case fd.IsList() || fd.IsMap():
+		case fd.Kind() == protoreflect.MessageKind:
+			if fd.Message().FullName().Parent() == "google.protobuf" {
+				continue
+			}
+			leaves = append(leaves, leafFields(fd.Message(), p, seen)...)
+		default:
+			leaves = append(leaves, p)
+		}
+	}
+	return leaves
+}
+
+// request builds a request of the command from its flags.
+type request struct {
+	desc   protoreflect.MessageDescriptor
+	fields []*fieldFlag
+	data   string
+	file   string
+}
+
+// newRequest defines the flags of the request fields on fs. Fields of nested messages are named
+// after the field alone, such as "region" for conf.region, unless the name is taken by another field,
+// in which case the names on the path are joined, such as "app-group-cluster-id".
+func newRequest(fs *flag.FlagSet, md protoreflect.MessageDescriptor) *request {
+	r := &request{desc: md}
+	fs.StringVar(&r.data, "data", "", "request as JSON, which flags of fields override")
+	fs.StringVar(&r.file, "f", "", "path of file with request as JSON, or - for stdin")
+
+	leaves := leafFields(md, nil, map[protoreflect.FullName]bool{})
+	names := make([]string, len(leaves))
+	for i, p := range leaves {
+		names[i] = flagName(string(p[len(p)-1].Name()))
+	}
+	// Joined names may be taken as well, such as app_serve_app.id by app_serve_app_task.app_serve_app_id.
+	for changed := true; changed; {
+		changed = false
+		count := map[string]int{}
+		for _, name := range names {
+			count[name]++
+		}
+		for i, p := range leaves {
+			if len(p) > 1 && count[names[i]] > 1 && names[i] != pathName(p) {
+				names[i] = pathName(p)
+				changed = true
+			}
+		}
+	}
+	for i, p := range leaves {
+		fd := p[len(p)-1]
+		name := names[i]
+		if fs.Lookup(name) != nil {
+			continue
+		}
+		f := &fieldFlag{path: p}
+		r.fields = append(r.fields, f)
+		fs.Var(f, name, usage(fd))
+	}
+	return r
+}
+
+// pathName returns the flag of the field on the path, such as "conf-region".
+func pathName(path []protoreflect.FieldDescriptor) string {
+	names := make([]string, 0, len(path))
+	for _, fd := range path {
+		names = append(names, string(fd.Name()))
+	}
+	return flagName(names...)
+}
+
+func usage(fd protoreflect.FieldDescriptor) string {
+	if fd.Kind() == protoreflect.EnumKind {
+		return "one of " + strings.Join(enumNames(fd.Enum()), ", ")
+	}
+	return fd.Kind().String()
+}
+
+// build returns the request from -f, -data and the field flags in this order.
+// arg is the field set by the argument of the command.
+func (r *request) build(argField string, arg string) (proto.Message, error) {
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(r.desc.FullName())
+	if err != nil {
+		return nil, err
+	}
+	msg := mt.New().Interface()
+
+	if r.file != "" {
+		var b []byte
+		if r.file == "-" {
+			b, err = io.ReadAll(os.Stdin)
+		} else {
+			b, err = os.ReadFile(r.file)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := protojson.Unmarshal(b, msg); err != nil {
+			return nil, fmt.Errorf("invalid request in %s: %w", r.file, err)
+		}
+	}
+	if r.data != "" {
+		data := mt.New().Interface()
+		if err := protojson.Unmarshal([]byte(r.data), data); err != nil {
+			return nil, fmt.Errorf("invalid request in -data: %w", err)
+		}
+		proto.Merge(msg, data)
+	}
+
+	if argField != "" && arg != "" {
+		fd := r.desc.Fields().ByName(protoreflect.Name(argField))
+		msg.ProtoReflect().Set(fd, protoreflect.ValueOfString(arg))
+	}
+	for _, f := range r.fields {
+		if !f.set {
+			continue
+		}
+		if err := f.apply(msg.ProtoReflect()); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
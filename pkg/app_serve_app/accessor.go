@@ -1,10 +1,13 @@
 package app_serve_app
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/openinfradev/tks-info/pkg/app_serve_app/model"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+	"github.com/openinfradev/tks-info/pkg/label"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/gorm"
@@ -22,8 +25,26 @@ func New(db *gorm.DB) *AsaAccessor {
 	}
 }
 
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *AsaAccessor) WithContext(ctx context.Context) *AsaAccessor {
+	return &AsaAccessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
 // Create creates a new appServeApp in database.
+// The target cluster, if any, must exist and belong to the same contract.
 func (x *AsaAccessor) Create(contractId string, app *pb.AppServeApp, task *pb.AppServeAppTask) (uuid.UUID, uuid.UUID, error) {
+	if targetClusterId := app.GetTargetClusterId(); targetClusterId != "" {
+		cluster, err := integrity.GetCluster(x.db, targetClusterId)
+		if err != nil {
+			return uuid.Nil, uuid.Nil, err
+		}
+		if err := integrity.CheckContract("cluster", targetClusterId, contractId, cluster.ContractID); err != nil {
+			return uuid.Nil, uuid.Nil, err
+		}
+	}
+
 	// TODO: should I set initial status field here?
 	asaModel := model.AppServeApp{
 		Name:               app.GetName(),
@@ -114,6 +135,69 @@ func (x *AsaAccessor) GetAppServeApps(contractId string, showAll bool) ([]*pb.Ap
 	return pbAppServeApps, nil
 }
 
+// GetAppServeAppsBySelector returns AppServeApps of a contract whose labels match the selector.
+// Deleted apps are left out unless showAll is set.
+func (x *AsaAccessor) GetAppServeAppsBySelector(contractId string, selector label.Selector, showAll bool) ([]*pb.AppServeApp, error) {
+	var appServeApps []model.AppServeApp
+	db := selector.Where(x.db, "labels").Where("contract_id = ?", contractId)
+	if !showAll {
+		db = db.Where("status <> ?", "DELETE_SUCCESS")
+	}
+	res := db.Order("created_at desc").Find(&appServeApps)
+	if res.Error != nil {
+		return nil, fmt.Errorf("Error while finding appServeApps with contractID %s and selector %s: %w", contractId, selector, res.Error)
+	}
+
+	pbAppServeApps := []*pb.AppServeApp{}
+	for _, asa := range appServeApps {
+		pbAppServeApps = append(pbAppServeApps, ConvertToPbAppServeApp(asa))
+	}
+	return pbAppServeApps, nil
+}
+
+// UpdateLabels replaces labels and annotations of an AppServeApp. A nil map leaves them as they are,
+// and an empty map removes all of them. The update time of the app is kept, as it tracks the status.
+func (x *AsaAccessor) UpdateLabels(id uuid.UUID, labels map[string]string, annotations map[string]string) error {
+	columns, err := label.Columns(labels, annotations)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("UpdateLabels: nothing to update in AppServeApp with id %s", id)
+	}
+
+	res := x.db.Model(&model.AppServeApp{}).Where("id = ?", id).UpdateColumns(columns)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: app serve app %s", integrity.ErrNotFound, id)
+	}
+	return nil
+}
+
+// GetLabels returns labels and annotations of an AppServeApp.
+func (x *AsaAccessor) GetLabels(id uuid.UUID) (map[string]string, map[string]string, error) {
+	var appServeApp model.AppServeApp
+	res := x.db.Select("labels", "annotations").Where("id = ?", id).Limit(1).Find(&appServeApp)
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil, fmt.Errorf("%w: app serve app %s", integrity.ErrNotFound, id)
+	}
+
+	labels, err := label.Unmarshal(appServeApp.Labels)
+	if err != nil {
+		return nil, nil, err
+	}
+	annotations, err := label.Unmarshal(appServeApp.Annotations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return labels, annotations, nil
+}
+
 func (x *AsaAccessor) GetAppServeApp(id uuid.UUID) (*pb.AppServeAppCombined, error) {
 	var appServeApp model.AppServeApp
 	var appServeAppTasks []model.AppServeAppTask
@@ -137,6 +221,22 @@ func (x *AsaAccessor) GetAppServeApp(id uuid.UUID) (*pb.AppServeAppCombined, err
 	return pbAppServeAppCombined, nil
 }
 
+// GetAppServeAppByTaskId returns the AppServeApp which the task belongs to.
+func (x *AsaAccessor) GetAppServeAppByTaskId(taskId uuid.UUID) (*pb.AppServeApp, error) {
+	var appServeAppTask model.AppServeAppTask
+	res := x.db.Select("app_serve_app_id").First(&appServeAppTask, "id = ?", taskId)
+	if res.RowsAffected == 0 || res.Error != nil {
+		return nil, fmt.Errorf("Could not find AppServeAppTask with ID: %s", taskId)
+	}
+
+	var appServeApp model.AppServeApp
+	res = x.db.First(&appServeApp, "id = ?", appServeAppTask.AppServeAppId)
+	if res.RowsAffected == 0 || res.Error != nil {
+		return nil, fmt.Errorf("Could not find AppServeApp with ID: %s", appServeAppTask.AppServeAppId)
+	}
+	return ConvertToPbAppServeApp(appServeApp), nil
+}
+
 func (x *AsaAccessor) UpdateStatus(taskId uuid.UUID, status string, output string) error {
 	// Update task status
 	res := x.db.Model(&model.AppServeAppTask{}).Where("ID = ?", taskId).Updates(model.AppServeAppTask{Status: status, Output: output})
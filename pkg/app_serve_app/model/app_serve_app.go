@@ -4,6 +4,7 @@ import (
 	"time"
 
 	uuid "github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -18,8 +19,11 @@ type AppServeApp struct {
 	PreviewEndpointUrl string
 	TargetClusterId    string
 	Status             string
-	CreatedAt          time.Time
-	UpdatedAt          time.Time
+	// Labels and Annotations are JSON objects of string values, which are NULL if empty.
+	Labels      datatypes.JSON
+	Annotations datatypes.JSON
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
 }
 
 func (c *AppServeApp) BeforeCreate(tx *gorm.DB) (err error) {
@@ -1,12 +1,15 @@
 package application
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 	"github.com/openinfradev/tks-common/pkg/log"
 	"github.com/openinfradev/tks-info/pkg/application/model"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+	"github.com/openinfradev/tks-info/pkg/label"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/datatypes"
@@ -25,8 +28,20 @@ func New(db *gorm.DB) *Accessor {
 	}
 }
 
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *Accessor) WithContext(ctx context.Context) *Accessor {
+	return &Accessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
 // Create creates a new application group in database.
+// The cluster must exist.
 func (x *Accessor) Create(clusterID string, appGroup *pb.AppGroup) (string, error) {
+	if _, err := integrity.GetCluster(x.db, clusterID); err != nil {
+		return "", err
+	}
+
 	existsLabel, err := x.existsExternalLabel(clusterID, appGroup.GetExternalLabel())
 	if err != nil {
 		return "", err
@@ -72,6 +87,21 @@ func (x *Accessor) GetAppGroupsByClusterID(clusterID string, offset, limit int)
 	return reflectToPbAppGroups(appGroupModels), nil
 }
 
+// GetAppGroupsByClusterIDs returns application groups of several clusters at once.
+func (x *Accessor) GetAppGroupsByClusterIDs(clusterIDs []string) ([]*pb.AppGroup, error) {
+	if len(clusterIDs) == 0 {
+		return []*pb.AppGroup{}, nil
+	}
+
+	var appGroupModels []model.ApplicationGroup
+	res := x.db.Where("cluster_id IN ?", clusterIDs).Order("created_at").Find(&appGroupModels)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return reflectToPbAppGroups(appGroupModels), nil
+}
+
 // GetAppGroups returns application groups matching name and type in database.
 func (x *Accessor) GetAppGroups(name string, appGroupType pb.AppGroupType) ([]*pb.AppGroup, error) {
 	var (
@@ -110,7 +140,7 @@ func (x *Accessor) GetAppGroup(appGroupID string) (*pb.AppGroup, error) {
 	} else if res.Error != nil {
 		return nil, res.Error
 	}
-	return reflectToPbAppGroup(appGroupModel), nil
+	return ConvertToPbAppGroup(appGroupModel), nil
 }
 
 // UpdateAppGroupStatus updates status of application group.
@@ -128,6 +158,60 @@ func (x *Accessor) UpdateAppGroupStatus(appGroupID string, status pb.AppGroupSta
 	return nil
 }
 
+// UpdateAppGroupLabels replaces labels and annotations of an application group. A nil map leaves them as they are,
+// and an empty map removes all of them. The update time of the group is kept, as it tracks the status.
+func (x *Accessor) UpdateAppGroupLabels(appGroupID string, labels map[string]string, annotations map[string]string) error {
+	columns, err := label.Columns(labels, annotations)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("nothing to update in application group %s", appGroupID)
+	}
+
+	res := x.db.Model(&model.ApplicationGroup{}).Where("id = ?", appGroupID).UpdateColumns(columns)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: app group %s", integrity.ErrNotFound, appGroupID)
+	}
+	return nil
+}
+
+// GetAppGroupLabels returns labels and annotations of an application group.
+func (x *Accessor) GetAppGroupLabels(appGroupID string) (map[string]string, map[string]string, error) {
+	var appGroupModel model.ApplicationGroup
+	res := x.db.Select("labels", "annotations").Where("id = ?", appGroupID).Limit(1).Find(&appGroupModel)
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil, fmt.Errorf("%w: app group %s", integrity.ErrNotFound, appGroupID)
+	}
+
+	labels, err := label.Unmarshal(appGroupModel.Labels)
+	if err != nil {
+		return nil, nil, err
+	}
+	annotations, err := label.Unmarshal(appGroupModel.Annotations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return labels, annotations, nil
+}
+
+// GetAppGroupsBySelector returns application groups of a cluster whose labels match the selector.
+func (x *Accessor) GetAppGroupsBySelector(clusterID string, selector label.Selector) ([]*pb.AppGroup, error) {
+	var appGroupModels []model.ApplicationGroup
+	res := selector.Where(x.db, "labels").Where("cluster_id = ?", clusterID).Order("created_at").Find(&appGroupModels)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return reflectToPbAppGroups(appGroupModels), nil
+}
+
 // DeleteAppGroup deletes an application group and applications.
 func (x *Accessor) DeleteAppGroup(appGroupID string) error {
 	res := x.db.Delete(&model.ApplicationGroup{}, "id = ?", appGroupID)
@@ -153,6 +237,20 @@ func (x *Accessor) GetAppsByAppGroupID(appGroupID string) ([]*pb.Application, er
 	return reflectToPbApplications(appModels), nil
 }
 
+// GetAppsByAppGroupIDs queries applications of several application groups at once.
+func (x *Accessor) GetAppsByAppGroupIDs(appGroupIDs []string) ([]*pb.Application, error) {
+	if len(appGroupIDs) == 0 {
+		return []*pb.Application{}, nil
+	}
+
+	var appModels []model.Application
+	res := x.db.Where("app_group_id IN ?", appGroupIDs).Order("created_at").Find(&appModels)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return reflectToPbApplications(appModels), nil
+}
+
 // GetApps queies applications by app type.
 func (x *Accessor) GetApps(appGroupID string, appType pb.AppType) ([]*pb.Application, error) {
 	var appModels []model.Application
@@ -194,12 +292,12 @@ func (x *Accessor) createApplication(appGroupID string, appType pb.AppType, endp
 func reflectToPbAppGroups(models []model.ApplicationGroup) []*pb.AppGroup {
 	var result []*pb.AppGroup
 	for _, model := range models {
-		result = append(result, reflectToPbAppGroup(model))
+		result = append(result, ConvertToPbAppGroup(model))
 	}
 	return result
 }
 
-func reflectToPbAppGroup(model model.ApplicationGroup) *pb.AppGroup {
+func ConvertToPbAppGroup(model model.ApplicationGroup) *pb.AppGroup {
 	return &pb.AppGroup{
 		AppGroupId:    model.ID,
 		AppGroupName:  model.Name,
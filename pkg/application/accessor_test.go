@@ -1,6 +1,7 @@
 package application_test
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
@@ -15,6 +16,9 @@ import (
 
 	"github.com/openinfradev/tks-info/pkg/application"
 	"github.com/openinfradev/tks-info/pkg/application/model"
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+	"github.com/openinfradev/tks-info/pkg/label"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 )
 
@@ -31,8 +35,6 @@ var (
 )
 
 func init() {
-	clusterID = helper.GenerateClusterId()
-
 	log.Disable()
 }
 
@@ -53,6 +55,16 @@ func getAccessor() (*application.Accessor, error) {
 	if err := db.AutoMigrate(&model.ApplicationGroup{}); err != nil {
 		return nil, err
 	}
+	if err := db.AutoMigrate(&clusterModel.Cluster{}); err != nil {
+		return nil, err
+	}
+
+	// Application groups can only be created on an existing cluster.
+	cluster := clusterModel.Cluster{Name: "testCluster"}
+	if res := db.Create(&cluster); res.Error != nil {
+		return nil, res.Error
+	}
+	clusterID = cluster.ID
 
 	return application.New(db), nil
 }
@@ -102,6 +114,13 @@ func TestCreateApplicationGroup(t *testing.T) {
 	}
 	t.Logf("new app group id: %s, %s", appGroupID, appGroupID2)
 }
+func TestCreateApplicationGroupWithInvalidCluster(t *testing.T) {
+	_, err := accessor.Create(helper.GenerateClusterId(), &pb.AppGroup{AppGroupName: getRandomString("gotest")})
+	if !errors.Is(err, integrity.ErrNotFound) {
+		t.Errorf("an error for not existing cluster was expected, but got: %v", err)
+	}
+}
+
 func TestGetAppGroupsByClusterID(t *testing.T) {
 	appGroups, err := accessor.GetAppGroupsByClusterID(clusterID, 0, 10)
 	if err != nil {
@@ -141,6 +160,29 @@ func TestUpdateAppGroupStatus(t *testing.T) {
 	}
 }
 
+func TestUpdateAppGroupLabels(t *testing.T) {
+	if err := accessor.UpdateAppGroupLabels(appGroupID, map[string]string{"tks.io/team": "infra"}, nil); err != nil {
+		t.Errorf("an error was unexpected while update labels: %s", err)
+	}
+	labels, _, err := accessor.GetAppGroupLabels(appGroupID)
+	if err != nil {
+		t.Errorf("an error was unexpected while get labels: %s", err)
+	}
+	if labels["tks.io/team"] != "infra" {
+		t.Errorf("labels were not updated, labels: %v", labels)
+	}
+
+	selector, _ := label.ParseSelector("tks.io/team=infra")
+	appGroups, err := accessor.GetAppGroupsBySelector(clusterID, selector)
+	if err != nil || len(appGroups) != 1 || appGroups[0].AppGroupId != appGroupID {
+		t.Errorf("app group was not selected, app groups: %v, err: %v", appGroups, err)
+	}
+
+	if err := accessor.UpdateAppGroupLabels("A0000none", map[string]string{"env": "prod"}, nil); !errors.Is(err, integrity.ErrNotFound) {
+		t.Errorf("not found error was expected, but got %v", err)
+	}
+}
+
 func TestUpdateApp(t *testing.T) {
 	if err := accessor.UpdateApp(appGroupID, pb.AppType_PROMETHEUS,
 		"http://localhost:9090", "{\"metadata\":\"no_data\"}"); err != nil {
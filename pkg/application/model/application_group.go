@@ -6,6 +6,7 @@ import (
 	uuid "github.com/google/uuid"
 	"github.com/openinfradev/tks-common/pkg/helper"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -21,8 +22,11 @@ type ApplicationGroup struct {
 	ExternalLabel string
 	Creator       uuid.UUID
 	Description   string
-	UpdatedAt     time.Time
-	CreatedAt     time.Time
+	// Labels and Annotations are JSON objects of string values, which are NULL if empty.
+	Labels      datatypes.JSON
+	Annotations datatypes.JSON
+	UpdatedAt   time.Time
+	CreatedAt   time.Time
 }
 
 func (c *ApplicationGroup) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+
+	model "github.com/openinfradev/tks-info/pkg/audit/model"
+)
+
+const (
+	defaultQueryLimit = 100
+	maxQueryLimit     = 1000
+)
+
+// Filter selects audit logs. Zero fields don't filter.
+type Filter struct {
+	ContractID string
+	// ResourceID selects the calls which had the id in the request or created the resource.
+	ResourceID string
+	// Since and Until select the calls made in [Since, Until).
+	Since time.Time
+	Until time.Time
+	// Limit is the maximum number of audit logs, 100 if zero.
+	Limit int
+}
+
+// Accessor accesses to audit logs.
+type Accessor struct {
+	db *gorm.DB
+}
+
+// New returns new Accessor to access audit logs.
+func New(db *gorm.DB) *Accessor {
+	return &Accessor{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *Accessor) WithContext(ctx context.Context) *Accessor {
+	return &Accessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
+// Record appends the audit log.
+func (x *Accessor) Record(auditLog *model.AuditLog) error {
+	return x.db.Create(auditLog).Error
+}
+
+// Query returns the audit logs selected by the filter, the latest first.
+func (x *Accessor) Query(filter Filter) ([]model.AuditLog, error) {
+	query := x.db.Order("created_at DESC")
+	if filter.ContractID != "" {
+		query = query.Where("contract_id = ?", filter.ContractID)
+	}
+	if filter.ResourceID != "" {
+		ids, err := json.Marshal([]string{filter.ResourceID})
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("resource_ids @> ?", string(ids))
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultQueryLimit
+	} else if limit > maxQueryLimit {
+		limit = maxQueryLimit
+	}
+
+	var auditLogs []model.AuditLog
+	if res := query.Limit(limit).Find(&auditLogs); res.Error != nil {
+		return nil, res.Error
+	}
+	return auditLogs, nil
+}
@@ -0,0 +1,193 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+
+	"github.com/openinfradev/tks-info/pkg/audit"
+	"github.com/openinfradev/tks-info/pkg/audit/model"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+var (
+	accessor   *audit.Accessor
+	testDBHost string
+	testDBPort string
+)
+
+func init() {
+	log.Disable()
+}
+
+func getAccessor() (*audit.Accessor, error) {
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Seoul",
+		testDBHost, "postgres", "password", "tks", testDBPort)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
+
+	if err := db.AutoMigrate(&model.AuditLog{}); err != nil {
+		return nil, err
+	}
+
+	return audit.New(db), nil
+}
+
+func TestMain(m *testing.M) {
+	pool, resource, err := helper.CreatePostgres()
+	if err != nil {
+		fmt.Printf("Could not create postgres: %s", err)
+		os.Exit(-1)
+	}
+	testDBHost, testDBPort = helper.GetHostAndPort(resource)
+	accessor, _ = getAccessor()
+
+	code := m.Run()
+
+	if err := helper.RemovePostgres(pool, resource); err != nil {
+		fmt.Printf("Could not remove postgres: %s", err)
+		os.Exit(-1)
+	}
+	os.Exit(code)
+}
+
+func TestQuery(t *testing.T) {
+	contractId := helper.GenerateContractId()
+	cspId := "a6e2e6d2-7a8b-4d4e-9e8e-0f4f3f1c1a11"
+	for _, method := range []string{"CreateCSPInfo", "UpdateCSPAuth", "UpdateCSPAuth"} {
+		err := accessor.Record(&model.AuditLog{
+			Method:      "/tks_pb.CspInfoService/" + method,
+			ContractID:  contractId,
+			ResourceIDs: []byte(`["` + contractId + `","` + cspId + `"]`),
+		})
+		if err != nil {
+			t.Fatalf("An error occurred while recording audit log. Err: %s", err)
+		}
+	}
+	if err := accessor.Record(&model.AuditLog{Method: "/tks_pb.CspInfoService/CreateCSPInfo", ResourceIDs: []byte(`[]`)}); err != nil {
+		t.Fatalf("An error occurred while recording audit log. Err: %s", err)
+	}
+
+	auditLogs, err := accessor.Query(audit.Filter{ContractID: contractId})
+	if err != nil {
+		t.Fatalf("An error occurred while querying audit logs. Err: %s", err)
+	}
+	if len(auditLogs) != 3 || !strings.HasSuffix(auditLogs[0].Method, "UpdateCSPAuth") {
+		t.Errorf("Unexpected audit logs of contract: %+v", auditLogs)
+	}
+
+	auditLogs, err = accessor.Query(audit.Filter{ResourceID: cspId, Limit: 2})
+	if err != nil {
+		t.Fatalf("An error occurred while querying audit logs. Err: %s", err)
+	}
+	if len(auditLogs) != 2 {
+		t.Errorf("Unexpected audit logs of resource: %+v", auditLogs)
+	}
+
+	auditLogs, err = accessor.Query(audit.Filter{ContractID: contractId, Until: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("An error occurred while querying audit logs. Err: %s", err)
+	}
+	if len(auditLogs) != 0 {
+		t.Errorf("Unexpected audit logs before an hour ago: %+v", auditLogs)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	contractId := helper.GenerateContractId()
+	method := "/tks_pb.CspInfoService/CreateCSPInfo"
+	interceptor := audit.UnaryServerInterceptor(accessor, audit.Policy{
+		method: func(ctx context.Context, req interface{}) (string, error) {
+			return req.(*pb.CreateCSPInfoRequest).GetContractId(), nil
+		},
+	})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.IDResponse{Code: pb.Code_OK_UNSPECIFIED, Id: "created-csp"}, nil
+	}
+	ctx := auth.NewContext(context.Background(), &auth.Identity{Subject: "admin"})
+	req := &pb.CreateCSPInfoRequest{ContractId: contractId, CspName: "aws", Auth: `{"secretAccessKey":"secret"}`}
+
+	if _, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, handler); err != nil {
+		t.Fatalf("An error occurred while calling handler. Err: %s", err)
+	}
+	// RPCs which are not in the policy are not recorded.
+	if _, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: "/tks_pb.CspInfoService/GetCSPIDs"}, handler); err != nil {
+		t.Fatalf("An error occurred while calling handler. Err: %s", err)
+	}
+	failing := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.IDResponse{Code: pb.Code_INVALID_ARGUMENT}, errors.New("invalid auth")
+	}
+	if _, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, failing); err == nil {
+		t.Fatal("The error of the handler was not returned")
+	}
+
+	auditLogs, err := accessor.Query(audit.Filter{ContractID: contractId})
+	if err != nil {
+		t.Fatalf("An error occurred while querying audit logs. Err: %s", err)
+	}
+	if len(auditLogs) != 2 {
+		t.Fatalf("Unexpected audit logs: %+v", auditLogs)
+	}
+	failed, created := auditLogs[0], auditLogs[1]
+	if failed.Code != pb.Code_INVALID_ARGUMENT.String() || failed.Error != "invalid auth" {
+		t.Errorf("Unexpected outcome of failed call: %s %s", failed.Code, failed.Error)
+	}
+	if created.Caller != "admin" || created.Method != method || created.Code != pb.Code_OK_UNSPECIFIED.String() {
+		t.Errorf("Unexpected audit log: %+v", created)
+	}
+
+	var ids []string
+	if err := json.Unmarshal(created.ResourceIDs, &ids); err != nil {
+		t.Fatalf("An error occurred while parsing resource ids. Err: %s", err)
+	}
+	if len(ids) != 2 || ids[0] != contractId || ids[1] != "created-csp" {
+		t.Errorf("Unexpected resource ids: %v", ids)
+	}
+	if strings.Contains(string(created.Request), "secret") || !strings.Contains(string(created.Request), `"aws"`) {
+		t.Errorf("Request is not redacted: %s", created.Request)
+	}
+}
+
+func TestNewAuditLogOfStruct(t *testing.T) {
+	type request struct {
+		Kind      string            `json:"kind"`
+		Id        string            `json:"id"`
+		ClusterId string            `json:"cluster_id"`
+		Auth      string            `json:"auth"`
+		Labels    map[string]string `json:"labels"`
+	}
+	req := &request{Kind: "cluster", Id: "C1234abcd", ClusterId: "C1234abcd", Auth: "secret", Labels: map[string]string{"team_id": "infra"}}
+
+	auditLog := audit.NewAuditLog(context.Background(), "/tks_info.LabelService/UpdateLabels", req, nil, nil)
+	var ids []string
+	if err := json.Unmarshal(auditLog.ResourceIDs, &ids); err != nil {
+		t.Fatalf("An error occurred while parsing resource ids. Err: %s", err)
+	}
+	if len(ids) != 1 || ids[0] != "C1234abcd" {
+		t.Errorf("Unexpected resource ids: %v", ids)
+	}
+	if strings.Contains(string(auditLog.Request), "secret") || !strings.Contains(string(auditLog.Request), `"team_id":"infra"`) {
+		t.Errorf("Request is not redacted: %s", auditLog.Request)
+	}
+	if auditLog.Code != "OK" {
+		t.Errorf("Unexpected code: %s", auditLog.Code)
+	}
+}
@@ -0,0 +1,217 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"gorm.io/datatypes"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	model "github.com/openinfradev/tks-info/pkg/audit/model"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+const redacted = "[REDACTED]"
+
+// redactedFields are the names of request fields holding credentials.
+var redactedFields = map[protoreflect.Name]bool{
+	"auth":        true,
+	"secret":      true,
+	"private_key": true,
+	"app_secret":  true,
+	"password":    true,
+}
+
+// ContractFunc returns the contract which a request is about.
+type ContractFunc func(ctx context.Context, req interface{}) (string, error)
+
+// Policy maps full method names of the audited RPCs, such as "/tks_pb.CspInfoService/UpdateCSPAuth",
+// to the functions finding their contracts. A nil function leaves the contract empty.
+type Policy map[string]ContractFunc
+
+// UnaryServerInterceptor records an audit log for each call of the RPCs in the policy.
+// It must run after authentication to record the caller.
+// A failure to record is logged and does not fail the call.
+func UnaryServerInterceptor(accessor *Accessor, policy Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		contractFunc, ok := policy[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		// Find the contract before the call, as deleted resources can't be resolved afterwards.
+		contractId := ""
+		if contractFunc != nil {
+			var err error
+			if contractId, err = contractFunc(ctx, req); err != nil {
+				log.Warn("failed to find the contract of ", info.FullMethod, " for audit log. err : ", err)
+			}
+		}
+
+		res, err := handler(ctx, req)
+
+		auditLog := NewAuditLog(ctx, info.FullMethod, req, res, err)
+		auditLog.ContractID = contractId
+		// Record even if the call is cancelled, keeping the trace of the call.
+		recordCtx := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+		if err := accessor.WithContext(recordCtx).Record(auditLog); err != nil {
+			log.Error("failed to record audit log of ", info.FullMethod, ". err : ", err)
+		}
+		return res, err
+	}
+}
+
+// NewAuditLog returns the audit log of a call without the contract.
+func NewAuditLog(ctx context.Context, method string, req interface{}, res interface{}, err error) *model.AuditLog {
+	auditLog := &model.AuditLog{
+		Caller: auth.Subject(ctx),
+		Method: method,
+		Code:   responseCode(res, err),
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		auditLog.Peer = p.Addr.String()
+	}
+	if err != nil {
+		auditLog.Error = err.Error()
+	}
+
+	ids := []string{}
+	if msg, ok := req.(proto.Message); ok && msg != nil {
+		ids = resourceIDs(msg.ProtoReflect(), ids)
+		if request, err := redactedJSON(msg); err == nil {
+			auditLog.Request = datatypes.JSON(request)
+		} else {
+			log.Warn("failed to marshal request of ", method, " for audit log. err : ", err)
+		}
+	} else if req != nil {
+		// Requests of gateway routes are plain structs marshalled with their json tags.
+		var request []byte
+		var err error
+		if ids, request, err = jsonRequest(req, ids); err == nil {
+			auditLog.Request = datatypes.JSON(request)
+		} else {
+			log.Warn("failed to marshal request of ", method, " for audit log. err : ", err)
+		}
+	}
+	if r, ok := res.(interface{ GetId() string }); ok && res != nil && r.GetId() != "" {
+		ids = appendUnique(ids, r.GetId())
+	}
+	auditLog.ResourceIDs, _ = json.Marshal(ids)
+
+	return auditLog
+}
+
+type codeResponse interface {
+	GetCode() pb.Code
+}
+
+func responseCode(res interface{}, err error) string {
+	if r, ok := res.(codeResponse); ok && res != nil {
+		return r.GetCode().String()
+	}
+	return status.Code(err).String()
+}
+
+// resourceIDs appends the values of the id fields of msg and its nested messages to ids.
+func resourceIDs(msg protoreflect.Message, ids []string) []string {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() || fd.IsMap():
+		case fd.Kind() == protoreflect.StringKind:
+			if name := string(fd.Name()); name == "id" || strings.HasSuffix(name, "_id") {
+				ids = appendUnique(ids, v.String())
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			ids = resourceIDs(v.Message(), ids)
+		}
+		return true
+	})
+	return ids
+}
+
+func appendUnique(ids []string, id string) []string {
+	if id == "" {
+		return ids
+	}
+	for _, v := range ids {
+		if v == id {
+			return ids
+		}
+	}
+	return append(ids, id)
+}
+
+// redactedJSON returns the JSON of the fields set in msg, with credentials redacted.
+func redactedJSON(msg proto.Message) ([]byte, error) {
+	clone := proto.Clone(msg)
+	redact(clone.ProtoReflect())
+	return protojson.MarshalOptions{UseProtoNames: true}.Marshal(clone)
+}
+
+func redact(msg protoreflect.Message) {
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() || fd.IsMap():
+		case fd.Kind() == protoreflect.StringKind && redactedFields[fd.Name()]:
+			msg.Set(fd, protoreflect.ValueOfString(redacted))
+		case fd.Kind() == protoreflect.MessageKind:
+			redact(v.Message())
+		}
+		return true
+	})
+}
+
+// jsonRequest appends the values of the top-level id fields of req to ids, and returns the JSON of req
+// with credentials redacted.
+func jsonRequest(req interface{}, ids []string) ([]string, []byte, error) {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return ids, nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		return ids, nil, err
+	}
+	if fields, ok := value.(map[string]interface{}); ok {
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if id, ok := fields[name].(string); ok && (name == "id" || strings.HasSuffix(name, "_id")) {
+				ids = appendUnique(ids, id)
+			}
+		}
+	}
+	b, err = json.Marshal(redactJSON(value))
+	return ids, b, err
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if _, ok := field.(string); ok && redactedFields[protoreflect.Name(name)] {
+				v[name] = redacted
+			} else {
+				v[name] = redactJSON(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactJSON(item)
+		}
+	}
+	return value
+}
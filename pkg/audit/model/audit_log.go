@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	uuid "github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// AuditLog records a call of a mutating RPC. Audit logs are never updated or deleted.
+type AuditLog struct {
+	ID uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	// Caller is the authenticated subject, or empty if authentication is disabled.
+	Caller string
+	// Peer is the address which the call came from.
+	Peer       string
+	Method     string
+	ContractID string `gorm:"index"`
+	// ResourceIDs is a JSON array of the ids in the request and the id of a created resource.
+	ResourceIDs datatypes.JSON
+	// Request is the JSON of the fields set in the request, with secrets redacted.
+	Request   datatypes.JSON
+	Code      string
+	Error     string
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (c *AuditLog) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
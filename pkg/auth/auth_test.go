@@ -0,0 +1,220 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+)
+
+func init() {
+	log.Disable()
+}
+
+func TestIdentity(t *testing.T) {
+	reader := &auth.Identity{Roles: []auth.Role{auth.RoleReader}, Contracts: []string{"P123"}}
+	require.True(t, reader.HasRole(auth.RoleReader))
+	require.False(t, reader.HasRole(auth.RoleWorkflowWriter))
+	require.True(t, reader.CanAccess("P123"))
+	require.False(t, reader.CanAccess("P456"))
+	require.False(t, reader.CanAccess(""))
+	require.False(t, reader.HasAllContracts())
+
+	writer := &auth.Identity{Roles: []auth.Role{auth.RoleWorkflowWriter}, Contracts: []string{auth.AllContracts}}
+	require.True(t, writer.HasRole(auth.RoleReader))
+	require.True(t, writer.HasAllContracts())
+
+	admin := &auth.Identity{Roles: []auth.Role{auth.RoleAdmin}}
+	require.True(t, admin.HasRole(auth.RoleWorkflowWriter))
+	require.True(t, admin.CanAccess("P456"))
+}
+
+type testIssuer struct {
+	key    *rsa.PrivateKey
+	server *httptest.Server
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kid": "key-1",
+				"kty": "RSA",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return &testIssuer{key: key, server: server}
+}
+
+func (i *testIssuer) token(t *testing.T, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = "key-1"
+	s, err := token.SignedString(i.key)
+	require.NoError(t, err)
+	return s
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	issuer := newTestIssuer(t)
+	jwks := auth.NewJWKS(issuer.server.URL, time.Minute)
+	authenticator := auth.NewJWTAuthenticator(jwks.Keyfunc, "https://keycloak/realms/tks", "tks-info", "realm_access.roles", "contracts")
+
+	claims := jwt.MapClaims{
+		"sub":                "1234",
+		"preferred_username": "alice",
+		"iss":                "https://keycloak/realms/tks",
+		"aud":                "tks-info",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"realm_access":       map[string]interface{}{"roles": []string{"offline_access", "reader"}},
+		"contracts":          []string{"P123"},
+	}
+	identity, err := authenticator.Authenticate(withToken(issuer.token(t, claims)))
+	require.NoError(t, err)
+	require.Equal(t, "alice", identity.Subject)
+	require.Equal(t, []auth.Role{auth.RoleReader}, identity.Roles)
+	require.Equal(t, []string{"P123"}, identity.Contracts)
+
+	_, err = authenticator.Authenticate(context.Background())
+	require.ErrorIs(t, err, auth.ErrUnauthenticated)
+
+	invalid := map[string]func(c jwt.MapClaims){
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://other" },
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "other" },
+	}
+	for name, modify := range invalid {
+		t.Run(name, func(t *testing.T) {
+			c := jwt.MapClaims{}
+			for k, v := range claims {
+				c[k] = v
+			}
+			modify(c)
+			_, err := authenticator.Authenticate(withToken(issuer.token(t, c)))
+			require.Error(t, err)
+		})
+	}
+
+	other := newTestIssuer(t)
+	_, err = authenticator.Authenticate(withToken(other.token(t, claims)))
+	require.Error(t, err)
+}
+
+func TestTLSAuthenticator(t *testing.T) {
+	cert := &x509.Certificate{Subject: pkix.Name{
+		CommonName:         "tks-cluster-lcm",
+		OrganizationalUnit: []string{"workflow-writer"},
+		Organization:       []string{auth.AllContracts},
+	}}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{
+		State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+	}})
+
+	identity, err := auth.TLSAuthenticator{}.Authenticate(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "tks-cluster-lcm", identity.Subject)
+	require.Equal(t, []auth.Role{auth.RoleWorkflowWriter}, identity.Roles)
+	require.True(t, identity.HasAllContracts())
+
+	_, err = auth.TLSAuthenticator{}.Authenticate(context.Background())
+	require.ErrorIs(t, err, auth.ErrUnauthenticated)
+}
+
+type staticAuthenticator struct {
+	identity *auth.Identity
+}
+
+func (a staticAuthenticator) Authenticate(ctx context.Context) (*auth.Identity, error) {
+	if a.identity == nil {
+		return nil, auth.ErrUnauthenticated
+	}
+	return a.identity, nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	contracts := map[string]string{"cluster-a": "P123", "cluster-b": "P456"}
+	policy := auth.Policy{
+		"/svc/Get": {Role: auth.RoleReader, Contract: func(ctx context.Context, req interface{}) (string, error) {
+			contractId, ok := contracts[req.(string)]
+			if !ok {
+				return "", fmt.Errorf("%w: cluster %s", integrity.ErrNotFound, req)
+			}
+			return contractId, nil
+		}},
+		"/svc/Update": {Role: auth.RoleWorkflowWriter, Contract: func(ctx context.Context, req interface{}) (string, error) {
+			return contracts[req.(string)], nil
+		}},
+		"/svc/List": {Role: auth.RoleReader},
+	}
+
+	reader := &auth.Identity{Subject: "alice", Roles: []auth.Role{auth.RoleReader}, Contracts: []string{"P123"}}
+	admin := &auth.Identity{Subject: "root", Roles: []auth.Role{auth.RoleAdmin}}
+
+	tests := []struct {
+		name     string
+		identity *auth.Identity
+		method   string
+		req      string
+		code     codes.Code
+	}{
+		{"own contract", reader, "/svc/Get", "cluster-a", codes.OK},
+		{"other contract", reader, "/svc/Get", "cluster-b", codes.PermissionDenied},
+		{"unknown resource", reader, "/svc/Get", "cluster-c", codes.PermissionDenied},
+		{"insufficient role", reader, "/svc/Update", "cluster-a", codes.PermissionDenied},
+		{"all contracts", reader, "/svc/List", "", codes.PermissionDenied},
+		{"not in policy", reader, "/svc/Delete", "cluster-a", codes.PermissionDenied},
+		{"no credentials", nil, "/svc/Get", "cluster-a", codes.Unauthenticated},
+		{"public", nil, "/grpc.health.v1.Health/Check", "", codes.OK},
+		{"admin", admin, "/svc/Delete", "cluster-b", codes.OK},
+		{"admin list", admin, "/svc/List", "", codes.OK},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			interceptor := auth.UnaryServerInterceptor(staticAuthenticator{tc.identity}, policy, "/grpc.health.v1.Health/")
+
+			var subject string
+			_, err := interceptor(context.Background(), tc.req, &grpc.UnaryServerInfo{FullMethod: tc.method},
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					subject = auth.Subject(ctx)
+					return nil, nil
+				})
+			require.Equal(t, tc.code, status.Code(err))
+			if tc.code == codes.OK && tc.identity != nil {
+				require.Equal(t, tc.identity.Subject, subject)
+			}
+		})
+	}
+}
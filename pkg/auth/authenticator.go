@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// ErrUnauthenticated is returned when a request carries no credentials.
+var ErrUnauthenticated = errors.New("request has no credentials")
+
+// Authenticator identifies the caller of a request.
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Identity, error)
+}
+
+// JWTAuthenticator authenticates requests with a bearer token in the "authorization" metadata,
+// such as an access token issued by Keycloak.
+type JWTAuthenticator struct {
+	keys           jwt.Keyfunc
+	issuer         string
+	audience       string
+	rolesClaim     string
+	contractsClaim string
+}
+
+// NewJWTAuthenticator returns new JWTAuthenticator which verifies tokens with keys.
+// issuer and audience are checked if they are not empty. rolesClaim and contractsClaim
+// are paths of the claims holding roles and contract ids, such as "realm_access.roles".
+func NewJWTAuthenticator(keys jwt.Keyfunc, issuer string, audience string, rolesClaim string, contractsClaim string) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		keys:           keys,
+		issuer:         issuer,
+		audience:       audience,
+		rolesClaim:     rolesClaim,
+		contractsClaim: contractsClaim,
+	}
+}
+
+func (a *JWTAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, ErrUnauthenticated
+	}
+	raw := strings.TrimSpace(values[0])
+	if len(raw) < 7 || !strings.EqualFold(raw[:7], "bearer ") {
+		return nil, errors.New("authorization must be a bearer token")
+	}
+
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}))
+	if _, err := parser.ParseWithClaims(strings.TrimSpace(raw[7:]), claims, a.keys); err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	if a.issuer != "" && !claims.VerifyIssuer(a.issuer, true) {
+		return nil, errors.New("invalid token: unexpected issuer")
+	}
+	if a.audience != "" && !claims.VerifyAudience(a.audience, true) {
+		return nil, errors.New("invalid token: unexpected audience")
+	}
+
+	subject, _ := claims["sub"].(string)
+	if username, ok := claims["preferred_username"].(string); ok && username != "" {
+		subject = username
+	}
+	return &Identity{
+		Subject:   subject,
+		Roles:     parseRoles(stringsClaim(claims, a.rolesClaim)),
+		Contracts: stringsClaim(claims, a.contractsClaim),
+	}, nil
+}
+
+// stringsClaim returns the claim at the dotted path as a list of strings.
+func stringsClaim(claims jwt.MapClaims, path string) []string {
+	var value interface{} = map[string]interface{}(claims)
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := []string{}
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+// TLSAuthenticator authenticates requests with a verified client certificate.
+// The subject is the common name, roles are the organizational units,
+// and contracts are the organizations of the certificate.
+type TLSAuthenticator struct{}
+
+func (a TLSAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	cert := clientCertificate(ctx)
+	if cert == nil {
+		return nil, ErrUnauthenticated
+	}
+	return &Identity{
+		Subject:   cert.Subject.CommonName,
+		Roles:     parseRoles(cert.Subject.OrganizationalUnit),
+		Contracts: cert.Subject.Organization,
+	}, nil
+}
+
+func clientCertificate(ctx context.Context) *x509.Certificate {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return info.State.VerifiedChains[0][0]
+}
+
+// Authenticators tries each authenticator in order until one finds credentials in the request.
+type Authenticators []Authenticator
+
+func (a Authenticators) Authenticate(ctx context.Context) (*Identity, error) {
+	for _, authenticator := range a {
+		identity, err := authenticator.Authenticate(ctx)
+		if errors.Is(err, ErrUnauthenticated) {
+			continue
+		}
+		return identity, err
+	}
+	return nil, ErrUnauthenticated
+}
@@ -0,0 +1,87 @@
+package auth
+
+import "context"
+
+// Role is what a caller is allowed to do. Each role includes the roles below it.
+type Role string
+
+const (
+	// RoleReader reads resources of its contracts.
+	RoleReader Role = "reader"
+	// RoleWorkflowWriter also creates resources and updates their status, as workflows do.
+	RoleWorkflowWriter Role = "workflow-writer"
+	// RoleAdmin can do anything on any contract.
+	RoleAdmin Role = "admin"
+)
+
+var roleLevels = map[Role]int{
+	RoleReader:         1,
+	RoleWorkflowWriter: 2,
+	RoleAdmin:          3,
+}
+
+// AllContracts in Identity.Contracts grants access to every contract.
+const AllContracts = "*"
+
+// Identity is an authenticated caller.
+type Identity struct {
+	Subject   string
+	Roles     []Role
+	Contracts []string
+}
+
+// HasRole returns true if the identity has role or a role which includes it.
+func (i *Identity) HasRole(role Role) bool {
+	for _, r := range i.Roles {
+		if roleLevels[r] >= roleLevels[role] {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAllContracts returns true if the identity can access every contract.
+func (i *Identity) HasAllContracts() bool {
+	return i.HasRole(RoleAdmin) || i.CanAccess(AllContracts)
+}
+
+// CanAccess returns true if the identity can access the contract.
+func (i *Identity) CanAccess(contractId string) bool {
+	for _, c := range i.Contracts {
+		if c == AllContracts || (c == contractId && contractId != "") {
+			return true
+		}
+	}
+	return i.HasRole(RoleAdmin)
+}
+
+type identityKey struct{}
+
+// NewContext returns a copy of ctx which carries the identity.
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, identity)
+}
+
+// FromContext returns the identity of the caller, if the request was authenticated.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityKey{}).(*Identity)
+	return identity, ok
+}
+
+// Subject returns the subject of the caller, or "" if the request was not authenticated.
+func Subject(ctx context.Context) string {
+	if identity, ok := FromContext(ctx); ok {
+		return identity.Subject
+	}
+	return ""
+}
+
+func parseRoles(values []string) []Role {
+	roles := []Role{}
+	for _, v := range values {
+		if _, ok := roleLevels[Role(v)]; ok {
+			roles = append(roles, Role(v))
+		}
+	}
+	return roles
+}
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+)
+
+// ContractFunc returns the contract which a request is about.
+type ContractFunc func(ctx context.Context, req interface{}) (string, error)
+
+// Rule is the authorization rule of an RPC.
+type Rule struct {
+	// Role is the least role to call the RPC.
+	Role Role
+	// Contract returns the contract of the request. If nil, the RPC spans every contract
+	// and only callers with access to all contracts may call it.
+	Contract ContractFunc
+}
+
+// Policy maps full method names, such as "/tks_pb.ClusterInfoService/GetCluster", to rules.
+// RPCs which are not in the policy are allowed only to admins.
+type Policy map[string]Rule
+
+// UnaryServerInterceptor authenticates each request and checks it against the policy.
+// Methods starting with one of the public prefixes are not checked.
+// The identity of the caller is put into the context for handlers.
+func UnaryServerInterceptor(authenticator Authenticator, policy Policy, public ...string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		for _, prefix := range public {
+			if strings.HasPrefix(info.FullMethod, prefix) {
+				return handler(ctx, req)
+			}
+		}
+
+		identity, err := authenticator.Authenticate(ctx)
+		if err != nil {
+			log.Warn("unauthenticated request to ", info.FullMethod, ". err : ", err)
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		if err := authorize(ctx, identity, policy, info.FullMethod, req); err != nil {
+			log.Warn("denied request of ", identity.Subject, " to ", info.FullMethod, ". err : ", err)
+			return nil, err
+		}
+		return handler(NewContext(ctx, identity), req)
+	}
+}
+
+func authorize(ctx context.Context, identity *Identity, policy Policy, method string, req interface{}) error {
+	rule, ok := policy[method]
+	if !ok {
+		rule = Rule{Role: RoleAdmin}
+	}
+	if !identity.HasRole(rule.Role) {
+		return status.Errorf(codes.PermissionDenied, "%s role is required", rule.Role)
+	}
+	if identity.HasAllContracts() {
+		return nil
+	}
+	if rule.Contract == nil {
+		return status.Error(codes.PermissionDenied, "access to all contracts is required")
+	}
+
+	contractId, err := rule.Contract(ctx, req)
+	if errors.Is(err, integrity.ErrNotFound) {
+		// Don't tell callers whether resources of other contracts exist.
+		return status.Error(codes.PermissionDenied, "no access to the contract of the request")
+	} else if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	if !identity.CanAccess(contractId) {
+		return status.Error(codes.PermissionDenied, "no access to the contract of the request")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	asaModel "github.com/openinfradev/tks-info/pkg/app_serve_app/model"
+	appModel "github.com/openinfradev/tks-info/pkg/application/model"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+)
+
+// Resolver finds the contract which a resource belongs to.
+// It returns an error wrapping integrity.ErrNotFound if the resource does not exist.
+type Resolver struct {
+	db *gorm.DB
+}
+
+// NewResolver returns new Resolver.
+func NewResolver(db *gorm.DB) *Resolver {
+	return &Resolver{
+		db: db,
+	}
+}
+
+// ClusterContract returns the contract of the cluster.
+func (r *Resolver) ClusterContract(ctx context.Context, clusterId string) (string, error) {
+	cluster, err := integrity.GetCluster(r.db.WithContext(ctx), clusterId)
+	if err != nil {
+		return "", err
+	}
+	return cluster.ContractID, nil
+}
+
+// CSPContract returns the contract of the CSP info.
+func (r *Resolver) CSPContract(ctx context.Context, cspId string) (string, error) {
+	id, err := uuid.Parse(cspId)
+	if err != nil {
+		return "", fmt.Errorf("%w: csp %s", integrity.ErrNotFound, cspId)
+	}
+	cspInfo, err := integrity.GetCSPInfo(r.db.WithContext(ctx), id)
+	if err != nil {
+		return "", err
+	}
+	return cspInfo.ContractID, nil
+}
+
+// AppGroupContract returns the contract of the cluster which the app group is on.
+func (r *Resolver) AppGroupContract(ctx context.Context, appGroupId string) (string, error) {
+	var appGroup appModel.ApplicationGroup
+	res := r.db.WithContext(ctx).Select("cluster_id").First(&appGroup, "id = ?", appGroupId)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("%w: app group %s", integrity.ErrNotFound, appGroupId)
+	} else if res.Error != nil {
+		return "", res.Error
+	}
+	return r.ClusterContract(ctx, appGroup.ClusterId)
+}
+
+// AppServeAppContract returns the contract of the app serve app.
+func (r *Resolver) AppServeAppContract(ctx context.Context, appServeAppId string) (string, error) {
+	id, err := uuid.Parse(appServeAppId)
+	if err != nil {
+		return "", fmt.Errorf("%w: app serve app %s", integrity.ErrNotFound, appServeAppId)
+	}
+	var asa asaModel.AppServeApp
+	res := r.db.WithContext(ctx).Select("contract_id").First(&asa, "id = ?", id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("%w: app serve app %s", integrity.ErrNotFound, appServeAppId)
+	} else if res.Error != nil {
+		return "", res.Error
+	}
+	return asa.ContractId, nil
+}
+
+// AppServeAppTaskContract returns the contract of the app serve app which the task belongs to.
+func (r *Resolver) AppServeAppTaskContract(ctx context.Context, taskId string) (string, error) {
+	id, err := uuid.Parse(taskId)
+	if err != nil {
+		return "", fmt.Errorf("%w: app serve app task %s", integrity.ErrNotFound, taskId)
+	}
+	var task asaModel.AppServeAppTask
+	res := r.db.WithContext(ctx).Select("app_serve_app_id").First(&task, "id = ?", id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return "", fmt.Errorf("%w: app serve app task %s", integrity.ErrNotFound, taskId)
+	} else if res.Error != nil {
+		return "", res.Error
+	}
+	return r.AppServeAppContract(ctx, task.AppServeAppId.String())
+}
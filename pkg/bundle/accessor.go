@@ -0,0 +1,385 @@
+package bundle
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	asaModel "github.com/openinfradev/tks-info/pkg/app_serve_app/model"
+	appModel "github.com/openinfradev/tks-info/pkg/application/model"
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
+	cspModel "github.com/openinfradev/tks-info/pkg/csp_info/model"
+	keycloakModel "github.com/openinfradev/tks-info/pkg/keycloak_info/model"
+)
+
+// Policies of Import for resources which already exist.
+const (
+	// ConflictFail imports nothing if any resource exists.
+	ConflictFail = "fail"
+	// ConflictSkip keeps the existing resources.
+	ConflictSkip = "skip"
+	// ConflictOverwrite replaces the existing resources. Secrets are kept if they are stripped from the bundle.
+	ConflictOverwrite = "overwrite"
+)
+
+// batchSize is the number of rows inserted or looked up by a query.
+const batchSize = 500
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// ConflictError is returned by Import with ConflictFail when resources of the bundle already exist.
+type ConflictError struct {
+	// Resources are the existing resources such as "clusters/C1234abcd".
+	Resources []string
+}
+
+func (e *ConflictError) Error() string {
+	resources := e.Resources
+	if len(resources) > 10 {
+		resources = append(resources[:10:10], "...")
+	}
+	return fmt.Sprintf("%d resources already exist: %s", len(e.Resources), strings.Join(resources, ", "))
+}
+
+// Result is the number of resources of a kind imported.
+type Result struct {
+	Kind    string
+	Created int
+	Updated int
+	Skipped int
+}
+
+// Accessor exports and imports bundles.
+type Accessor struct {
+	db *gorm.DB
+}
+
+// New returns new Accessor to export and import bundles.
+func New(db *gorm.DB) *Accessor {
+	return &Accessor{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *Accessor) WithContext(ctx context.Context) *Accessor {
+	return &Accessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
+// Export returns the resources of the contracts, or of all contracts if contractIds is empty.
+// Resources are read in a single snapshot, and secrets are in plain text.
+func (x *Accessor) Export(contractIds []string) (*Bundle, error) {
+	b := &Bundle{
+		Version:     Version,
+		ExportedAt:  time.Now(),
+		ContractIds: append([]string{}, contractIds...),
+		Secrets:     SecretsPlain,
+	}
+	sort.Strings(b.ContractIds)
+	all := len(contractIds) == 0
+
+	var (
+		cspInfos      []cspModel.CSPInfo
+		cspAuths      []cspModel.CSPAuth
+		clusters      []clusterModel.Cluster
+		appGroups     []appModel.ApplicationGroup
+		applications  []appModel.Application
+		keycloakInfos []keycloakModel.KeycloakInfo
+		asas          []asaModel.AppServeApp
+		tasks         []asaModel.AppServeAppTask
+	)
+	err := x.db.Transaction(func(tx *gorm.DB) error {
+		tx = tx.Order("created_at, id").Session(&gorm.Session{})
+		if err := in(tx, all, "contract_id", contractIds).Find(&cspInfos).Error; err != nil {
+			return err
+		}
+		cspIds := []string{}
+		for _, m := range cspInfos {
+			cspIds = append(cspIds, m.ID.String())
+		}
+		if err := in(tx, all, "csp_id", cspIds).Find(&cspAuths).Error; err != nil {
+			return err
+		}
+
+		if err := in(tx, all, "contract_id", contractIds).Find(&clusters).Error; err != nil {
+			return err
+		}
+		clusterIds := []string{}
+		for _, m := range clusters {
+			clusterIds = append(clusterIds, m.ID)
+		}
+		if err := in(tx, all, "cluster_id", clusterIds).Find(&appGroups).Error; err != nil {
+			return err
+		}
+		appGroupIds := []string{}
+		for _, m := range appGroups {
+			appGroupIds = append(appGroupIds, m.ID)
+		}
+		if err := in(tx, all, "app_group_id", appGroupIds).Find(&applications).Error; err != nil {
+			return err
+		}
+		if err := in(tx, all, "cluster_id", clusterIds).Find(&keycloakInfos).Error; err != nil {
+			return err
+		}
+
+		if err := in(tx, all, "contract_id", contractIds).Find(&asas).Error; err != nil {
+			return err
+		}
+		asaIds := []string{}
+		for _, m := range asas {
+			asaIds = append(asaIds, m.ID.String())
+		}
+		return in(tx, all, "app_serve_app_id", asaIds).Find(&tasks).Error
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to export: %w", err)
+	}
+
+	for _, m := range cspInfos {
+		b.CspInfos = append(b.CspInfos, fromCspInfo(m))
+	}
+	for _, m := range cspAuths {
+		b.CspAuths = append(b.CspAuths, fromCspAuth(m))
+	}
+	for _, m := range clusters {
+		b.Clusters = append(b.Clusters, fromCluster(m))
+	}
+	for _, m := range appGroups {
+		b.AppGroups = append(b.AppGroups, fromAppGroup(m))
+	}
+	for _, m := range applications {
+		b.Applications = append(b.Applications, fromApplication(m))
+	}
+	for _, m := range keycloakInfos {
+		b.KeycloakInfos = append(b.KeycloakInfos, fromKeycloakInfo(m))
+	}
+	for _, m := range asas {
+		b.AppServeApps = append(b.AppServeApps, fromAppServeApp(m))
+	}
+	for _, m := range tasks {
+		b.AppServeAppTasks = append(b.AppServeAppTasks, fromAppServeAppTask(m))
+	}
+	return b, nil
+}
+
+// in returns the query of the rows whose column is one of values, or of all rows if all is true.
+func in(tx *gorm.DB, all bool, column string, values []string) *gorm.DB {
+	if all {
+		return tx
+	}
+	if len(values) == 0 {
+		return tx.Where("1 = 0")
+	}
+	return tx.Where(column+" IN ?", values)
+}
+
+// table is the rows of a table in a bundle.
+type table struct {
+	kind  string
+	model interface{}
+	// rows is a pointer to the slice of the models.
+	rows interface{}
+	ids  []string
+	// secrets are the columns holding secrets.
+	secrets []string
+}
+
+// tables returns the rows of the bundle in the order they can be inserted without breaking foreign keys.
+func (b *Bundle) tables() ([]table, error) {
+	cspInfos := []cspModel.CSPInfo{}
+	cspInfoIds := []string{}
+	for _, r := range b.CspInfos {
+		m, err := r.model()
+		if err != nil {
+			return nil, fmt.Errorf("csp_infos/%s: %w", r.ID, err)
+		}
+		cspInfos = append(cspInfos, m)
+		cspInfoIds = append(cspInfoIds, m.ID.String())
+	}
+	cspAuths := []cspModel.CSPAuth{}
+	cspAuthIds := []string{}
+	for _, r := range b.CspAuths {
+		m, _ := r.model()
+		cspAuths = append(cspAuths, m)
+		cspAuthIds = append(cspAuthIds, m.ID.String())
+	}
+	clusters := []clusterModel.Cluster{}
+	clusterIds := []string{}
+	for _, r := range b.Clusters {
+		m, err := r.model()
+		if err != nil {
+			return nil, fmt.Errorf("clusters/%s: %w", r.ID, err)
+		}
+		clusters = append(clusters, m)
+		clusterIds = append(clusterIds, m.ID)
+	}
+	appGroups := []appModel.ApplicationGroup{}
+	appGroupIds := []string{}
+	for _, r := range b.AppGroups {
+		m, err := r.model()
+		if err != nil {
+			return nil, fmt.Errorf("application_groups/%s: %w", r.ID, err)
+		}
+		appGroups = append(appGroups, m)
+		appGroupIds = append(appGroupIds, m.ID)
+	}
+	applications := []appModel.Application{}
+	applicationIds := []string{}
+	for _, r := range b.Applications {
+		m, err := r.model()
+		if err != nil {
+			return nil, fmt.Errorf("applications/%s: %w", r.ID, err)
+		}
+		applications = append(applications, m)
+		applicationIds = append(applicationIds, m.ID.String())
+	}
+	keycloakInfos := []keycloakModel.KeycloakInfo{}
+	keycloakInfoIds := []string{}
+	for _, r := range b.KeycloakInfos {
+		m, _ := r.model()
+		keycloakInfos = append(keycloakInfos, m)
+		keycloakInfoIds = append(keycloakInfoIds, m.Id.String())
+	}
+	asas := []asaModel.AppServeApp{}
+	asaIds := []string{}
+	for _, r := range b.AppServeApps {
+		m, _ := r.model()
+		asas = append(asas, m)
+		asaIds = append(asaIds, m.ID.String())
+	}
+	tasks := []asaModel.AppServeAppTask{}
+	taskIds := []string{}
+	for _, r := range b.AppServeAppTasks {
+		m, _ := r.model()
+		tasks = append(tasks, m)
+		taskIds = append(taskIds, m.ID.String())
+	}
+
+	return []table{
+		{"csp_infos", &cspModel.CSPInfo{}, &cspInfos, cspInfoIds, []string{"auth"}},
+		{"csp_auths", &cspModel.CSPAuth{}, &cspAuths, cspAuthIds, []string{"auth"}},
+		{"clusters", &clusterModel.Cluster{}, &clusters, clusterIds, []string{"kubeconfig"}},
+		{"application_groups", &appModel.ApplicationGroup{}, &appGroups, appGroupIds, nil},
+		{"applications", &appModel.Application{}, &applications, applicationIds, nil},
+		{"keycloak_infos", &keycloakModel.KeycloakInfo{}, &keycloakInfos, keycloakInfoIds, []string{"secret", "private_key"}},
+		{"app_serve_apps", &asaModel.AppServeApp{}, &asas, asaIds, nil},
+		{"app_serve_app_tasks", &asaModel.AppServeAppTask{}, &tasks, taskIds, []string{"app_secret"}},
+	}, nil
+}
+
+// Import inserts the resources of the bundle with their IDs in a transaction, so that either all or
+// none of them are imported. Resources which already exist are handled by onConflict, one of
+// ConflictFail, ConflictSkip and ConflictOverwrite. A dry run returns the results and imports nothing.
+// Secrets of the bundle must not be encrypted, and stripped secrets are imported as empty.
+func (x *Accessor) Import(b *Bundle, onConflict string, dryRun bool) ([]Result, error) {
+	if b.Secrets == SecretsEncrypted {
+		return nil, errors.New("secrets of bundle must be decrypted before import")
+	}
+	if !isOneOf(onConflict, ConflictFail, ConflictSkip, ConflictOverwrite) {
+		return nil, fmt.Errorf("invalid conflict policy %q", onConflict)
+	}
+	tables, err := b.tables()
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	var results []Result
+	// Hooks are skipped to keep the IDs, which BeforeCreate replaces with new ones.
+	err = x.db.Session(&gorm.Session{SkipHooks: true}).Transaction(func(tx *gorm.DB) error {
+		results = []Result{}
+		existing := make([]int, len(tables))
+		conflicts := []string{}
+		for i, t := range tables {
+			ids, err := existingIds(tx, t)
+			if err != nil {
+				return err
+			}
+			existing[i] = len(ids)
+			for _, id := range ids {
+				conflicts = append(conflicts, t.kind+"/"+id)
+			}
+		}
+		if onConflict == ConflictFail && len(conflicts) > 0 {
+			return &ConflictError{Resources: conflicts}
+		}
+
+		for i, t := range tables {
+			result := Result{Kind: t.kind, Created: len(t.ids) - existing[i]}
+			if len(t.ids) == 0 {
+				results = append(results, result)
+				continue
+			}
+
+			q := tx
+			onId := []clause.Column{{Name: "id"}}
+			switch onConflict {
+			case ConflictSkip:
+				result.Skipped = existing[i]
+				q = tx.Clauses(clause.OnConflict{Columns: onId, DoNothing: true})
+			case ConflictOverwrite:
+				result.Updated = existing[i]
+				columns, err := updatedColumns(tx, t, b.Secrets == SecretsStripped)
+				if err != nil {
+					return err
+				}
+				q = tx.Clauses(clause.OnConflict{Columns: onId, DoUpdates: clause.AssignmentColumns(columns)})
+			}
+			if err := q.CreateInBatches(t.rows, batchSize).Error; err != nil {
+				return fmt.Errorf("failed to import %s: %w", t.kind, err)
+			}
+			results = append(results, result)
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+	return results, nil
+}
+
+// existingIds returns the IDs of the rows of the table which exist in the database.
+func existingIds(tx *gorm.DB, t table) ([]string, error) {
+	existing := []string{}
+	for start := 0; start < len(t.ids); start += batchSize {
+		end := start + batchSize
+		if end > len(t.ids) {
+			end = len(t.ids)
+		}
+		var ids []string
+		if err := tx.Model(t.model).Where("id IN ?", t.ids[start:end]).Pluck("id", &ids).Error; err != nil {
+			return nil, fmt.Errorf("failed to look up %s: %w", t.kind, err)
+		}
+		existing = append(existing, ids...)
+	}
+	sort.Strings(existing)
+	return existing, nil
+}
+
+// updatedColumns returns the columns of the table overwritten by import.
+func updatedColumns(tx *gorm.DB, t table, keepSecrets bool) ([]string, error) {
+	stmt := &gorm.Statement{DB: tx}
+	if err := stmt.Parse(t.model); err != nil {
+		return nil, err
+	}
+	columns := []string{}
+	for _, name := range stmt.Schema.DBNames {
+		if name == "id" || (keepSecrets && isOneOf(name, t.secrets...)) {
+			continue
+		}
+		columns = append(columns, name)
+	}
+	return columns, nil
+}
@@ -0,0 +1,179 @@
+package bundle_test
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+
+	asa "github.com/openinfradev/tks-info/pkg/app_serve_app"
+	modelAsa "github.com/openinfradev/tks-info/pkg/app_serve_app/model"
+	"github.com/openinfradev/tks-info/pkg/application"
+	modelApplication "github.com/openinfradev/tks-info/pkg/application/model"
+	"github.com/openinfradev/tks-info/pkg/bundle"
+	"github.com/openinfradev/tks-info/pkg/cluster"
+	modelCluster "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/csp_info"
+	modelCspInfo "github.com/openinfradev/tks-info/pkg/csp_info/model"
+	"github.com/openinfradev/tks-info/pkg/keycloak_info"
+	modelKeycloakInfo "github.com/openinfradev/tks-info/pkg/keycloak_info/model"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+var (
+	testDBHost string
+	testDBPort string
+)
+
+func init() {
+	log.Disable()
+}
+
+// getDB returns a new database, so that resources can be imported to another installation.
+func getDB(name string) (*gorm.DB, error) {
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Seoul",
+		testDBHost, "postgres", "password", "tks", testDBPort)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	db.Exec("CREATE DATABASE " + name)
+
+	dsn = fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Seoul",
+		testDBHost, "postgres", "password", name, testDBPort)
+	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
+
+	if err := db.AutoMigrate(
+		&modelCspInfo.CSPInfo{},
+		&modelCspInfo.CSPAuth{},
+		&modelCluster.Cluster{},
+		&modelApplication.ApplicationGroup{},
+		&modelApplication.Application{},
+		&modelKeycloakInfo.KeycloakInfo{},
+		&modelAsa.AppServeApp{},
+		&modelAsa.AppServeAppTask{},
+	); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func TestMain(m *testing.M) {
+	pool, resource, err := helper.CreatePostgres()
+	if err != nil {
+		fmt.Printf("Could not create postgres: %s", err)
+		os.Exit(-1)
+	}
+	testDBHost, testDBPort = helper.GetHostAndPort(resource)
+
+	code := m.Run()
+
+	if err := helper.RemovePostgres(pool, resource); err != nil {
+		fmt.Printf("Could not remove postgres: %s", err)
+		os.Exit(-1)
+	}
+	os.Exit(code)
+}
+
+func createContract(t *testing.T, db *gorm.DB, contractId string) string {
+	cspId, err := csp_info.New(db).Create(contractId, "csp", `{"accessKeyId":"id","secretAccessKey":"key"}`, pb.CspType_AWS)
+	require.NoError(t, err)
+	clusterId, err := cluster.New(db).CreateClusterInfo(contractId, cspId, "cluster", &pb.ClusterConf{Region: "ap-northeast-2"}, uuid.Nil, "")
+	require.NoError(t, err)
+	appGroupId, err := application.New(db).Create(clusterId, &pb.AppGroup{AppGroupName: "lma", Type: pb.AppGroupType_LMA})
+	require.NoError(t, err)
+	require.NoError(t, application.New(db).UpdateApp(appGroupId, pb.AppType_PROMETHEUS, "prometheus:9090", `{"port":9090}`))
+	_, err = keycloak_info.New(db).Create(clusterId, "realm", "clientId", "secret", "privateKey")
+	require.NoError(t, err)
+	_, _, err = asa.New(db).Create(contractId,
+		&pb.AppServeApp{Name: "app", ContractId: contractId, TargetClusterId: clusterId},
+		&pb.AppServeAppTask{Version: "1", AppSecret: "app secret"})
+	require.NoError(t, err)
+	return clusterId
+}
+
+func TestExportImport(t *testing.T) {
+	source, err := getDB("bundle_source")
+	require.NoError(t, err)
+	clusterId := createContract(t, source, "P0000export")
+	createContract(t, source, "P0000other")
+
+	b, err := bundle.New(source).Export([]string{"P0000export"})
+	require.NoError(t, err)
+	require.Len(t, b.CspInfos, 1)
+	require.Len(t, b.Clusters, 1)
+	require.Equal(t, clusterId, b.Clusters[0].ID)
+	require.Len(t, b.AppGroups, 1)
+	require.Len(t, b.Applications, 1)
+	require.Len(t, b.KeycloakInfos, 1)
+	require.Len(t, b.AppServeApps, 1)
+	require.Len(t, b.AppServeAppTasks, 1)
+
+	all, err := bundle.New(source).Export(nil)
+	require.NoError(t, err)
+	require.Len(t, all.Clusters, 2)
+
+	require.NoError(t, b.EncryptSecrets([]byte("passphrase")))
+	data, err := bundle.Marshal(b, bundle.FormatYAML)
+	require.NoError(t, err)
+	b, err = bundle.Unmarshal(data)
+	require.NoError(t, err)
+	require.NoError(t, b.DecryptSecrets([]byte("passphrase")))
+
+	target, err := getDB("bundle_target")
+	require.NoError(t, err)
+	accessor := bundle.New(target)
+
+	results, err := accessor.Import(b, bundle.ConflictFail, true)
+	require.NoError(t, err)
+	require.Equal(t, bundle.Result{Kind: "clusters", Created: 1}, results[2])
+	var count int64
+	require.NoError(t, target.Model(&modelCluster.Cluster{}).Count(&count).Error)
+	require.Zero(t, count, "dry run imported resources")
+
+	_, err = accessor.Import(b, bundle.ConflictFail, false)
+	require.NoError(t, err)
+	imported, err := cluster.New(target).GetCluster(clusterId)
+	require.NoError(t, err)
+	require.Equal(t, "cluster", imported.GetName())
+	keycloakInfos, err := keycloak_info.New(target).GetKeycloakInfos(clusterId)
+	require.NoError(t, err)
+	require.Equal(t, "secret", keycloakInfos[0].GetSecret())
+
+	// Existing resources fail the import, and nothing is imported.
+	_, err = accessor.Import(b, bundle.ConflictFail, false)
+	var conflictErr *bundle.ConflictError
+	require.True(t, errors.As(err, &conflictErr), err)
+	require.Contains(t, conflictErr.Resources, "clusters/"+clusterId)
+
+	results, err = accessor.Import(b, bundle.ConflictSkip, false)
+	require.NoError(t, err)
+	require.Equal(t, bundle.Result{Kind: "clusters", Skipped: 1}, results[2])
+
+	// Stripped secrets don't overwrite the existing ones.
+	b.Clusters[0].Name = "renamed"
+	require.NoError(t, b.StripSecrets())
+	results, err = accessor.Import(b, bundle.ConflictOverwrite, false)
+	require.NoError(t, err)
+	require.Equal(t, bundle.Result{Kind: "clusters", Updated: 1}, results[2])
+	imported, err = cluster.New(target).GetCluster(clusterId)
+	require.NoError(t, err)
+	require.Equal(t, "renamed", imported.GetName())
+	keycloakInfos, err = keycloak_info.New(target).GetKeycloakInfos(clusterId)
+	require.NoError(t, err)
+	require.Equal(t, "secret", keycloakInfos[0].GetSecret())
+}
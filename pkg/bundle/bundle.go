@@ -0,0 +1,581 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+	"gorm.io/datatypes"
+
+	asaModel "github.com/openinfradev/tks-info/pkg/app_serve_app/model"
+	appModel "github.com/openinfradev/tks-info/pkg/application/model"
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
+	cspModel "github.com/openinfradev/tks-info/pkg/csp_info/model"
+	keycloakModel "github.com/openinfradev/tks-info/pkg/keycloak_info/model"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// Version is the version of the bundle format. Bundles of other versions are not imported.
+const Version = "tks-info/v1"
+
+// Formats of bundle files.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// How secrets are stored in a bundle.
+const (
+	SecretsPlain     = "plain"
+	SecretsStripped  = "stripped"
+	SecretsEncrypted = "encrypted"
+)
+
+// Bundle is every resource of some contracts, to be backed up or moved to another installation of tks-info.
+// Resources keep their IDs. Enums are stored by name so that bundles don't depend on the numbers of tks-proto.
+type Bundle struct {
+	Version    string    `json:"version"`
+	ExportedAt time.Time `json:"exported_at"`
+	// ContractIds are the contracts exported, or empty if all contracts are exported.
+	ContractIds []string `json:"contract_ids,omitempty"`
+	Secrets     string   `json:"secrets"`
+	// Encryption is how secrets are encrypted, if Secrets is SecretsEncrypted.
+	Encryption *Encryption `json:"encryption,omitempty"`
+
+	CspInfos         []CspInfo         `json:"csp_infos"`
+	CspAuths         []CspAuth         `json:"csp_auths"`
+	Clusters         []Cluster         `json:"clusters"`
+	AppGroups        []AppGroup        `json:"app_groups"`
+	Applications     []Application     `json:"applications"`
+	KeycloakInfos    []KeycloakInfo    `json:"keycloak_infos"`
+	AppServeApps     []AppServeApp     `json:"app_serve_apps"`
+	AppServeAppTasks []AppServeAppTask `json:"app_serve_app_tasks"`
+}
+
+type CspInfo struct {
+	ID          uuid.UUID `json:"id"`
+	ContractID  string    `json:"contract_id"`
+	Name        string    `json:"name"`
+	CspType     string    `json:"csp_type"`
+	Auth        string    `json:"auth"`
+	AuthVersion int       `json:"auth_version"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CspAuth struct {
+	ID         uuid.UUID  `json:"id"`
+	CspID      uuid.UUID  `json:"csp_id"`
+	Version    int        `json:"version"`
+	Auth       string     `json:"auth"`
+	RotatedAt  time.Time  `json:"rotated_at"`
+	RotatedBy  string     `json:"rotated_by"`
+	ValidUntil *time.Time `json:"valid_until,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	RevokedBy  string     `json:"revoked_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type Cluster struct {
+	ID           string          `json:"id"`
+	Name         string          `json:"name"`
+	ContractID   string          `json:"contract_id"`
+	CspID        uuid.UUID       `json:"csp_id"`
+	WorkflowID   string          `json:"workflow_id"`
+	Status       string          `json:"status"`
+	StatusDesc   string          `json:"status_desc"`
+	SshKeyName   string          `json:"ssh_key_name"`
+	Region       string          `json:"region"`
+	NumOfAz      int32           `json:"num_of_az"`
+	MachineType  string          `json:"machine_type"`
+	MinSizePerAz int32           `json:"min_size_per_az"`
+	MaxSizePerAz int32           `json:"max_size_per_az"`
+	Kubeconfig   string          `json:"kubeconfig"`
+	Creator      uuid.UUID       `json:"creator"`
+	Description  string          `json:"description"`
+	Labels       json.RawMessage `json:"labels,omitempty"`
+	Annotations  json.RawMessage `json:"annotations,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	UpdatedAt    time.Time       `json:"updated_at"`
+}
+
+type AppGroup struct {
+	ID            string          `json:"id"`
+	Name          string          `json:"name"`
+	Type          string          `json:"type"`
+	WorkflowID    string          `json:"workflow_id"`
+	Status        string          `json:"status"`
+	StatusDesc    string          `json:"status_desc"`
+	ClusterID     string          `json:"cluster_id"`
+	ExternalLabel string          `json:"external_label"`
+	Creator       uuid.UUID       `json:"creator"`
+	Description   string          `json:"description"`
+	Labels        json.RawMessage `json:"labels,omitempty"`
+	Annotations   json.RawMessage `json:"annotations,omitempty"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+type Application struct {
+	ID         uuid.UUID       `json:"id"`
+	AppGroupID string          `json:"app_group_id"`
+	Type       string          `json:"type"`
+	Endpoint   string          `json:"endpoint"`
+	Metadata   json.RawMessage `json:"metadata,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+type KeycloakInfo struct {
+	ID         uuid.UUID `json:"id"`
+	ClusterID  string    `json:"cluster_id"`
+	Realm      string    `json:"realm"`
+	ClientID   string    `json:"client_id"`
+	Secret     string    `json:"secret"`
+	PrivateKey string    `json:"private_key"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type AppServeApp struct {
+	ID                 uuid.UUID       `json:"id"`
+	Name               string          `json:"name"`
+	ContractID         string          `json:"contract_id"`
+	Type               string          `json:"type"`
+	AppType            string          `json:"app_type"`
+	EndpointURL        string          `json:"endpoint_url"`
+	PreviewEndpointURL string          `json:"preview_endpoint_url"`
+	TargetClusterID    string          `json:"target_cluster_id"`
+	Status             string          `json:"status"`
+	Labels             json.RawMessage `json:"labels,omitempty"`
+	Annotations        json.RawMessage `json:"annotations,omitempty"`
+	CreatedAt          time.Time       `json:"created_at"`
+	UpdatedAt          time.Time       `json:"updated_at"`
+}
+
+type AppServeAppTask struct {
+	ID             uuid.UUID `json:"id"`
+	AppServeAppID  uuid.UUID `json:"app_serve_app_id"`
+	Version        string    `json:"version"`
+	Strategy       string    `json:"strategy"`
+	Status         string    `json:"status"`
+	Output         string    `json:"output"`
+	ArtifactURL    string    `json:"artifact_url"`
+	ImageURL       string    `json:"image_url"`
+	ExecutablePath string    `json:"executable_path"`
+	ResourceSpec   string    `json:"resource_spec"`
+	Profile        string    `json:"profile"`
+	AppConfig      string    `json:"app_config"`
+	AppSecret      string    `json:"app_secret"`
+	ExtraEnv       string    `json:"extra_env"`
+	Port           string    `json:"port"`
+	HelmRevision   int32     `json:"helm_revision"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// Marshal returns the bundle in the format, FormatJSON or FormatYAML.
+func Marshal(b *Bundle, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case FormatJSON:
+		return append(data, '\n'), nil
+	case FormatYAML:
+		// Decoding JSON to a node keeps the order of fields.
+		var node yaml.Node
+		if err := yaml.Unmarshal(data, &node); err != nil {
+			return nil, err
+		}
+		blockStyle(&node)
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(&node); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	return nil, fmt.Errorf("unknown bundle format %q", format)
+}
+
+// blockStyle clears the flow style and the quotes of JSON from node, so that it is written as block YAML.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, n := range node.Content {
+		blockStyle(n)
+	}
+}
+
+// Unmarshal reads a bundle in JSON or YAML.
+func Unmarshal(data []byte) (*Bundle, error) {
+	// YAML is a superset of JSON, so both are read as YAML and decoded as JSON.
+	var tree interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	data, err := json.Marshal(tree)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+
+	b := &Bundle{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(b); err != nil {
+		return nil, fmt.Errorf("invalid bundle: %w", err)
+	}
+	if b.Version != Version {
+		return nil, fmt.Errorf("unsupported bundle version %q, expected %q", b.Version, Version)
+	}
+	if !isOneOf(b.Secrets, SecretsPlain, SecretsStripped, SecretsEncrypted) {
+		return nil, fmt.Errorf("invalid secrets %q of bundle", b.Secrets)
+	}
+	if (b.Secrets == SecretsEncrypted) != (b.Encryption != nil) {
+		return nil, fmt.Errorf("encryption of bundle doesn't match secrets %q", b.Secrets)
+	}
+	return b, nil
+}
+
+// secrets returns the fields of the bundle which hold credentials.
+func (b *Bundle) secrets() []*string {
+	secrets := []*string{}
+	for i := range b.CspInfos {
+		secrets = append(secrets, &b.CspInfos[i].Auth)
+	}
+	for i := range b.CspAuths {
+		secrets = append(secrets, &b.CspAuths[i].Auth)
+	}
+	for i := range b.Clusters {
+		secrets = append(secrets, &b.Clusters[i].Kubeconfig)
+	}
+	for i := range b.KeycloakInfos {
+		secrets = append(secrets, &b.KeycloakInfos[i].Secret, &b.KeycloakInfos[i].PrivateKey)
+	}
+	for i := range b.AppServeAppTasks {
+		secrets = append(secrets, &b.AppServeAppTasks[i].AppSecret)
+	}
+	return secrets
+}
+
+func enumName(names map[int32]string, value int32) string {
+	if name, ok := names[value]; ok {
+		return name
+	}
+	return strconv.Itoa(int(value))
+}
+
+func enumValue(values map[string]int32, kind string, name string) (int32, error) {
+	if value, ok := values[name]; ok {
+		return value, nil
+	}
+	if value, err := strconv.ParseInt(name, 10, 32); err == nil {
+		return int32(value), nil
+	}
+	return 0, fmt.Errorf("invalid %s %q", kind, name)
+}
+
+func isOneOf(value string, candidates ...string) bool {
+	for _, c := range candidates {
+		if value == c {
+			return true
+		}
+	}
+	return false
+}
+
+func fromCspInfo(m cspModel.CSPInfo) CspInfo {
+	return CspInfo{
+		ID:          m.ID,
+		ContractID:  m.ContractID,
+		Name:        m.Name,
+		CspType:     enumName(pb.CspType_name, int32(m.CspType)),
+		Auth:        m.Auth,
+		AuthVersion: m.AuthVersion,
+		CreatedAt:   m.CreatedAt,
+		UpdatedAt:   m.UpdatedAt,
+	}
+}
+
+func (r CspInfo) model() (cspModel.CSPInfo, error) {
+	cspType, err := enumValue(pb.CspType_value, "csp type", r.CspType)
+	return cspModel.CSPInfo{
+		ID:          r.ID,
+		ContractID:  r.ContractID,
+		Name:        r.Name,
+		CspType:     pb.CspType(cspType),
+		Auth:        r.Auth,
+		AuthVersion: r.AuthVersion,
+		CreatedAt:   r.CreatedAt,
+		UpdatedAt:   r.UpdatedAt,
+	}, err
+}
+
+func fromCspAuth(m cspModel.CSPAuth) CspAuth {
+	return CspAuth{
+		ID:         m.ID,
+		CspID:      m.CspId,
+		Version:    m.Version,
+		Auth:       m.Auth,
+		RotatedAt:  m.RotatedAt,
+		RotatedBy:  m.RotatedBy,
+		ValidUntil: m.ValidUntil,
+		RevokedAt:  m.RevokedAt,
+		RevokedBy:  m.RevokedBy,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+func (r CspAuth) model() (cspModel.CSPAuth, error) {
+	return cspModel.CSPAuth{
+		ID:         r.ID,
+		CspId:      r.CspID,
+		Version:    r.Version,
+		Auth:       r.Auth,
+		RotatedAt:  r.RotatedAt,
+		RotatedBy:  r.RotatedBy,
+		ValidUntil: r.ValidUntil,
+		RevokedAt:  r.RevokedAt,
+		RevokedBy:  r.RevokedBy,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}, nil
+}
+
+func fromCluster(m clusterModel.Cluster) Cluster {
+	return Cluster{
+		ID:           m.ID,
+		Name:         m.Name,
+		ContractID:   m.ContractID,
+		CspID:        m.CspID,
+		WorkflowID:   m.WorkflowId,
+		Status:       enumName(pb.ClusterStatus_name, int32(m.Status)),
+		StatusDesc:   m.StatusDesc,
+		SshKeyName:   m.SshKeyName,
+		Region:       m.Region,
+		NumOfAz:      m.NumOfAz,
+		MachineType:  m.MachineType,
+		MinSizePerAz: m.MinSizePerAz,
+		MaxSizePerAz: m.MaxSizePerAz,
+		Kubeconfig:   m.Kubeconfig,
+		Creator:      m.Creator,
+		Description:  m.Description,
+		Labels:       json.RawMessage(m.Labels),
+		Annotations:  json.RawMessage(m.Annotations),
+		CreatedAt:    m.CreatedAt,
+		UpdatedAt:    m.UpdatedAt,
+	}
+}
+
+func (r Cluster) model() (clusterModel.Cluster, error) {
+	status, err := enumValue(pb.ClusterStatus_value, "cluster status", r.Status)
+	return clusterModel.Cluster{
+		ID:           r.ID,
+		Name:         r.Name,
+		ContractID:   r.ContractID,
+		CspID:        r.CspID,
+		WorkflowId:   r.WorkflowID,
+		Status:       pb.ClusterStatus(status),
+		StatusDesc:   r.StatusDesc,
+		SshKeyName:   r.SshKeyName,
+		Region:       r.Region,
+		NumOfAz:      r.NumOfAz,
+		MachineType:  r.MachineType,
+		MinSizePerAz: r.MinSizePerAz,
+		MaxSizePerAz: r.MaxSizePerAz,
+		Kubeconfig:   r.Kubeconfig,
+		Creator:      r.Creator,
+		Description:  r.Description,
+		Labels:       datatypes.JSON(r.Labels),
+		Annotations:  datatypes.JSON(r.Annotations),
+		CreatedAt:    r.CreatedAt,
+		UpdatedAt:    r.UpdatedAt,
+	}, err
+}
+
+func fromAppGroup(m appModel.ApplicationGroup) AppGroup {
+	return AppGroup{
+		ID:            m.ID,
+		Name:          m.Name,
+		Type:          enumName(pb.AppGroupType_name, int32(m.Type)),
+		WorkflowID:    m.WorkflowId,
+		Status:        enumName(pb.AppGroupStatus_name, int32(m.Status)),
+		StatusDesc:    m.StatusDesc,
+		ClusterID:     m.ClusterId,
+		ExternalLabel: m.ExternalLabel,
+		Creator:       m.Creator,
+		Description:   m.Description,
+		Labels:        json.RawMessage(m.Labels),
+		Annotations:   json.RawMessage(m.Annotations),
+		CreatedAt:     m.CreatedAt,
+		UpdatedAt:     m.UpdatedAt,
+	}
+}
+
+func (r AppGroup) model() (appModel.ApplicationGroup, error) {
+	appGroupType, err := enumValue(pb.AppGroupType_value, "app group type", r.Type)
+	if err != nil {
+		return appModel.ApplicationGroup{}, err
+	}
+	status, err := enumValue(pb.AppGroupStatus_value, "app group status", r.Status)
+	return appModel.ApplicationGroup{
+		ID:            r.ID,
+		Name:          r.Name,
+		Type:          pb.AppGroupType(appGroupType),
+		WorkflowId:    r.WorkflowID,
+		Status:        pb.AppGroupStatus(status),
+		StatusDesc:    r.StatusDesc,
+		ClusterId:     r.ClusterID,
+		ExternalLabel: r.ExternalLabel,
+		Creator:       r.Creator,
+		Description:   r.Description,
+		Labels:        datatypes.JSON(r.Labels),
+		Annotations:   datatypes.JSON(r.Annotations),
+		CreatedAt:     r.CreatedAt,
+		UpdatedAt:     r.UpdatedAt,
+	}, err
+}
+
+func fromApplication(m appModel.Application) Application {
+	return Application{
+		ID:         m.ID,
+		AppGroupID: m.AppGroupId,
+		Type:       enumName(pb.AppType_name, int32(m.Type)),
+		Endpoint:   m.Endpoint,
+		Metadata:   json.RawMessage(m.Metadata),
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+func (r Application) model() (appModel.Application, error) {
+	appType, err := enumValue(pb.AppType_value, "app type", r.Type)
+	return appModel.Application{
+		ID:         r.ID,
+		AppGroupId: r.AppGroupID,
+		Type:       pb.AppType(appType),
+		Endpoint:   r.Endpoint,
+		Metadata:   datatypes.JSON(r.Metadata),
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}, err
+}
+
+func fromKeycloakInfo(m keycloakModel.KeycloakInfo) KeycloakInfo {
+	return KeycloakInfo{
+		ID:         m.Id,
+		ClusterID:  m.ClusterId,
+		Realm:      m.Realm,
+		ClientID:   m.ClientId,
+		Secret:     m.Secret,
+		PrivateKey: m.PrivateKey,
+		CreatedAt:  m.CreatedAt,
+		UpdatedAt:  m.UpdatedAt,
+	}
+}
+
+func (r KeycloakInfo) model() (keycloakModel.KeycloakInfo, error) {
+	return keycloakModel.KeycloakInfo{
+		Id:         r.ID,
+		ClusterId:  r.ClusterID,
+		Realm:      r.Realm,
+		ClientId:   r.ClientID,
+		Secret:     r.Secret,
+		PrivateKey: r.PrivateKey,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+	}, nil
+}
+
+func fromAppServeApp(m asaModel.AppServeApp) AppServeApp {
+	return AppServeApp{
+		ID:                 m.ID,
+		Name:               m.Name,
+		ContractID:         m.ContractId,
+		Type:               m.Type,
+		AppType:            m.AppType,
+		EndpointURL:        m.EndpointUrl,
+		PreviewEndpointURL: m.PreviewEndpointUrl,
+		TargetClusterID:    m.TargetClusterId,
+		Status:             m.Status,
+		Labels:             json.RawMessage(m.Labels),
+		Annotations:        json.RawMessage(m.Annotations),
+		CreatedAt:          m.CreatedAt,
+		UpdatedAt:          m.UpdatedAt,
+	}
+}
+
+func (r AppServeApp) model() (asaModel.AppServeApp, error) {
+	return asaModel.AppServeApp{
+		ID:                 r.ID,
+		Name:               r.Name,
+		ContractId:         r.ContractID,
+		Type:               r.Type,
+		AppType:            r.AppType,
+		EndpointUrl:        r.EndpointURL,
+		PreviewEndpointUrl: r.PreviewEndpointURL,
+		TargetClusterId:    r.TargetClusterID,
+		Status:             r.Status,
+		Labels:             datatypes.JSON(r.Labels),
+		Annotations:        datatypes.JSON(r.Annotations),
+		CreatedAt:          r.CreatedAt,
+		UpdatedAt:          r.UpdatedAt,
+	}, nil
+}
+
+func fromAppServeAppTask(m asaModel.AppServeAppTask) AppServeAppTask {
+	return AppServeAppTask{
+		ID:             m.ID,
+		AppServeAppID:  m.AppServeAppId,
+		Version:        m.Version,
+		Strategy:       m.Strategy,
+		Status:         m.Status,
+		Output:         m.Output,
+		ArtifactURL:    m.ArtifactUrl,
+		ImageURL:       m.ImageUrl,
+		ExecutablePath: m.ExecutablePath,
+		ResourceSpec:   m.ResourceSpec,
+		Profile:        m.Profile,
+		AppConfig:      m.AppConfig,
+		AppSecret:      m.AppSecret,
+		ExtraEnv:       m.ExtraEnv,
+		Port:           m.Port,
+		HelmRevision:   m.HelmRevision,
+		CreatedAt:      m.CreatedAt,
+		UpdatedAt:      m.UpdatedAt,
+	}
+}
+
+func (r AppServeAppTask) model() (asaModel.AppServeAppTask, error) {
+	return asaModel.AppServeAppTask{
+		ID:             r.ID,
+		AppServeAppId:  r.AppServeAppID,
+		Version:        r.Version,
+		Strategy:       r.Strategy,
+		Status:         r.Status,
+		Output:         r.Output,
+		ArtifactUrl:    r.ArtifactURL,
+		ImageUrl:       r.ImageURL,
+		ExecutablePath: r.ExecutablePath,
+		ResourceSpec:   r.ResourceSpec,
+		Profile:        r.Profile,
+		AppConfig:      r.AppConfig,
+		AppSecret:      r.AppSecret,
+		ExtraEnv:       r.ExtraEnv,
+		Port:           r.Port,
+		HelmRevision:   r.HelmRevision,
+		CreatedAt:      r.CreatedAt,
+		UpdatedAt:      r.UpdatedAt,
+	}, nil
+}
@@ -0,0 +1,110 @@
+package bundle
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBundle() *Bundle {
+	cspId := uuid.New()
+	createdAt := time.Date(2022, 5, 1, 9, 0, 0, 0, time.UTC)
+	return &Bundle{
+		Version:     Version,
+		ExportedAt:  createdAt,
+		ContractIds: []string{"P1234"},
+		Secrets:     SecretsPlain,
+		CspInfos: []CspInfo{
+			{ID: cspId, ContractID: "P1234", Name: "aws", CspType: "AWS", Auth: `{"accessKeyId":"id"}`, CreatedAt: createdAt},
+		},
+		Clusters: []Cluster{
+			{ID: "C1234", ContractID: "P1234", CspID: cspId, Status: "RUNNING", Kubeconfig: "kubeconfig", CreatedAt: createdAt},
+		},
+		Applications: []Application{
+			{ID: uuid.New(), AppGroupID: "A1234", Type: "PROMETHEUS", Metadata: json.RawMessage(`{"port":9090}`)},
+		},
+		KeycloakInfos: []KeycloakInfo{
+			{ID: uuid.New(), ClusterID: "C1234", Secret: "secret", PrivateKey: ""},
+		},
+	}
+}
+
+func TestMarshal(t *testing.T) {
+	for _, format := range []string{FormatJSON, FormatYAML} {
+		b := newTestBundle()
+		data, err := Marshal(b, format)
+		require.NoError(t, err)
+
+		read, err := Unmarshal(data)
+		require.NoError(t, err, format)
+		require.Equal(t, b.Clusters, read.Clusters, format)
+		require.Equal(t, b.CspInfos, read.CspInfos, format)
+		require.JSONEq(t, `{"port":9090}`, string(read.Applications[0].Metadata), format)
+	}
+
+	_, err := Marshal(newTestBundle(), "xml")
+	require.Error(t, err)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"version":"tks-info/v0","secrets":"plain"}`,
+		`{"version":"tks-info/v1","secrets":"unknown"}`,
+		`{"version":"tks-info/v1","secrets":"encrypted"}`,
+		`{"version":"tks-info/v1","secrets":"plain","unknown":[]}`,
+		`[`,
+	} {
+		_, err := Unmarshal([]byte(data))
+		require.Error(t, err, data)
+	}
+}
+
+func TestEnums(t *testing.T) {
+	b := newTestBundle()
+	m, err := b.Clusters[0].model()
+	require.NoError(t, err)
+	require.Equal(t, "RUNNING", fromCluster(m).Status)
+
+	b.Clusters[0].Status = "UNKNOWN_STATUS"
+	_, err = b.tables()
+	require.Error(t, err)
+}
+
+func TestSecrets(t *testing.T) {
+	b := newTestBundle()
+	require.NoError(t, b.EncryptSecrets([]byte("passphrase")))
+	require.Equal(t, SecretsEncrypted, b.Secrets)
+	require.NotContains(t, b.Clusters[0].Kubeconfig, "kubeconfig")
+	require.Empty(t, b.KeycloakInfos[0].PrivateKey)
+	require.Error(t, b.StripSecrets())
+
+	data, err := Marshal(b, FormatYAML)
+	require.NoError(t, err)
+	read, err := Unmarshal(data)
+	require.NoError(t, err)
+
+	encrypted := read.Clusters[0].Kubeconfig
+	require.ErrorIs(t, read.DecryptSecrets([]byte("wrong")), ErrWrongPassphrase)
+	require.Equal(t, encrypted, read.Clusters[0].Kubeconfig)
+
+	require.NoError(t, read.DecryptSecrets([]byte("passphrase")))
+	require.Equal(t, newTestBundle().secretValues(), read.secretValues())
+	require.Nil(t, read.Encryption)
+
+	require.NoError(t, read.StripSecrets())
+	require.Equal(t, SecretsStripped, read.Secrets)
+	for _, s := range read.secrets() {
+		require.Empty(t, *s)
+	}
+}
+
+func (b *Bundle) secretValues() []string {
+	values := []string{}
+	for _, s := range b.secrets() {
+		values = append(values, *s)
+	}
+	return values
+}
@@ -0,0 +1,127 @@
+package bundle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// Parameters of scrypt recommended for interactive use.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// ErrWrongPassphrase is returned when secrets can't be decrypted with the passphrase.
+var ErrWrongPassphrase = errors.New("wrong passphrase of bundle secrets")
+
+// Encryption describes how secrets of a bundle are encrypted. Secrets are encrypted with AES-256-GCM
+// by a key derived from a passphrase with scrypt, and stored as base64 of the nonce and the ciphertext.
+type Encryption struct {
+	Cipher string `json:"cipher"`
+	KDF    string `json:"kdf"`
+	Salt   []byte `json:"salt"`
+	N      int    `json:"n"`
+	R      int    `json:"r"`
+	P      int    `json:"p"`
+}
+
+func (e *Encryption) aead(passphrase []byte) (cipher.AEAD, error) {
+	if e.Cipher != "aes-256-gcm" || e.KDF != "scrypt" {
+		return nil, fmt.Errorf("unsupported encryption %s with %s", e.Cipher, e.KDF)
+	}
+	key, err := scrypt.Key(passphrase, e.Salt, e.N, e.R, e.P, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// StripSecrets clears the secrets of the bundle.
+func (b *Bundle) StripSecrets() error {
+	if b.Secrets != SecretsPlain {
+		return fmt.Errorf("secrets of bundle are already %s", b.Secrets)
+	}
+	for _, s := range b.secrets() {
+		*s = ""
+	}
+	b.Secrets = SecretsStripped
+	return nil
+}
+
+// EncryptSecrets encrypts the secrets of the bundle with the passphrase.
+func (b *Bundle) EncryptSecrets(passphrase []byte) error {
+	if b.Secrets != SecretsPlain {
+		return fmt.Errorf("secrets of bundle are already %s", b.Secrets)
+	}
+	if len(passphrase) == 0 {
+		return errors.New("passphrase must not be empty")
+	}
+
+	e := &Encryption{Cipher: "aes-256-gcm", KDF: "scrypt", Salt: make([]byte, 16), N: scryptN, R: scryptR, P: scryptP}
+	if _, err := rand.Read(e.Salt); err != nil {
+		return err
+	}
+	aead, err := e.aead(passphrase)
+	if err != nil {
+		return err
+	}
+	for _, s := range b.secrets() {
+		if *s == "" {
+			continue
+		}
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		*s = base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, []byte(*s), nil))
+	}
+	b.Secrets = SecretsEncrypted
+	b.Encryption = e
+	return nil
+}
+
+// DecryptSecrets decrypts the secrets of the bundle with the passphrase.
+func (b *Bundle) DecryptSecrets(passphrase []byte) error {
+	if b.Secrets != SecretsEncrypted {
+		return fmt.Errorf("secrets of bundle are %s, not encrypted", b.Secrets)
+	}
+	aead, err := b.Encryption.aead(passphrase)
+	if err != nil {
+		return err
+	}
+
+	secrets := b.secrets()
+	plain := make([]string, len(secrets))
+	for i, s := range secrets {
+		if *s == "" {
+			continue
+		}
+		data, err := base64.StdEncoding.DecodeString(*s)
+		if err != nil || len(data) < aead.NonceSize() {
+			return fmt.Errorf("invalid encrypted secret in bundle")
+		}
+		value, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+		if err != nil {
+			return ErrWrongPassphrase
+		}
+		plain[i] = string(value)
+	}
+	// Secrets are replaced only when all of them are decrypted.
+	for i, s := range secrets {
+		*s = plain[i]
+	}
+	b.Secrets = SecretsPlain
+	b.Encryption = nil
+	return nil
+}
@@ -0,0 +1,37 @@
+package caller
+
+import (
+	"runtime"
+	"strings"
+)
+
+const pkgPrefix = "github.com/openinfradev/tks-info/pkg/"
+
+// instrumentation packages which are never reported as callers.
+var skipped = []string{"caller.", "metrics.", "tracing."}
+
+// Accessor returns the name of the first caller in the packages of tks-info,
+// such as "cluster.(*ClusterAccessor).GetCluster", or "unknown".
+func Accessor() string {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if name := strings.TrimPrefix(frame.Function, pkgPrefix); name != frame.Function && !isSkipped(name) {
+			return name
+		}
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+func isSkipped(name string) bool {
+	for _, prefix := range skipped {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
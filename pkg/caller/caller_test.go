@@ -0,0 +1,13 @@
+package caller_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openinfradev/tks-info/pkg/caller"
+)
+
+func TestAccessor(t *testing.T) {
+	require.Equal(t, "caller_test.TestAccessor", caller.Accessor())
+}
@@ -1,6 +1,8 @@
 package cluster
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	_ "time"
 
@@ -8,8 +10,13 @@ import (
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/gorm"
 
-	_ "github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-common/pkg/log"
+	asaModel "github.com/openinfradev/tks-info/pkg/app_serve_app/model"
+	appModel "github.com/openinfradev/tks-info/pkg/application/model"
 	model "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+	keycloakModel "github.com/openinfradev/tks-info/pkg/keycloak_info/model"
+	"github.com/openinfradev/tks-info/pkg/label"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 )
 
@@ -25,6 +32,13 @@ func New(db *gorm.DB) *ClusterAccessor {
 	}
 }
 
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *ClusterAccessor) WithContext(ctx context.Context) *ClusterAccessor {
+	return &ClusterAccessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
 // Get returns a Cluster if it exists.
 func (x *ClusterAccessor) GetCluster(id string) (*pb.Cluster, error) {
 	var cluster model.Cluster
@@ -79,7 +93,16 @@ func (x *ClusterAccessor) GetClustersByCspID(cspId uuid.UUID) ([]*pb.Cluster, er
 }
 
 // Create creates new cluster with contract ID, csp ID, name.
+// The CSP must exist and belong to the same contract.
 func (x *ClusterAccessor) CreateClusterInfo(contractId string, cspId uuid.UUID, name string, conf *pb.ClusterConf, creator uuid.UUID, description string) (string, error) {
+	cspInfo, err := integrity.GetCSPInfo(x.db, cspId)
+	if err != nil {
+		return "", err
+	}
+	if err := integrity.CheckContract("csp", cspId.String(), contractId, cspInfo.ContractID); err != nil {
+		return "", err
+	}
+
 	cluster := model.Cluster{
 		ContractID:   contractId,
 		CspID:        cspId,
@@ -119,6 +142,217 @@ func (x *ClusterAccessor) UpdateStatus(id string, status pb.ClusterStatus, statu
 	return nil
 }
 
+// UpdateLabels replaces labels and annotations of a cluster. A nil map leaves them as they are,
+// and an empty map removes all of them. The update time of the cluster is kept, as it tracks the status.
+func (x *ClusterAccessor) UpdateLabels(id string, labels map[string]string, annotations map[string]string) error {
+	columns, err := label.Columns(labels, annotations)
+	if err != nil {
+		return err
+	}
+	if len(columns) == 0 {
+		return fmt.Errorf("nothing to update in cluster with id %s", id)
+	}
+
+	res := x.db.Model(&model.Cluster{}).Where("id = ?", id).UpdateColumns(columns)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: cluster %s", integrity.ErrNotFound, id)
+	}
+	return nil
+}
+
+// GetLabels returns labels and annotations of a cluster.
+func (x *ClusterAccessor) GetLabels(id string) (map[string]string, map[string]string, error) {
+	var cluster model.Cluster
+	res := x.db.Select("labels", "annotations").Where("id = ?", id).Limit(1).Find(&cluster)
+	if res.Error != nil {
+		return nil, nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, nil, fmt.Errorf("%w: cluster %s", integrity.ErrNotFound, id)
+	}
+
+	labels, err := label.Unmarshal(cluster.Labels)
+	if err != nil {
+		return nil, nil, err
+	}
+	annotations, err := label.Unmarshal(cluster.Annotations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return labels, annotations, nil
+}
+
+// GetClustersBySelector returns clusters of a contract whose labels match the selector.
+func (x *ClusterAccessor) GetClustersBySelector(contractId string, selector label.Selector) ([]*pb.Cluster, error) {
+	var clusters []model.Cluster
+	res := selector.Where(x.db, "labels").Where("contract_id = ?", contractId).Order("created_at").Find(&clusters)
+	if res.Error != nil {
+		return nil, fmt.Errorf("Error while finding clusters with contractID %s and selector %s: %w", contractId, selector, res.Error)
+	}
+
+	pbClusters := []*pb.Cluster{}
+	for _, cluster := range clusters {
+		pbClusters = append(pbClusters, ConvertToPbCluster(cluster))
+	}
+	return pbClusters, nil
+}
+
+// DeletePolicy decides what happens to the resources which depend on a deleted cluster.
+type DeletePolicy int
+
+const (
+	// DeleteRefuse refuses to delete a cluster which still has dependents.
+	DeleteRefuse DeletePolicy = iota
+	// DeleteCascade deletes the dependents together with the cluster.
+	DeleteCascade
+	// DeleteOrphan keeps the dependents but clears their reference to the cluster.
+	DeleteOrphan
+)
+
+// Dependents are the resources which refer to a cluster.
+type Dependents struct {
+	AppGroupIds     []string
+	KeycloakInfoIds []string
+	AppServeAppIds  []string
+}
+
+// Empty reports whether there is no dependent.
+func (d *Dependents) Empty() bool {
+	return len(d.AppGroupIds) == 0 && len(d.KeycloakInfoIds) == 0 && len(d.AppServeAppIds) == 0
+}
+
+// ErrHasDependents is returned when a cluster which still has dependents is deleted with DeleteRefuse.
+var ErrHasDependents = errors.New("cluster has dependents")
+
+// DependentsError lists the dependents which block deleting a cluster.
+type DependentsError struct {
+	ClusterId  string
+	Dependents *Dependents
+}
+
+func (e *DependentsError) Error() string {
+	return fmt.Sprintf("%s: cluster %s is still referred by application groups %v, keycloak infos %v, appServeApps %v",
+		ErrHasDependents, e.ClusterId, e.Dependents.AppGroupIds, e.Dependents.KeycloakInfoIds, e.Dependents.AppServeAppIds)
+}
+
+func (e *DependentsError) Unwrap() error {
+	return ErrHasDependents
+}
+
+// GetDependents returns the resources which refer to the cluster.
+func (x *ClusterAccessor) GetDependents(id string) (*Dependents, error) {
+	return getDependents(x.db, id)
+}
+
+// DeleteCluster deletes the cluster and handles its dependents according to the policy.
+// Everything is done in a single transaction. The handled dependents are returned.
+func (x *ClusterAccessor) DeleteCluster(id string, policy DeletePolicy) (*Dependents, error) {
+	var dependents *Dependents
+	err := x.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if dependents, err = getDependents(tx, id); err != nil {
+			return err
+		}
+
+		if !dependents.Empty() {
+			switch policy {
+			case DeleteRefuse:
+				return &DependentsError{ClusterId: id, Dependents: dependents}
+			case DeleteCascade:
+				err = deleteDependents(tx, dependents)
+			case DeleteOrphan:
+				err = orphanDependents(tx, id)
+			default:
+				err = fmt.Errorf("unknown delete policy %d", policy)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		res := tx.Delete(&model.Cluster{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%w: cluster %s", integrity.ErrNotFound, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("cluster id ", id, " is deleted!")
+	return dependents, nil
+}
+
+func getDependents(db *gorm.DB, id string) (*Dependents, error) {
+	dependents := &Dependents{
+		AppGroupIds:     []string{},
+		KeycloakInfoIds: []string{},
+		AppServeAppIds:  []string{},
+	}
+
+	if res := db.Model(&appModel.ApplicationGroup{}).Where("cluster_id = ?", id).
+		Pluck("id", &dependents.AppGroupIds); res.Error != nil {
+		return nil, res.Error
+	}
+	if res := db.Model(&keycloakModel.KeycloakInfo{}).Where("cluster_id = ?", id).
+		Pluck("id", &dependents.KeycloakInfoIds); res.Error != nil {
+		return nil, res.Error
+	}
+	if res := db.Model(&asaModel.AppServeApp{}).Where("target_cluster_id = ?", id).
+		Pluck("id", &dependents.AppServeAppIds); res.Error != nil {
+		return nil, res.Error
+	}
+	return dependents, nil
+}
+
+func deleteDependents(tx *gorm.DB, dependents *Dependents) error {
+	if len(dependents.AppGroupIds) > 0 {
+		if res := tx.Delete(&appModel.Application{}, "app_group_id IN ?", dependents.AppGroupIds); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Delete(&appModel.ApplicationGroup{}, "id IN ?", dependents.AppGroupIds); res.Error != nil {
+			return res.Error
+		}
+	}
+	if len(dependents.KeycloakInfoIds) > 0 {
+		if res := tx.Delete(&keycloakModel.KeycloakInfo{}, "id IN ?", dependents.KeycloakInfoIds); res.Error != nil {
+			return res.Error
+		}
+	}
+	if len(dependents.AppServeAppIds) > 0 {
+		if res := tx.Delete(&asaModel.AppServeAppTask{}, "app_serve_app_id IN ?", dependents.AppServeAppIds); res.Error != nil {
+			return res.Error
+		}
+		if res := tx.Delete(&asaModel.AppServeApp{}, "id IN ?", dependents.AppServeAppIds); res.Error != nil {
+			return res.Error
+		}
+	}
+	return nil
+}
+
+func orphanDependents(tx *gorm.DB, id string) error {
+	if res := tx.Model(&appModel.ApplicationGroup{}).Where("cluster_id = ?", id).
+		Update("cluster_id", nil); res.Error != nil {
+		return res.Error
+	}
+	if res := tx.Model(&keycloakModel.KeycloakInfo{}).Where("cluster_id = ?", id).
+		Update("cluster_id", nil); res.Error != nil {
+		return res.Error
+	}
+	if res := tx.Model(&asaModel.AppServeApp{}).Where("target_cluster_id = ?", id).
+		Update("target_cluster_id", ""); res.Error != nil {
+		return res.Error
+	}
+	return nil
+}
+
 func ConvertToPbCluster(cluster model.Cluster) *pb.Cluster {
 	tempConf := pb.ClusterConf{
 		SshKeyName:   cluster.SshKeyName,
@@ -14,8 +14,14 @@ import (
 	"github.com/openinfradev/tks-common/pkg/helper"
 	"github.com/openinfradev/tks-common/pkg/log"
 
+	asaModel "github.com/openinfradev/tks-info/pkg/app_serve_app/model"
+	appModel "github.com/openinfradev/tks-info/pkg/application/model"
 	"github.com/openinfradev/tks-info/pkg/cluster"
 	"github.com/openinfradev/tks-info/pkg/cluster/model"
+	cspModel "github.com/openinfradev/tks-info/pkg/csp_info/model"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+	keycloakModel "github.com/openinfradev/tks-info/pkg/keycloak_info/model"
+	"github.com/openinfradev/tks-info/pkg/label"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 )
 
@@ -28,6 +34,7 @@ var (
 )
 
 var (
+	testDB     *gorm.DB
 	testDBHost string
 	testDBPort string
 	err        error
@@ -35,7 +42,6 @@ var (
 
 func init() {
 	contractId = helper.GenerateContractId()
-	cspId = uuid.New()
 	clusterName = "testCluster"
 
 	log.Disable()
@@ -52,9 +58,19 @@ func getAccessor() (*cluster.ClusterAccessor, error) {
 
 	db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
 
-	if err := db.AutoMigrate(&model.Cluster{}); err != nil {
+	if err := db.AutoMigrate(&model.Cluster{}, &cspModel.CSPInfo{},
+		&appModel.ApplicationGroup{}, &appModel.Application{}, &keycloakModel.KeycloakInfo{},
+		&asaModel.AppServeApp{}, &asaModel.AppServeAppTask{}); err != nil {
 		return nil, err
 	}
+	testDB = db
+
+	// Clusters can only be created on an existing CSP.
+	cspInfo := cspModel.CSPInfo{ContractID: contractId, Name: "testCsp"}
+	if res := db.Create(&cspInfo); res.Error != nil {
+		return nil, res.Error
+	}
+	cspId = cspInfo.ID
 
 	return cluster.New(db), nil
 }
@@ -96,6 +112,14 @@ func TestCreateClusterInfo(t *testing.T) {
 	t.Logf("Created clusterID: %s", clusterId)
 }
 
+func TestCreateClusterInfoWithInvalidCsp(t *testing.T) {
+	_, err := clusterAccessor.CreateClusterInfo(contractId, uuid.New(), clusterName, &pb.ClusterConf{}, uuid.Nil, "")
+	assert.ErrorIs(t, err, integrity.ErrNotFound)
+
+	_, err = clusterAccessor.CreateClusterInfo(helper.GenerateContractId(), cspId, clusterName, &pb.ClusterConf{}, uuid.Nil, "")
+	assert.ErrorIs(t, err, integrity.ErrContractMismatch)
+}
+
 func TestGetCluster(t *testing.T) {
 	cluster, err := clusterAccessor.GetCluster(clusterId)
 	if err != nil {
@@ -123,3 +147,103 @@ func TestUpdateStatus(t *testing.T) {
 		t.Errorf("An error occurred while updating cluster status. Err: %s", err)
 	}
 }
+
+func TestUpdateLabels(t *testing.T) {
+	id, err := clusterAccessor.CreateClusterInfo(contractId, cspId, clusterName, &pb.ClusterConf{}, uuid.Nil, "")
+	assert.NoError(t, err)
+	before, err := clusterAccessor.GetCluster(id)
+	assert.NoError(t, err)
+
+	assert.NoError(t, clusterAccessor.UpdateLabels(id, map[string]string{"env": "labeled"}, map[string]string{"note": "any text"}))
+	assert.NoError(t, clusterAccessor.UpdateLabels(id, map[string]string{"env": "labeled", "tier": "web"}, nil))
+	labels, annotations, err := clusterAccessor.GetLabels(id)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "labeled", "tier": "web"}, labels)
+	assert.Equal(t, map[string]string{"note": "any text"}, annotations)
+
+	after, err := clusterAccessor.GetCluster(id)
+	assert.NoError(t, err)
+	assert.Equal(t, before.UpdatedAt.AsTime(), after.UpdatedAt.AsTime())
+
+	selector, err := label.ParseSelector("env=labeled,tier in (web,api)")
+	assert.NoError(t, err)
+	clusters, err := clusterAccessor.GetClustersBySelector(contractId, selector)
+	assert.NoError(t, err)
+	if assert.Len(t, clusters, 1) {
+		assert.Equal(t, id, clusters[0].Id)
+	}
+
+	assert.NoError(t, clusterAccessor.UpdateLabels(id, map[string]string{}, nil))
+	labels, _, err = clusterAccessor.GetLabels(id)
+	assert.NoError(t, err)
+	assert.Empty(t, labels)
+
+	assert.Error(t, clusterAccessor.UpdateLabels(id, map[string]string{"env": "not valid"}, nil))
+	assert.ErrorIs(t, clusterAccessor.UpdateLabels("C0000none", map[string]string{"env": "prod"}, nil), integrity.ErrNotFound)
+}
+
+func TestDeleteCluster(t *testing.T) {
+	// createClusterWithDependents creates a cluster which has an app group,
+	// a keycloak info and an appServeApp.
+	createClusterWithDependents := func() (string, string) {
+		id, err := clusterAccessor.CreateClusterInfo(contractId, cspId, clusterName, &pb.ClusterConf{}, uuid.Nil, "")
+		assert.NoError(t, err)
+
+		appGroup := appModel.ApplicationGroup{ClusterId: id}
+		assert.NoError(t, testDB.Create(&appGroup).Error)
+		assert.NoError(t, testDB.Create(&appModel.Application{AppGroupId: appGroup.ID, Metadata: []byte("{}")}).Error)
+		assert.NoError(t, testDB.Create(&keycloakModel.KeycloakInfo{ClusterId: id}).Error)
+		asa := asaModel.AppServeApp{ContractId: contractId, TargetClusterId: id}
+		assert.NoError(t, testDB.Create(&asa).Error)
+		assert.NoError(t, testDB.Create(&asaModel.AppServeAppTask{AppServeAppId: asa.ID}).Error)
+		return id, appGroup.ID
+	}
+
+	t.Run("REFUSE", func(t *testing.T) {
+		id, appGroupId := createClusterWithDependents()
+
+		_, err := clusterAccessor.DeleteCluster(id, cluster.DeleteRefuse)
+		assert.ErrorIs(t, err, cluster.ErrHasDependents)
+		assert.Contains(t, err.Error(), appGroupId)
+
+		_, err = clusterAccessor.GetCluster(id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("CASCADE", func(t *testing.T) {
+		id, appGroupId := createClusterWithDependents()
+
+		dependents, err := clusterAccessor.DeleteCluster(id, cluster.DeleteCascade)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{appGroupId}, dependents.AppGroupIds)
+
+		_, err = clusterAccessor.GetCluster(id)
+		assert.Error(t, err)
+
+		var count int64
+		testDB.Model(&appModel.Application{}).Where("app_group_id = ?", appGroupId).Count(&count)
+		assert.Equal(t, int64(0), count)
+		testDB.Model(&asaModel.AppServeApp{}).Where("target_cluster_id = ?", id).Count(&count)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("ORPHAN", func(t *testing.T) {
+		id, appGroupId := createClusterWithDependents()
+
+		_, err := clusterAccessor.DeleteCluster(id, cluster.DeleteOrphan)
+		assert.NoError(t, err)
+
+		var appGroup appModel.ApplicationGroup
+		assert.NoError(t, testDB.First(&appGroup, "id = ?", appGroupId).Error)
+		assert.Equal(t, "", appGroup.ClusterId)
+
+		dependents, err := clusterAccessor.GetDependents(id)
+		assert.NoError(t, err)
+		assert.True(t, dependents.Empty())
+	})
+
+	t.Run("NOT_FOUND", func(t *testing.T) {
+		_, err := clusterAccessor.DeleteCluster(helper.GenerateClusterId(), cluster.DeleteRefuse)
+		assert.ErrorIs(t, err, integrity.ErrNotFound)
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/openinfradev/tks-common/pkg/helper"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -27,8 +28,11 @@ type Cluster struct {
 	Kubeconfig   string
 	Creator      uuid.UUID
 	Description  string
-	UpdatedAt    time.Time
-	CreatedAt    time.Time
+	// Labels and Annotations are JSON objects of string values, which are NULL if empty.
+	Labels      datatypes.JSON
+	Annotations datatypes.JSON
+	UpdatedAt   time.Time
+	CreatedAt   time.Time
 }
 
 func (c *Cluster) BeforeCreate(tx *gorm.DB) (err error) {
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// fileSuffix marks a key whose value is read from the file it points to,
+// so that secrets don't have to be written in the config file or the environment.
+const fileSuffix = "-file"
+
+// Load sets the flags of fs which were not given on the command line.
+// A value is taken from, in order of precedence,
+//   - the environment variable named after the flag with prefix, such as TKS_INFO_DB_NAME for "db-name"
+//   - the config file at path, if path is not empty
+//
+// and the default value of the flag is kept otherwise.
+// Keys of the config file are flag names, and nested keys are joined with "-",
+// so "db: {name: tks}" sets "db-name". Any key or variable with the suffix
+// "-file" (or "_FILE") sets the flag to the content of the file it points to.
+func Load(fs *flag.FlagSet, path string, prefix string) error {
+	setOnCommandLine := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		setOnCommandLine[f.Name] = true
+	})
+
+	values := map[string]string{}
+	if path != "" {
+		fileValues, err := readFile(path)
+		if err != nil {
+			return err
+		}
+		if err := resolve(fs, fileValues, values, "config file "+path); err != nil {
+			return err
+		}
+	}
+	if err := resolve(fs, readEnv(fs, prefix), values, "environment"); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if setOnCommandLine[name] {
+			continue
+		}
+		if err := fs.Set(name, values[name]); err != nil {
+			return fmt.Errorf("invalid value %q for %s: %w", values[name], name, err)
+		}
+	}
+	return nil
+}
+
+// EnvName returns the environment variable for the flag name.
+func EnvName(prefix string, name string) string {
+	return prefix + "_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// resolve moves raw into values, reading the files of keys with fileSuffix.
+func resolve(fs *flag.FlagSet, raw map[string]string, values map[string]string, source string) error {
+	for key, value := range raw {
+		if fs.Lookup(key) != nil {
+			values[key] = value
+			continue
+		}
+		name := strings.TrimSuffix(key, fileSuffix)
+		if name == key || fs.Lookup(name) == nil {
+			return fmt.Errorf("unknown option %q in %s", key, source)
+		}
+		if _, ok := raw[name]; ok {
+			return fmt.Errorf("both %s and %s are set in %s", name, key, source)
+		}
+		b, err := os.ReadFile(value)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from %s: %w", name, source, err)
+		}
+		values[name] = strings.TrimRight(string(b), "\r\n")
+	}
+	return nil
+}
+
+func readEnv(fs *flag.FlagSet, prefix string) map[string]string {
+	values := map[string]string{}
+	fs.VisitAll(func(f *flag.Flag) {
+		if value, ok := os.LookupEnv(EnvName(prefix, f.Name)); ok {
+			values[f.Name] = value
+		}
+		if value, ok := os.LookupEnv(EnvName(prefix, f.Name+fileSuffix)); ok {
+			values[f.Name+fileSuffix] = value
+		}
+	})
+	return values
+}
+
+func readFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := map[string]interface{}{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &tree)
+	case ".toml":
+		err = toml.Unmarshal(b, &tree)
+	default:
+		return nil, fmt.Errorf("unsupported config file format %q", ext)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", tree, values)
+	return values, nil
+}
+
+func flatten(prefix string, tree map[string]interface{}, values map[string]string) {
+	for key, value := range tree {
+		key = strings.ReplaceAll(strings.ToLower(key), "_", "-")
+		if prefix != "" {
+			key = prefix + "-" + key
+		}
+		if sub, ok := value.(map[string]interface{}); ok {
+			flatten(key, sub, values)
+			continue
+		}
+		values[key] = fmt.Sprint(value)
+	}
+}
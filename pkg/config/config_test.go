@@ -0,0 +1,131 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openinfradev/tks-info/pkg/config"
+)
+
+type options struct {
+	port     int
+	dbhost   string
+	password string
+	dbName   string
+	timeout  time.Duration
+}
+
+func newFlagSet(o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.IntVar(&o.port, "port", 9111, "")
+	fs.StringVar(&o.dbhost, "dbhost", "localhost", "")
+	fs.StringVar(&o.password, "dbpassword", "password", "")
+	fs.StringVar(&o.dbName, "db-name", "tks", "")
+	fs.DurationVar(&o.timeout, "db-connect-timeout", 10*time.Second, "")
+	return fs
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestLoadYAML(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+port: 9000
+dbhost: postgres
+db:
+  name: info
+  connect_timeout: 3s
+`)
+	o := options{}
+	fs := newFlagSet(&o)
+	require.NoError(t, fs.Parse(nil))
+	require.NoError(t, config.Load(fs, path, "TEST"))
+
+	require.Equal(t, 9000, o.port)
+	require.Equal(t, "postgres", o.dbhost)
+	require.Equal(t, "info", o.dbName)
+	require.Equal(t, 3*time.Second, o.timeout)
+	require.Equal(t, "password", o.password)
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+port = 9000
+
+[db]
+name = "info"
+`)
+	o := options{}
+	fs := newFlagSet(&o)
+	require.NoError(t, fs.Parse(nil))
+	require.NoError(t, config.Load(fs, path, "TEST"))
+
+	require.Equal(t, 9000, o.port)
+	require.Equal(t, "info", o.dbName)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+port: 9000
+dbhost: from-file
+db-name: from-file
+`)
+	t.Setenv("TEST_DBHOST", "from-env")
+	t.Setenv("TEST_DB_NAME", "from-env")
+
+	o := options{}
+	fs := newFlagSet(&o)
+	require.NoError(t, fs.Parse([]string{"-db-name", "from-flag"}))
+	require.NoError(t, config.Load(fs, path, "TEST"))
+
+	require.Equal(t, 9000, o.port)
+	require.Equal(t, "from-env", o.dbhost)
+	require.Equal(t, "from-flag", o.dbName)
+}
+
+func TestLoadSecretFile(t *testing.T) {
+	secret := writeFile(t, "password", "s3cret\n")
+
+	o := options{}
+	fs := newFlagSet(&o)
+	require.NoError(t, fs.Parse(nil))
+	require.NoError(t, config.Load(fs, writeFile(t, "config.yaml", "dbpassword-file: "+secret+"\n"), "TEST"))
+	require.Equal(t, "s3cret", o.password)
+
+	o = options{}
+	fs = newFlagSet(&o)
+	require.NoError(t, fs.Parse(nil))
+	t.Setenv("TEST_DBPASSWORD_FILE", secret)
+	require.NoError(t, config.Load(fs, "", "TEST"))
+	require.Equal(t, "s3cret", o.password)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown key":   "unknown: 1\n",
+		"invalid value": "port: abc\n",
+		"both set":      "dbpassword: a\ndbpassword-file: /tmp/password\n",
+		"missing file":  "dbpassword-file: /nonexistent\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := options{}
+			fs := newFlagSet(&o)
+			require.NoError(t, fs.Parse(nil))
+			require.Error(t, config.Load(fs, writeFile(t, "config.yaml", content), "TEST"))
+		})
+	}
+
+	o := options{}
+	fs := newFlagSet(&o)
+	require.NoError(t, fs.Parse(nil))
+	require.Error(t, config.Load(fs, writeFile(t, "config.json", "{}"), "TEST"))
+}
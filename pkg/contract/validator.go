@@ -0,0 +1,114 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+var (
+	// ErrNotFound is returned when tks-contract does not know the contract.
+	ErrNotFound = errors.New("contract not found")
+	// ErrUnavailable is returned when tks-contract could not be reached
+	// and the validator is configured to fail closed.
+	ErrUnavailable = errors.New("contract service unavailable")
+)
+
+// Validator checks whether a contract exists in tks-contract.
+// Contracts found to exist are cached for the configured TTL.
+type Validator struct {
+	client   pb.ContractServiceClient
+	ttl      time.Duration
+	failOpen bool
+
+	mu    sync.Mutex
+	cache map[string]time.Time
+	now   func() time.Time
+}
+
+// NewValidator returns new Validator which uses the client to look up contracts.
+// If failOpen is true, contracts are accepted when tks-contract is unreachable.
+func NewValidator(client pb.ContractServiceClient, ttl time.Duration, failOpen bool) *Validator {
+	return &Validator{
+		client:   client,
+		ttl:      ttl,
+		failOpen: failOpen,
+		cache:    map[string]time.Time{},
+		now:      time.Now,
+	}
+}
+
+// Validate returns nil if the contract exists.
+func (v *Validator) Validate(ctx context.Context, contractId string) error {
+	if v.cached(contractId) {
+		return nil
+	}
+
+	res, err := v.client.GetContract(ctx, &pb.GetContractRequest{ContractId: contractId})
+	if err != nil {
+		if isUnreachable(err) {
+			return v.unreachable(contractId, err)
+		}
+		return fmt.Errorf("%w: %s", ErrNotFound, contractId)
+	}
+
+	switch res.GetCode() {
+	case pb.Code_OK_UNSPECIFIED:
+		if res.GetContract().GetContractId() != contractId {
+			return fmt.Errorf("%w: %s", ErrNotFound, contractId)
+		}
+	case pb.Code_NOT_FOUND:
+		return fmt.Errorf("%w: %s", ErrNotFound, contractId)
+	default:
+		return v.unreachable(contractId, fmt.Errorf("unexpected response code %s", res.GetCode()))
+	}
+
+	v.mu.Lock()
+	v.cache[contractId] = v.now().Add(v.ttl)
+	v.mu.Unlock()
+	return nil
+}
+
+func (v *Validator) cached(contractId string) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	expiresAt, ok := v.cache[contractId]
+	if !ok {
+		return false
+	}
+	if v.now().After(expiresAt) {
+		delete(v.cache, contractId)
+		return false
+	}
+	return true
+}
+
+func (v *Validator) unreachable(contractId string, err error) error {
+	if v.failOpen {
+		log.Warn("Failed to check contract ", contractId, " on tks-contract. Accepting it anyway. err : ", err)
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrUnavailable, err)
+}
+
+// isUnreachable reports whether err means tks-contract could not answer,
+// as opposed to answering that the contract does not exist.
+// tks-contract returns a plain error along with its response code, which arrives as Unknown,
+// so only transport failures count as unreachable.
+func isUnreachable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Canceled:
+		return true
+	default:
+		return false
+	}
+}
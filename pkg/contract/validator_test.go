@@ -0,0 +1,172 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+	mocktks "github.com/openinfradev/tks-proto/tks_pb/mock"
+)
+
+func init() {
+	log.Disable()
+}
+
+func TestValidate(t *testing.T) {
+	contractId := helper.GenerateContractId()
+
+	testCases := []struct {
+		name          string
+		failOpen      bool
+		buildStubs    func(mockContractClient *mocktks.MockContractServiceClient)
+		checkResponse func(err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(mockContractClient *mocktks.MockContractServiceClient) {
+				mockContractClient.EXPECT().GetContract(gomock.Any(), gomock.Any()).Times(1).
+					Return(&pb.GetContractResponse{
+						Code:     pb.Code_OK_UNSPECIFIED,
+						Contract: &pb.Contract{ContractId: contractId},
+					}, nil)
+			},
+			checkResponse: func(err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "NOT_FOUND",
+			buildStubs: func(mockContractClient *mocktks.MockContractServiceClient) {
+				mockContractClient.EXPECT().GetContract(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, status.Error(codes.NotFound, "could not find contract"))
+			},
+			checkResponse: func(err error) {
+				require.ErrorIs(t, err, ErrNotFound)
+			},
+		},
+		{
+			name: "NOT_FOUND_CODE",
+			buildStubs: func(mockContractClient *mocktks.MockContractServiceClient) {
+				mockContractClient.EXPECT().GetContract(gomock.Any(), gomock.Any()).Times(1).
+					Return(&pb.GetContractResponse{Code: pb.Code_NOT_FOUND}, nil)
+			},
+			checkResponse: func(err error) {
+				require.ErrorIs(t, err, ErrNotFound)
+			},
+		},
+		{
+			name: "UNAVAILABLE_FAIL_CLOSED",
+			buildStubs: func(mockContractClient *mocktks.MockContractServiceClient) {
+				mockContractClient.EXPECT().GetContract(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, status.Error(codes.Unavailable, "connection refused"))
+			},
+			checkResponse: func(err error) {
+				require.ErrorIs(t, err, ErrUnavailable)
+			},
+		},
+		{
+			name:     "UNKNOWN_FAIL_OPEN",
+			failOpen: true,
+			buildStubs: func(mockContractClient *mocktks.MockContractServiceClient) {
+				mockContractClient.EXPECT().GetContract(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, errors.New("could not find contract"))
+			},
+			checkResponse: func(err error) {
+				require.ErrorIs(t, err, ErrNotFound)
+			},
+		},
+		{
+			name:     "UNAVAILABLE_FAIL_OPEN",
+			failOpen: true,
+			buildStubs: func(mockContractClient *mocktks.MockContractServiceClient) {
+				mockContractClient.EXPECT().GetContract(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, status.Error(codes.DeadlineExceeded, "timeout"))
+			},
+			checkResponse: func(err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockContractClient := mocktks.NewMockContractServiceClient(ctrl)
+			tc.buildStubs(mockContractClient)
+
+			v := NewValidator(mockContractClient, time.Minute, tc.failOpen)
+			tc.checkResponse(v.Validate(context.Background(), contractId))
+		})
+	}
+}
+
+func TestValidateCache(t *testing.T) {
+	contractId := helper.GenerateContractId()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockContractClient := mocktks.NewMockContractServiceClient(ctrl)
+	mockContractClient.EXPECT().GetContract(gomock.Any(), gomock.Any()).Times(2).
+		Return(&pb.GetContractResponse{
+			Code:     pb.Code_OK_UNSPECIFIED,
+			Contract: &pb.Contract{ContractId: contractId},
+		}, nil)
+
+	now := time.Now()
+	v := NewValidator(mockContractClient, time.Minute, false)
+	v.now = func() time.Time { return now }
+
+	// The second call is served from the cache.
+	require.NoError(t, v.Validate(context.Background(), contractId))
+	require.NoError(t, v.Validate(context.Background(), contractId))
+
+	// The cached entry expires after the TTL.
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, v.Validate(context.Background(), contractId))
+}
+
+// contractServer answers like tks-contract, with a response code and a plain error.
+type contractServer struct {
+	pb.UnimplementedContractServiceServer
+}
+
+func (s *contractServer) GetContract(ctx context.Context, in *pb.GetContractRequest) (*pb.GetContractResponse, error) {
+	return &pb.GetContractResponse{
+		Code:  pb.Code_NOT_FOUND,
+		Error: &pb.Error{Msg: "could not find contract"},
+	}, errors.New("could not find contract")
+}
+
+func TestValidateOverGRPC(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	pb.RegisterContractServiceServer(server, &contractServer{})
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithInsecure())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	v := NewValidator(pb.NewContractServiceClient(conn), time.Minute, true)
+	require.ErrorIs(t, v.Validate(context.Background(), helper.GenerateContractId()), ErrNotFound)
+
+	// Contracts are accepted only when tks-contract can't be reached.
+	server.Stop()
+	require.NoError(t, v.Validate(context.Background(), helper.GenerateContractId()))
+}
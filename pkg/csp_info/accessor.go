@@ -1,15 +1,28 @@
 package csp_info
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	uuid "github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
 	model "github.com/openinfradev/tks-info/pkg/csp_info/model"
+	"github.com/openinfradev/tks-info/pkg/integrity"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 )
 
+var (
+	// ErrNoPreviousAuth is returned when there is no auth version to roll back to.
+	ErrNoPreviousAuth = errors.New("no previous auth to roll back to")
+	// ErrInvalidAuthVersion is returned when an auth version is neither active nor in its overlap window.
+	ErrInvalidAuthVersion = errors.New("auth version is not valid")
+)
+
 // Accessor accesses to csp info in-memory data.
 type CspInfoAccessor struct {
 	db *gorm.DB
@@ -22,6 +35,13 @@ func New(db *gorm.DB) *CspInfoAccessor {
 	}
 }
 
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *CspInfoAccessor) WithContext(ctx context.Context) *CspInfoAccessor {
+	return &CspInfoAccessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
 // Get returns a CSP Info if it exists.
 func (x *CspInfoAccessor) GetCSPInfo(id uuid.UUID) (model.CSPInfo, error) {
 	var cspInfo model.CSPInfo
@@ -50,28 +70,277 @@ func (x *CspInfoAccessor) GetCSPIDsByContractID(contractId string) ([]string, er
 	return idArr, nil
 }
 
+// GetCSPInfosByContractID returns CSP infos which belong to the contract.
+func (x *CspInfoAccessor) GetCSPInfosByContractID(contractId string) ([]model.CSPInfo, error) {
+	var cspInfos []model.CSPInfo
+
+	res := x.db.Order("created_at").Find(&cspInfos, "contract_id = ?", contractId)
+	if res.Error != nil {
+		return nil, fmt.Errorf("Error while finding CSPInfo with contract ID: %s", contractId)
+	}
+
+	return cspInfos, nil
+}
+
 // Create creates new CSP info with contractID and auth.
+// A non-empty auth is stored as the first version of the auth history.
 func (x *CspInfoAccessor) Create(contractId string, name string, auth string, cspType pb.CspType) (uuid.UUID, error) {
 	cspInfo := model.CSPInfo{ContractID: contractId, Name: name, Auth: auth, CspType: cspType}
 
-	res := x.db.Create(&cspInfo)
-	if res.Error != nil {
+	err := x.db.Transaction(func(tx *gorm.DB) error {
+		if res := tx.Create(&cspInfo); res.Error != nil {
+			return res.Error
+		}
+		return seedAuthHistory(tx, &cspInfo)
+	})
+	if err != nil {
 		nilId, _ := uuid.Parse("")
-		return nilId, res.Error
+		return nilId, err
 	}
 
 	return cspInfo.ID, nil
 }
 
-// Update updates an authentication info for CSP.
-func (x *CspInfoAccessor) UpdateCSPAuth(id uuid.UUID, auth string) error {
+// UpdateName renames the CSP.
+func (x *CspInfoAccessor) UpdateName(id uuid.UUID, name string) error {
 	res := x.db.Model(&model.CSPInfo{}).
 		Where("ID = ?", id).
-		Update("Auth", auth)
+		Update("Name", name)
+
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: csp %s", integrity.ErrNotFound, id.String())
+	}
+
+	return nil
+}
+
+// Delete deletes the CSP info. It refuses to delete a CSP which clusters are still created on.
+func (x *CspInfoAccessor) Delete(id uuid.UUID) error {
+	return x.db.Transaction(func(tx *gorm.DB) error {
+		var clusterIds []string
+		if res := tx.Model(&clusterModel.Cluster{}).Where("csp_id = ?", id).Pluck("id", &clusterIds); res.Error != nil {
+			return res.Error
+		}
+		if len(clusterIds) > 0 {
+			return fmt.Errorf("%w: csp %s is still used by clusters %v", integrity.ErrInUse, id.String(), clusterIds)
+		}
+
+		if res := tx.Delete(&model.CSPAuth{}, "csp_id = ?", id); res.Error != nil {
+			return res.Error
+		}
+
+		res := tx.Delete(&model.CSPInfo{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%w: csp %s", integrity.ErrNotFound, id.String())
+		}
+		return nil
+	})
+}
+
+// Update updates an authentication info for CSP.
+// The previous auth becomes invalid immediately. Use RotateCSPAuth to keep it valid for a while.
+func (x *CspInfoAccessor) UpdateCSPAuth(id uuid.UUID, auth string) error {
+	if _, err := x.RotateCSPAuth(id, auth, "", 0); err != nil {
+		return fmt.Errorf("nothing updated in cspInfo for id %s. Err: %s", id.String(), err)
+	}
+
+	return nil
+}
+
+// RotateCSPAuth stores auth as a new version and makes it active.
+// The previous version stays valid for overlap so that workflows which already
+// loaded it can finish. It returns the new version.
+func (x *CspInfoAccessor) RotateCSPAuth(id uuid.UUID, auth string, rotatedBy string, overlap time.Duration) (int, error) {
+	var version int
+	err := x.db.Transaction(func(tx *gorm.DB) error {
+		cspInfo, err := lockCSPInfo(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := seedAuthHistory(tx, cspInfo); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if cspInfo.AuthVersion > 0 {
+			res := tx.Model(&model.CSPAuth{}).
+				Where("csp_id = ? AND version = ?", id, cspInfo.AuthVersion).
+				Update("valid_until", now.Add(overlap))
+			if res.Error != nil {
+				return res.Error
+			}
+		}
+
+		var latest int
+		res := tx.Model(&model.CSPAuth{}).Where("csp_id = ?", id).Select("COALESCE(MAX(version), 0)").Scan(&latest)
+		if res.Error != nil {
+			return res.Error
+		}
+		version = latest + 1
+
+		cspAuth := model.CSPAuth{
+			CspId:     id,
+			Version:   version,
+			Auth:      auth,
+			RotatedAt: now,
+			RotatedBy: rotatedBy,
+		}
+		if res := tx.Create(&cspAuth); res.Error != nil {
+			return res.Error
+		}
+
+		return activateAuth(tx, id, version, auth)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// RollbackCSPAuth revokes the active auth and makes the latest version before it active again.
+// It returns the version which became active.
+func (x *CspInfoAccessor) RollbackCSPAuth(id uuid.UUID, rolledBackBy string) (int, error) {
+	var version int
+	err := x.db.Transaction(func(tx *gorm.DB) error {
+		cspInfo, err := lockCSPInfo(tx, id)
+		if err != nil {
+			return err
+		}
+
+		var previous model.CSPAuth
+		res := tx.Where("csp_id = ? AND version < ? AND revoked_at IS NULL", id, cspInfo.AuthVersion).
+			Order("version desc").First(&previous)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: csp %s", ErrNoPreviousAuth, id.String())
+		} else if res.Error != nil {
+			return res.Error
+		}
+
+		now := time.Now()
+		res = tx.Model(&model.CSPAuth{}).
+			Where("csp_id = ? AND version = ?", id, cspInfo.AuthVersion).
+			Updates(map[string]interface{}{"revoked_at": now, "revoked_by": rolledBackBy, "valid_until": now})
+		if res.Error != nil {
+			return res.Error
+		}
+
+		res = tx.Model(&model.CSPAuth{}).Where("id = ?", previous.ID).Update("valid_until", nil)
+		if res.Error != nil {
+			return res.Error
+		}
+
+		version = previous.Version
+		return activateAuth(tx, id, previous.Version, previous.Auth)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// GetValidCSPAuths returns the active auth followed by previous auths
+// which are still in their overlap window.
+func (x *CspInfoAccessor) GetValidCSPAuths(id uuid.UUID) ([]model.CSPAuth, error) {
+	cspInfo, err := x.GetCSPInfo(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var cspAuths []model.CSPAuth
+	res := x.db.Where("csp_id = ? AND revoked_at IS NULL AND (version = ? OR valid_until > ?)",
+		id, cspInfo.AuthVersion, time.Now()).
+		Order("version desc").
+		Find(&cspAuths)
+	if res.Error != nil {
+		return nil, res.Error
+	}
 
-	if res.Error != nil || res.RowsAffected == 0 {
-		return fmt.Errorf("nothing updated in cspInfo for id %s", id.String())
+	// The active auth comes first even after a rollback to an older version.
+	valid := []model.CSPAuth{}
+	for _, cspAuth := range cspAuths {
+		if cspAuth.Version == cspInfo.AuthVersion {
+			valid = append([]model.CSPAuth{cspAuth}, valid...)
+		} else {
+			valid = append(valid, cspAuth)
+		}
 	}
+	return valid, nil
+}
 
+// GetValidCSPAuth returns the auth of the version if it is active or still in its overlap window,
+// so that workflows which loaded it before a rotation can keep using it.
+func (x *CspInfoAccessor) GetValidCSPAuth(id uuid.UUID, version int) (model.CSPAuth, error) {
+	cspAuths, err := x.GetValidCSPAuths(id)
+	if err != nil {
+		return model.CSPAuth{}, err
+	}
+	for _, cspAuth := range cspAuths {
+		if cspAuth.Version == version {
+			return cspAuth, nil
+		}
+	}
+	return model.CSPAuth{}, fmt.Errorf("%w: version %d of csp %s", ErrInvalidAuthVersion, version, id.String())
+}
+
+// GetCSPAuthHistory returns every version of the auth of the CSP, latest first.
+// Auth itself is not loaded so that the history can be shown to auditors.
+func (x *CspInfoAccessor) GetCSPAuthHistory(id uuid.UUID) ([]model.CSPAuth, error) {
+	var cspAuths []model.CSPAuth
+	res := x.db.Omit("auth").Where("csp_id = ?", id).Order("version desc").Find(&cspAuths)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	return cspAuths, nil
+}
+
+func lockCSPInfo(tx *gorm.DB, id uuid.UUID) (*model.CSPInfo, error) {
+	var cspInfo model.CSPInfo
+	res := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cspInfo, "id = ?", id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: csp %s", integrity.ErrNotFound, id.String())
+	} else if res.Error != nil {
+		return nil, res.Error
+	}
+	return &cspInfo, nil
+}
+
+// seedAuthHistory stores the auth of a CSP which has no history yet as its first version.
+func seedAuthHistory(tx *gorm.DB, cspInfo *model.CSPInfo) error {
+	if cspInfo.AuthVersion > 0 || cspInfo.Auth == "" {
+		return nil
+	}
+
+	cspAuth := model.CSPAuth{
+		CspId:     cspInfo.ID,
+		Version:   1,
+		Auth:      cspInfo.Auth,
+		RotatedAt: cspInfo.UpdatedAt,
+	}
+	if res := tx.Create(&cspAuth); res.Error != nil {
+		return res.Error
+	}
+
+	cspInfo.AuthVersion = 1
+	return tx.Model(&model.CSPInfo{}).Where("id = ?", cspInfo.ID).Update("auth_version", 1).Error
+}
+
+func activateAuth(tx *gorm.DB, id uuid.UUID, version int, auth string) error {
+	res := tx.Model(&model.CSPInfo{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"auth": auth, "auth_version": version})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return fmt.Errorf("%w: csp %s", integrity.ErrNotFound, id.String())
+	}
 	return nil
 }
@@ -1,9 +1,11 @@
 package csp_info_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
@@ -12,8 +14,11 @@ import (
 	"github.com/openinfradev/tks-common/pkg/helper"
 	"github.com/openinfradev/tks-common/pkg/log"
 
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
 	"github.com/openinfradev/tks-info/pkg/csp_info"
 	"github.com/openinfradev/tks-info/pkg/csp_info/model"
+	"github.com/openinfradev/tks-info/pkg/integrity"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
 )
 
 var (
@@ -23,6 +28,7 @@ var (
 )
 
 var (
+	testDB     *gorm.DB
 	testDBHost string
 	testDBPort string
 	err        error
@@ -45,9 +51,10 @@ func getAccessor() (*csp_info.CspInfoAccessor, error) {
 
 	db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
 
-	if err := db.AutoMigrate(&model.CSPInfo{}); err != nil {
+	if err := db.AutoMigrate(&model.CSPInfo{}, &model.CSPAuth{}, &clusterModel.Cluster{}); err != nil {
 		return nil, err
 	}
+	testDB = db
 
 	return csp_info.New(db), nil
 }
@@ -94,3 +101,154 @@ func TestUpdateCSPAuth(t *testing.T) {
 		t.Errorf("An error occurred while updating CSP auth. Err: %s", err)
 	}
 }
+
+func TestGetCSPInfosByContractID(t *testing.T) {
+	cspInfos, err := cspInfoAccessor.GetCSPInfosByContractID(contractId)
+	if err != nil {
+		t.Errorf("An error occurred while getting CSP infos. Err: %s", err)
+	}
+	if len(cspInfos) != 1 || cspInfos[0].Name != "dummy" {
+		t.Errorf("Unexpected CSP infos: %v", cspInfos)
+	}
+}
+
+func TestUpdateName(t *testing.T) {
+	if err := cspInfoAccessor.UpdateName(cspId, "renamed"); err != nil {
+		t.Errorf("An error occurred while renaming CSP. Err: %s", err)
+	}
+
+	cspInfo, _ := cspInfoAccessor.GetCSPInfo(cspId)
+	if cspInfo.Name != "renamed" {
+		t.Errorf("CSP was not renamed. name: %s", cspInfo.Name)
+	}
+
+	if err := cspInfoAccessor.UpdateName(uuid.New(), "renamed"); !errors.Is(err, integrity.ErrNotFound) {
+		t.Errorf("An error for not existing CSP was expected, but got: %v", err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	id, err := cspInfoAccessor.Create(contractId, "used", "", 0)
+	if err != nil {
+		t.Fatalf("An error occurred while creating new cspInfo. Err: %s", err)
+	}
+	cluster := clusterModel.Cluster{ContractID: contractId, CspID: id}
+	testDB.Create(&cluster)
+
+	if err := cspInfoAccessor.Delete(id); !errors.Is(err, integrity.ErrInUse) {
+		t.Errorf("An error for CSP in use was expected, but got: %v", err)
+	}
+
+	testDB.Delete(&cluster)
+	if err := cspInfoAccessor.Delete(id); err != nil {
+		t.Errorf("An error occurred while deleting CSP. Err: %s", err)
+	}
+	if _, err := cspInfoAccessor.GetCSPInfo(id); err == nil {
+		t.Errorf("CSP was not deleted")
+	}
+}
+
+func TestValidateAuth(t *testing.T) {
+	testCases := []struct {
+		name    string
+		cspType pb.CspType
+		auth    string
+		valid   bool
+	}{
+		{"EMPTY", pb.CspType_AWS, "", true},
+		{"UNSPECIFIED_TYPE", pb.CspType_CSPTYPE_UNSPECIFIED, "anything", true},
+		{"AWS", pb.CspType_AWS, `{"accessKeyId":"key","secretAccessKey":"secret"}`, true},
+		{"AWS_SNAKE_CASE", pb.CspType_AWS, `{"aws_access_key_id":"key","aws_secret_access_key":"secret"}`, true},
+		{"AWS_MISSING_KEY", pb.CspType_AWS, `{"accessKeyId":"key"}`, false},
+		{"AZURE_NOT_JSON", pb.CspType_AZURE, "secret", true},
+		{"AZURE_INVALID_JSON", pb.CspType_AZURE, `{"clientId":`, false},
+		{"GCP", pb.CspType_GCP, `{"type":"service_account","project_id":"p","private_key":"k","client_email":"e"}`, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := csp_info.ValidateAuth(tc.cspType, tc.auth)
+			if tc.valid && err != nil {
+				t.Errorf("auth should be valid. Err: %s", err)
+			}
+			if !tc.valid && err == nil {
+				t.Errorf("auth should be invalid")
+			}
+		})
+	}
+}
+
+func TestRotateCSPAuth(t *testing.T) {
+	id, err := cspInfoAccessor.Create(contractId, "rotation", "v1", 0)
+	if err != nil {
+		t.Fatalf("An error occurred while creating new cspInfo. Err: %s", err)
+	}
+
+	version, err := cspInfoAccessor.RotateCSPAuth(id, "v2", "admin", time.Hour)
+	if err != nil || version != 2 {
+		t.Fatalf("An error occurred while rotating CSP auth. version: %d, Err: %v", version, err)
+	}
+
+	// Both the new and the previous auth are valid during the overlap.
+	cspAuths, err := cspInfoAccessor.GetValidCSPAuths(id)
+	if err != nil {
+		t.Fatalf("An error occurred while getting valid CSP auths. Err: %s", err)
+	}
+	if len(cspAuths) != 2 || cspAuths[0].Auth != "v2" || cspAuths[1].Auth != "v1" {
+		t.Errorf("Unexpected valid CSP auths: %v", cspAuths)
+	}
+
+	// Without overlap only the new auth is valid.
+	if _, err := cspInfoAccessor.RotateCSPAuth(id, "v3", "admin", 0); err != nil {
+		t.Fatalf("An error occurred while rotating CSP auth. Err: %s", err)
+	}
+	cspAuths, _ = cspInfoAccessor.GetValidCSPAuths(id)
+	if len(cspAuths) != 2 || cspAuths[0].Auth != "v3" || cspAuths[1].Auth != "v1" {
+		t.Errorf("Unexpected valid CSP auths: %v", cspAuths)
+	}
+
+	// A workflow which loaded a version can keep getting it while it is valid.
+	if cspAuth, err := cspInfoAccessor.GetValidCSPAuth(id, 1); err != nil || cspAuth.Auth != "v1" {
+		t.Errorf("Version 1 should be valid: %v, Err: %v", cspAuth, err)
+	}
+	if _, err := cspInfoAccessor.GetValidCSPAuth(id, 2); !errors.Is(err, csp_info.ErrInvalidAuthVersion) {
+		t.Errorf("An error for an invalid version was expected, but got: %v", err)
+	}
+
+	history, err := cspInfoAccessor.GetCSPAuthHistory(id)
+	if err != nil || len(history) != 3 {
+		t.Fatalf("Unexpected CSP auth history: %v, Err: %v", history, err)
+	}
+	if history[0].RotatedBy != "admin" || history[0].Auth != "" {
+		t.Errorf("History should have metadata without auth: %v", history[0])
+	}
+}
+
+func TestRollbackCSPAuth(t *testing.T) {
+	id, err := cspInfoAccessor.Create(contractId, "rollback", "v1", 0)
+	if err != nil {
+		t.Fatalf("An error occurred while creating new cspInfo. Err: %s", err)
+	}
+	if _, err := cspInfoAccessor.RotateCSPAuth(id, "v2", "admin", 0); err != nil {
+		t.Fatalf("An error occurred while rotating CSP auth. Err: %s", err)
+	}
+
+	version, err := cspInfoAccessor.RollbackCSPAuth(id, "admin")
+	if err != nil || version != 1 {
+		t.Fatalf("An error occurred while rolling back CSP auth. version: %d, Err: %v", version, err)
+	}
+
+	cspInfo, _ := cspInfoAccessor.GetCSPInfo(id)
+	if cspInfo.Auth != "v1" || cspInfo.AuthVersion != 1 {
+		t.Errorf("CSP auth was not rolled back: %s (%d)", cspInfo.Auth, cspInfo.AuthVersion)
+	}
+
+	cspAuths, _ := cspInfoAccessor.GetValidCSPAuths(id)
+	if len(cspAuths) != 1 || cspAuths[0].Version != 1 {
+		t.Errorf("Rolled back auth should not be valid: %v", cspAuths)
+	}
+
+	if _, err := cspInfoAccessor.RollbackCSPAuth(id, "admin"); !errors.Is(err, csp_info.ErrNoPreviousAuth) {
+		t.Errorf("An error for missing previous auth was expected, but got: %v", err)
+	}
+}
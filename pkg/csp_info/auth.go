@@ -0,0 +1,72 @@
+package csp_info
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// requiredAuthKeys are the keys which the auth JSON of each CSP type must have.
+// Each key may be spelled in any of its names, as clients use the names of
+// the SDKs, the credential files or the environment of each CSP.
+var requiredAuthKeys = map[pb.CspType][][]string{
+	pb.CspType_AWS: {
+		{"accessKeyId", "access_key_id", "aws_access_key_id"},
+		{"secretAccessKey", "secret_access_key", "aws_secret_access_key"},
+	},
+	pb.CspType_GCP: {
+		{"type"}, {"project_id"}, {"private_key"}, {"client_email"},
+	},
+	pb.CspType_AZURE: {
+		{"clientId", "client_id"},
+		{"clientSecret", "client_secret"},
+		{"tenantId", "tenant_id"},
+		{"subscriptionId", "subscription_id"},
+	},
+}
+
+// ValidateAuth checks that auth has the shape expected for the CSP type.
+// An empty auth is allowed because auth is optional. Auth which is not a JSON object,
+// such as a key in PEM, is stored as it is, and auth of an unspecified CSP type is not checked.
+func ValidateAuth(cspType pb.CspType, auth string) error {
+	if auth == "" {
+		return nil
+	}
+
+	keys, ok := requiredAuthKeys[cspType]
+	if !ok {
+		return nil
+	}
+
+	if !strings.HasPrefix(strings.TrimSpace(auth), "{") {
+		return nil
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(auth), &fields); err != nil {
+		return fmt.Errorf("auth for %s is not valid JSON: %w", cspType, err)
+	}
+
+	missing := []string{}
+	for _, names := range keys {
+		if !hasAnyKey(fields, names) {
+			missing = append(missing, names[0])
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("auth for %s is missing %v", cspType, missing)
+	}
+	return nil
+}
+
+func hasAnyKey(fields map[string]interface{}, names []string) bool {
+	for _, name := range names {
+		if value, ok := fields[name].(string); ok && value != "" {
+			return true
+		}
+	}
+	return false
+}
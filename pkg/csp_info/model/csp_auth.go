@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	uuid "github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CSPAuth represents a version of the authentication info of a CSP.
+type CSPAuth struct {
+	ID         uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	CspId      uuid.UUID `gorm:"uniqueIndex:idx_csp_auths_csp_id_version"`
+	Version    int       `gorm:"uniqueIndex:idx_csp_auths_csp_id_version"`
+	Auth       string
+	RotatedAt  time.Time
+	RotatedBy  string
+	ValidUntil *time.Time
+	RevokedAt  *time.Time
+	RevokedBy  string
+	UpdatedAt  time.Time
+	CreatedAt  time.Time
+}
+
+func (c *CSPAuth) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
@@ -15,8 +15,10 @@ type CSPInfo struct {
 	Name       string
 	Auth       string
 	CspType    pb.CspType
-	UpdatedAt  time.Time
-	CreatedAt  time.Time
+	// AuthVersion is the version of CSPAuth which Auth was copied from.
+	AuthVersion int
+	UpdatedAt   time.Time
+	CreatedAt   time.Time
 }
 
 func (c *CSPInfo) BeforeCreate(tx *gorm.DB) (err error) {
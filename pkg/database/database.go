@@ -0,0 +1,196 @@
+package database
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+)
+
+// maxBackoff caps the wait between connection attempts.
+const maxBackoff = 30 * time.Second
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+// Config describes how to connect to postgreSQL.
+type Config struct {
+	Host     string
+	Port     string
+	User     string
+	Password string
+	Name     string
+	TimeZone string
+
+	// SSLMode is one of the sslmode of libpq. verify-ca and verify-full need SSLRootCert.
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	ConnectTimeout   time.Duration
+	StatementTimeout time.Duration
+
+	// ConnectRetries is the number of retries when the database is not ready.
+	// ConnectBackoff is the first wait between attempts and doubles on each retry.
+	ConnectRetries int
+	ConnectBackoff time.Duration
+}
+
+// RegisterFlags defines the flags of the fields on fs, such as "dbhost" and "db-name".
+func (c *Config) RegisterFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.Host, "dbhost", "localhost", "host of postgreSQL")
+	fs.StringVar(&c.Port, "dbport", "5432", "port of postgreSQL")
+	fs.StringVar(&c.User, "dbuser", "postgres", "postgreSQL user")
+	fs.StringVar(&c.Password, "dbpassword", "password", "password for postgreSQL user")
+	fs.StringVar(&c.Name, "db-name", "tks", "name of postgreSQL database")
+	fs.StringVar(&c.SSLMode, "db-sslmode", "disable", "sslmode of postgreSQL connection")
+	fs.StringVar(&c.SSLRootCert, "db-sslrootcert", "", "path of CA cert file to verify postgreSQL server")
+	fs.StringVar(&c.SSLCert, "db-sslcert", "", "path of client cert file for postgreSQL")
+	fs.StringVar(&c.SSLKey, "db-sslkey", "", "path of client key file for postgreSQL")
+	fs.StringVar(&c.TimeZone, "db-timezone", "Asia/Seoul", "time zone of postgreSQL session")
+	fs.IntVar(&c.MaxOpenConns, "db-max-open-conns", 0, "maximum number of open connections to postgreSQL, 0 for unlimited")
+	fs.IntVar(&c.MaxIdleConns, "db-max-idle-conns", 2, "maximum number of idle connections to postgreSQL")
+	fs.DurationVar(&c.ConnMaxLifetime, "db-conn-max-lifetime", 0, "maximum lifetime of a postgreSQL connection, 0 for unlimited")
+	fs.DurationVar(&c.ConnMaxIdleTime, "db-conn-max-idle-time", 0, "maximum idle time of a postgreSQL connection, 0 for unlimited")
+	fs.DurationVar(&c.ConnectTimeout, "db-connect-timeout", 10*time.Second, "timeout of connecting to postgreSQL")
+	fs.DurationVar(&c.StatementTimeout, "db-statement-timeout", 0, "timeout of each postgreSQL statement, 0 for no timeout")
+	fs.IntVar(&c.ConnectRetries, "db-connect-retries", 10, "number of retries when postgreSQL is not ready on startup")
+	fs.DurationVar(&c.ConnectBackoff, "db-connect-backoff", time.Second, "first wait between retries, doubled on each retry")
+}
+
+// Validate returns an error describing every invalid field.
+func (c Config) Validate() error {
+	problems := []string{}
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Host != "", "host must not be empty")
+	check(c.User != "", "user must not be empty")
+	check(c.Name != "", "name must not be empty")
+	check(isOneOf(c.SSLMode, sslModes...), "sslmode %q is not valid", c.SSLMode)
+	if c.SSLMode == "verify-ca" || c.SSLMode == "verify-full" {
+		check(c.SSLRootCert != "", "sslmode %s needs a root certificate", c.SSLMode)
+	}
+	check((c.SSLCert == "") == (c.SSLKey == ""), "client certificate and key must be given together")
+	for _, path := range []string{c.SSLRootCert, c.SSLCert, c.SSLKey} {
+		if path != "" {
+			_, err := os.Stat(path)
+			check(err == nil, "%v", err)
+		}
+	}
+	check(c.MaxOpenConns >= 0, "max open connections must not be negative")
+	check(c.MaxIdleConns >= 0, "max idle connections must not be negative")
+	check(c.ConnMaxLifetime >= 0, "connection max lifetime must not be negative")
+	check(c.ConnMaxIdleTime >= 0, "connection max idle time must not be negative")
+	check(c.ConnectTimeout >= 0, "connect timeout must not be negative")
+	check(c.StatementTimeout >= 0, "statement timeout must not be negative")
+	check(c.ConnectRetries >= 0, "connect retries must not be negative")
+	check(c.ConnectBackoff >= 0, "connect backoff must not be negative")
+
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid database configuration: %s", strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+// DSN returns the connection string of the config.
+func (c Config) DSN() string {
+	params := [][2]string{
+		{"host", c.Host},
+		{"port", c.Port},
+		{"user", c.User},
+		{"password", c.Password},
+		{"dbname", c.Name},
+		{"sslmode", c.SSLMode},
+		{"TimeZone", c.TimeZone},
+	}
+	if c.SSLRootCert != "" {
+		params = append(params, [2]string{"sslrootcert", c.SSLRootCert})
+	}
+	if c.SSLCert != "" {
+		params = append(params, [2]string{"sslcert", c.SSLCert}, [2]string{"sslkey", c.SSLKey})
+	}
+	if c.ConnectTimeout > 0 {
+		params = append(params, [2]string{"connect_timeout", fmt.Sprint(int(c.ConnectTimeout.Seconds()))})
+	}
+	if c.StatementTimeout > 0 {
+		params = append(params, [2]string{"statement_timeout", fmt.Sprint(c.StatementTimeout.Milliseconds())})
+	}
+
+	pairs := make([]string, 0, len(params))
+	for _, p := range params {
+		pairs = append(pairs, p[0]+"="+quote(p[1]))
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Open connects to the database, retrying with backoff while it is not ready,
+// and configures the connection pool.
+func Open(c Config) (*gorm.DB, error) {
+	db, err := retry(c.ConnectRetries, c.ConnectBackoff, time.Sleep, func() (*gorm.DB, error) {
+		return gorm.Open(postgres.Open(c.DSN()), &gorm.Config{})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(c.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(c.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(c.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	return db, nil
+}
+
+func retry(retries int, backoff time.Duration, sleep func(time.Duration), open func() (*gorm.DB, error)) (*gorm.DB, error) {
+	for attempt := 0; ; attempt++ {
+		db, err := open()
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= retries {
+			return nil, fmt.Errorf("failed to connect to database after %d attempts: %w", attempt+1, err)
+		}
+
+		log.Warn("database is not ready. retrying in ", backoff, ". err : ", err)
+		sleep(backoff)
+		if backoff *= 2; backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// quote quotes a value of the key=value connection string when needed.
+func quote(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+func isOneOf(value string, candidates ...string) bool {
+	for _, c := range candidates {
+		if value == c {
+			return true
+		}
+	}
+	return false
+}
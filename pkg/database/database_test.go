@@ -0,0 +1,100 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+)
+
+func init() {
+	log.Disable()
+}
+
+func validConfig() Config {
+	return Config{
+		Host:     "localhost",
+		Port:     "5432",
+		User:     "postgres",
+		Password: "password",
+		Name:     "tks",
+		SSLMode:  "disable",
+		TimeZone: "Asia/Seoul",
+	}
+}
+
+func TestDSN(t *testing.T) {
+	c := validConfig()
+	require.Equal(t, "host=localhost port=5432 user=postgres password=password dbname=tks sslmode=disable TimeZone=Asia/Seoul", c.DSN())
+
+	c.Password = `it's a secret\`
+	c.SSLMode = "verify-full"
+	c.SSLRootCert = "/certs/ca.crt"
+	c.SSLCert = "/certs/client.crt"
+	c.SSLKey = "/certs/client.key"
+	c.ConnectTimeout = 5 * time.Second
+	c.StatementTimeout = 1500 * time.Millisecond
+	require.Equal(t, `host=localhost port=5432 user=postgres password='it\'s a secret\\' dbname=tks sslmode=verify-full TimeZone=Asia/Seoul `+
+		`sslrootcert=/certs/ca.crt sslcert=/certs/client.crt sslkey=/certs/client.key connect_timeout=5 statement_timeout=1500`, c.DSN())
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, validConfig().Validate())
+
+	dir := t.TempDir()
+	ca := filepath.Join(dir, "ca.crt")
+	require.NoError(t, os.WriteFile(ca, []byte("ca"), 0600))
+
+	c := validConfig()
+	c.SSLMode = "verify-full"
+	c.SSLRootCert = ca
+	require.NoError(t, c.Validate())
+
+	tests := map[string]func(c *Config){
+		"invalid sslmode":     func(c *Config) { c.SSLMode = "on" },
+		"no root cert":        func(c *Config) { c.SSLMode = "verify-ca" },
+		"missing root cert":   func(c *Config) { c.SSLRootCert = filepath.Join(dir, "none.crt") },
+		"cert without key":    func(c *Config) { c.SSLCert = ca },
+		"negative pool size":  func(c *Config) { c.MaxOpenConns = -1 },
+		"negative retries":    func(c *Config) { c.ConnectRetries = -1 },
+		"empty database name": func(c *Config) { c.Name = "" },
+	}
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := validConfig()
+			modify(&c)
+			require.Error(t, c.Validate())
+		})
+	}
+}
+
+func TestRetry(t *testing.T) {
+	waits := []time.Duration{}
+	sleep := func(d time.Duration) { waits = append(waits, d) }
+
+	attempts := 0
+	db, err := retry(10, 10*time.Second, sleep, func() (*gorm.DB, error) {
+		attempts++
+		if attempts < 4 {
+			return nil, errors.New("connection refused")
+		}
+		return &gorm.DB{}, nil
+	})
+	require.NoError(t, err)
+	require.NotNil(t, db)
+	require.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second}, waits)
+
+	attempts = 0
+	_, err = retry(2, time.Second, func(time.Duration) {}, func() (*gorm.DB, error) {
+		attempts++
+		return nil, errors.New("connection refused")
+	})
+	require.Error(t, err)
+	require.Equal(t, 3, attempts)
+}
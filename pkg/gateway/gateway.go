@@ -0,0 +1,306 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// PathPrefix is the prefix of the paths of RPCs, which are /v1/<service>/<method>,
+// such as /v1/ClusterInfoService/GetCluster.
+const PathPrefix = "/v1/"
+
+// metadataHeaderPrefix is the prefix of HTTP headers passed to RPCs as metadata without it.
+const metadataHeaderPrefix = "Grpc-Metadata-"
+
+// forwardedHeaders are the HTTP headers passed to RPCs as metadata.
+var forwardedHeaders = []string{"Authorization", "Idempotency-Key", "Traceparent", "Tracestate"}
+
+// maxBodySize is the default limit of request bodies.
+const maxBodySize = 4 << 20
+
+type method struct {
+	desc  grpc.MethodDesc
+	impl  interface{}
+	input protoreflect.MessageDescriptor
+}
+
+// Gateway serves unary RPCs of the registered services as JSON over HTTP.
+// Requests are JSON of the request messages, and responses are JSON of the response messages,
+// both with field names of the proto files. Requests of GET methods are given as query parameters.
+// RPCs run in-process through the interceptor, as they do through the gRPC server.
+type Gateway struct {
+	interceptor grpc.UnaryServerInterceptor
+	methods     map[string]method
+	routes      map[string]Route
+	services    []protoreflect.ServiceDescriptor
+	maxBodySize int64
+}
+
+// New returns new Gateway which runs RPCs through the interceptor. The interceptor may be nil.
+func New(interceptor grpc.UnaryServerInterceptor) *Gateway {
+	return &Gateway{
+		interceptor: interceptor,
+		methods:     map[string]method{},
+		routes:      map[string]Route{},
+		maxBodySize: maxBodySize,
+	}
+}
+
+// SetMaxBodySize limits the size of request bodies in bytes.
+func (g *Gateway) SetMaxBodySize(n int) {
+	g.maxBodySize = int64(n)
+}
+
+// RegisterService registers the service implementation like grpc.Server does,
+// so that pb.Register*Server functions can register services to the gateway.
+func (g *Gateway) RegisterService(desc *grpc.ServiceDesc, impl interface{}) {
+	d, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(desc.ServiceName))
+	if err != nil {
+		panic(fmt.Sprintf("gateway: unknown service %s: %v", desc.ServiceName, err))
+	}
+	sd, ok := d.(protoreflect.ServiceDescriptor)
+	if !ok {
+		panic(fmt.Sprintf("gateway: %s is not a service", desc.ServiceName))
+	}
+	g.services = append(g.services, sd)
+
+	for _, m := range desc.Methods {
+		md := sd.Methods().ByName(protoreflect.Name(m.MethodName))
+		if md == nil {
+			panic(fmt.Sprintf("gateway: unknown method %s of %s", m.MethodName, desc.ServiceName))
+		}
+		if _, ok := g.routes[string(sd.Name())+"/"+m.MethodName]; ok {
+			panic(fmt.Sprintf("gateway: %s/%s is already registered", sd.Name(), m.MethodName))
+		}
+		g.methods[string(sd.Name())+"/"+m.MethodName] = method{
+			desc:  m,
+			impl:  impl,
+			input: md.Input(),
+		}
+	}
+}
+
+// isGetMethod tells whether the RPC reads resources and can be called with GET.
+func isGetMethod(name string) bool {
+	return strings.HasPrefix(name, "Get")
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, PathPrefix)
+	if route, ok := g.routes[path]; ok && strings.HasPrefix(r.URL.Path, PathPrefix) {
+		g.serveRoute(w, r, route)
+		return
+	}
+	m, ok := g.methods[path]
+	if !strings.HasPrefix(r.URL.Path, PathPrefix) || !ok {
+		writeError(w, status.Errorf(codes.NotFound, "no RPC at %s", r.URL.Path))
+		return
+	}
+
+	var dec func(interface{}) error
+	switch {
+	case r.Method == http.MethodPost:
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, g.maxBodySize))
+		if err != nil {
+			writeError(w, status.Errorf(codes.ResourceExhausted, "failed to read request: %v", err))
+			return
+		}
+		dec = func(v interface{}) error {
+			if len(body) == 0 {
+				return nil
+			}
+			return unmarshal(body, v)
+		}
+	case r.Method == http.MethodGet && isGetMethod(m.desc.MethodName):
+		body, err := queryToJSON(r.URL.Query(), m.input)
+		if err != nil {
+			writeError(w, status.Error(codes.InvalidArgument, err.Error()))
+			return
+		}
+		dec = func(v interface{}) error {
+			return unmarshal(body, v)
+		}
+	default:
+		w.Header().Set("Allow", allowedMethods(m.desc.MethodName))
+		writeStatus(w, http.StatusMethodNotAllowed, status.Newf(codes.Unimplemented, "method %s is not allowed", r.Method))
+		return
+	}
+
+	res, err := m.desc.Handler(m.impl, incomingContext(r), dec, g.interceptor)
+	if err != nil {
+		writeError(w, responseError(res, err))
+		return
+	}
+	writeMessage(w, http.StatusOK, res)
+}
+
+func allowedMethods(name string) string {
+	if isGetMethod(name) {
+		return "GET, POST"
+	}
+	return "POST"
+}
+
+func unmarshal(body []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return status.Error(codes.Internal, "request is not a proto message")
+	}
+	if err := protojson.Unmarshal(body, msg); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid request: %v", err)
+	}
+	return nil
+}
+
+// queryToJSON converts query parameters, named after the fields of the message, to JSON of the message.
+func queryToJSON(query map[string][]string, md protoreflect.MessageDescriptor) ([]byte, error) {
+	fields := map[string]interface{}{}
+	for name, values := range query {
+		fd := md.Fields().ByName(protoreflect.Name(name))
+		if fd == nil {
+			fd = md.Fields().ByJSONName(name)
+		}
+		if fd == nil {
+			return nil, fmt.Errorf("unknown parameter %s", name)
+		}
+		if fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind {
+			return nil, fmt.Errorf("parameter %s must be given in the body of POST", name)
+		}
+		value := values[len(values)-1]
+		if fd.Kind() == protoreflect.BoolKind {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("parameter %s must be a boolean", name)
+			}
+			fields[name] = b
+			continue
+		}
+		// protojson takes quoted numbers and enum names.
+		fields[name] = value
+	}
+	return json.Marshal(fields)
+}
+
+// incomingContext returns the context of the RPC with the metadata and the peer of the HTTP request.
+func incomingContext(r *http.Request) context.Context {
+	md := metadata.MD{}
+	for _, name := range forwardedHeaders {
+		if values := r.Header.Values(name); len(values) > 0 {
+			md.Append(name, values...)
+		}
+	}
+	for name, values := range r.Header {
+		if strings.HasPrefix(name, metadataHeaderPrefix) {
+			md.Append(strings.TrimPrefix(name, metadataHeaderPrefix), values...)
+		}
+	}
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+
+	p := &peer.Peer{Addr: remoteAddr(r.RemoteAddr)}
+	if r.TLS != nil {
+		p.AuthInfo = credentials.TLSInfo{State: *r.TLS}
+	}
+	return peer.NewContext(ctx, p)
+}
+
+// remoteAddr is the address of an HTTP client as net.Addr.
+type remoteAddr string
+
+func (a remoteAddr) Network() string { return "tcp" }
+func (a remoteAddr) String() string  { return string(a) }
+
+type codeResponse interface {
+	GetCode() pb.Code
+}
+
+// responseError returns the status of a failed RPC. Handlers return plain errors
+// with the code in the response, so the code of the response is preferred.
+func responseError(res interface{}, err error) error {
+	if _, ok := status.FromError(err); !ok {
+		if r, ok := res.(codeResponse); ok && res != nil && r.GetCode() != pb.Code_OK_UNSPECIFIED {
+			return status.Error(codes.Code(r.GetCode()), err.Error())
+		}
+	}
+	return err
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	s := status.Convert(err)
+	writeStatus(w, HTTPStatus(s.Code()), s)
+}
+
+// writeStatus writes the status as the Error schema of the OpenAPI document.
+func writeStatus(w http.ResponseWriter, httpStatus int, s *status.Status) {
+	body, _ := json.Marshal(map[string]string{
+		"code":    pb.Code(s.Code()).String(),
+		"message": s.Message(),
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	_, _ = w.Write(body)
+}
+
+func writeMessage(w http.ResponseWriter, code int, res interface{}) {
+	msg, ok := res.(proto.Message)
+	if !ok {
+		writeError(w, status.Error(codes.Internal, "response is not a proto message"))
+		return
+	}
+	body, err := messageMarshaler.Marshal(msg)
+	if err != nil {
+		writeError(w, status.Error(codes.Internal, err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
+
+// HTTPStatus returns the HTTP status of a gRPC code.
+func HTTPStatus(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.Canceled:
+		return 499
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,208 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+type clusterInfoServer struct {
+	pb.UnimplementedClusterInfoServiceServer
+}
+
+func (s *clusterInfoServer) GetCluster(ctx context.Context, in *pb.GetClusterRequest) (*pb.GetClusterResponse, error) {
+	if in.GetClusterId() == "" {
+		return &pb.GetClusterResponse{
+			Code:  pb.Code_INVALID_ARGUMENT,
+			Error: &pb.Error{Msg: "invalid cluster ID"},
+		}, errors.New("invalid cluster ID")
+	}
+	return &pb.GetClusterResponse{
+		Code:    pb.Code_OK_UNSPECIFIED,
+		Cluster: &pb.Cluster{Id: in.GetClusterId(), Status: pb.ClusterStatus_RUNNING},
+	}, nil
+}
+
+func newTestGateway(interceptor grpc.UnaryServerInterceptor) *Gateway {
+	g := New(interceptor)
+	pb.RegisterClusterInfoServiceServer(g, &clusterInfoServer{})
+	return g
+}
+
+func serve(g http.Handler, method string, target string, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer token")
+	g.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestGatewayCallsRPC(t *testing.T) {
+	var called string
+	var md metadata.MD
+	g := newTestGateway(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		called = info.FullMethod
+		md, _ = metadata.FromIncomingContext(ctx)
+		return handler(ctx, req)
+	})
+
+	for _, rec := range []*httptest.ResponseRecorder{
+		serve(g, http.MethodPost, "/v1/ClusterInfoService/GetCluster", `{"cluster_id":"C1234"}`),
+		serve(g, http.MethodGet, "/v1/ClusterInfoService/GetCluster?cluster_id=C1234", ""),
+		serve(g, http.MethodGet, "/v1/ClusterInfoService/GetCluster?clusterId=C1234", ""),
+	} {
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		res := &pb.GetClusterResponse{}
+		require.NoError(t, protojson.Unmarshal(rec.Body.Bytes(), res))
+		require.Equal(t, "C1234", res.GetCluster().GetId())
+		require.Equal(t, pb.ClusterStatus_RUNNING, res.GetCluster().GetStatus())
+		require.Contains(t, rec.Body.String(), `"status_desc"`)
+	}
+	require.Equal(t, "/tks_pb.ClusterInfoService/GetCluster", called)
+	require.Equal(t, []string{"Bearer token"}, md.Get("authorization"))
+}
+
+func TestGatewayErrors(t *testing.T) {
+	g := newTestGateway(nil)
+
+	tests := []struct {
+		method string
+		target string
+		body   string
+		status int
+		code   string
+	}{
+		{http.MethodPost, "/v1/ClusterInfoService/GetCluster", `{}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{http.MethodPost, "/v1/ClusterInfoService/GetCluster", `{"unknown":1}`, http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{http.MethodGet, "/v1/ClusterInfoService/GetCluster?unknown=1", "", http.StatusBadRequest, "INVALID_ARGUMENT"},
+		{http.MethodPost, "/v1/ClusterInfoService/AddClusterInfo", `{}`, http.StatusNotImplemented, "UNIMPLEMENTED"},
+		{http.MethodGet, "/v1/ClusterInfoService/AddClusterInfo", "", http.StatusMethodNotAllowed, "UNIMPLEMENTED"},
+		{http.MethodPost, "/v1/ClusterInfoService/Unknown", `{}`, http.StatusNotFound, "NOT_FOUND"},
+		{http.MethodPost, "/v2/ClusterInfoService/GetCluster", `{}`, http.StatusNotFound, "NOT_FOUND"},
+	}
+	for _, test := range tests {
+		rec := serve(g, test.method, test.target, test.body)
+		require.Equal(t, test.status, rec.Code, "%s %s", test.method, test.target)
+
+		var body map[string]string
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		require.Equal(t, test.code, body["code"], "%s %s", test.method, test.target)
+	}
+}
+
+func TestResponseError(t *testing.T) {
+	err := responseError(&pb.IDResponse{Code: pb.Code_NOT_FOUND}, errors.New("not found"))
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	err = responseError(&pb.IDResponse{Code: pb.Code_INTERNAL}, status.Error(codes.PermissionDenied, "denied"))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	err = responseError(nil, errors.New("panic"))
+	require.Equal(t, codes.Unknown, status.Code(err))
+}
+
+func TestOpenAPI(t *testing.T) {
+	g := newTestGateway(nil)
+	b, err := g.OpenAPI("tks-info", "v1")
+	require.NoError(t, err)
+
+	var doc struct {
+		Paths      map[string]map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(b, &doc))
+
+	require.Contains(t, doc.Paths["/v1/ClusterInfoService/GetCluster"], "get")
+	require.Contains(t, doc.Paths["/v1/ClusterInfoService/GetCluster"], "post")
+	require.NotContains(t, doc.Paths["/v1/ClusterInfoService/AddClusterInfo"], "get")
+	require.Contains(t, doc.Components.Schemas, "tks_pb.Cluster")
+
+	// Every referenced schema is in the document.
+	for _, ref := range strings.Split(string(b), `"$ref": "`)[1:] {
+		name := strings.TrimPrefix(ref[:strings.Index(ref, `"`)], schemaRef)
+		require.Contains(t, doc.Components.Schemas, name)
+	}
+}
+
+type testRouteRequest struct {
+	ClusterId string `json:"cluster_id"`
+}
+
+type testRouteResponse struct {
+	Name     string        `json:"name"`
+	Cluster  *pb.Cluster   `json:"cluster"`
+	Status   pb.CspType    `json:"status"`
+	Clusters []*pb.Cluster `json:"clusters"`
+	Empty    string        `json:"empty,omitempty"`
+}
+
+func TestGatewayRoute(t *testing.T) {
+	var called string
+	g := newTestGateway(func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		called = info.FullMethod
+		return handler(ctx, req)
+	})
+	g.HandleRoute(Route{
+		Service:    "tks_info.TestService",
+		Method:     "GetTest",
+		NewRequest: func() interface{} { return &testRouteRequest{} },
+		Handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+			in := req.(*testRouteRequest)
+			if in.ClusterId == "" {
+				return nil, status.Error(codes.InvalidArgument, "cluster_id is required")
+			}
+			return &testRouteResponse{
+				Name:    "test",
+				Cluster: &pb.Cluster{Id: in.ClusterId, Status: pb.ClusterStatus_RUNNING},
+				Status:  pb.CspType_AWS,
+			}, nil
+		},
+	})
+
+	rec := serve(g, http.MethodPost, "/v1/TestService/GetTest", `{"cluster_id":"C1234"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.Equal(t, "/tks_info.TestService/GetTest", called)
+
+	var res map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+	require.JSONEq(t, `"test"`, string(res["name"]))
+	require.JSONEq(t, `"AWS"`, string(res["status"]))
+	require.JSONEq(t, `[]`, string(res["clusters"]))
+	require.NotContains(t, res, "empty")
+	cluster := &pb.Cluster{}
+	require.NoError(t, protojson.Unmarshal(res["cluster"], cluster))
+	require.Equal(t, "C1234", cluster.GetId())
+	require.Equal(t, pb.ClusterStatus_RUNNING, cluster.GetStatus())
+
+	for _, test := range []struct {
+		method string
+		body   string
+		status int
+	}{
+		{http.MethodPost, `{}`, http.StatusBadRequest},
+		{http.MethodPost, `{"unknown":1}`, http.StatusBadRequest},
+		{http.MethodGet, "", http.StatusMethodNotAllowed},
+	} {
+		rec := serve(g, test.method, "/v1/TestService/GetTest", test.body)
+		require.Equal(t, test.status, rec.Code, "%s %s", test.method, test.body)
+	}
+
+	b, err := g.OpenAPI("tks-info", "v1")
+	require.NoError(t, err)
+	require.Contains(t, string(b), `"/v1/TestService/GetTest"`)
+}
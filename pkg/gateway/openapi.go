@@ -0,0 +1,233 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const schemaRef = "#/components/schemas/"
+
+// errorSchema is the schema of the JSON written for failed RPCs.
+var errorSchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"code":    map[string]interface{}{"type": "string"},
+		"message": map[string]interface{}{"type": "string"},
+	},
+}
+
+// OpenAPI returns the OpenAPI 3 document of the registered RPCs.
+func (g *Gateway) OpenAPI(title string, version string) ([]byte, error) {
+	schemas := map[string]interface{}{"Error": errorSchema}
+	paths := map[string]interface{}{}
+
+	for _, sd := range g.services {
+		methods := sd.Methods()
+		for i := 0; i < methods.Len(); i++ {
+			md := methods.Get(i)
+			path := PathPrefix + string(sd.Name()) + "/" + string(md.Name())
+			if _, ok := g.methods[path[len(PathPrefix):]]; !ok {
+				continue
+			}
+			addSchema(schemas, md.Input())
+			addSchema(schemas, md.Output())
+
+			operation := func(suffix string) map[string]interface{} {
+				return map[string]interface{}{
+					"operationId": string(sd.Name()) + "_" + string(md.Name()) + suffix,
+					"tags":        []string{string(sd.Name())},
+					"responses": map[string]interface{}{
+						"200":     jsonContent("OK", md.Output().FullName()),
+						"default": jsonContent("Error", "Error"),
+					},
+				}
+			}
+			post := operation("")
+			post["requestBody"] = jsonContent(string(md.Input().FullName()), md.Input().FullName())
+			item := map[string]interface{}{"post": post}
+			if isGetMethod(string(md.Name())) {
+				get := operation("_Get")
+				get["parameters"] = queryParameters(md.Input())
+				item["get"] = get
+			}
+			paths[path] = item
+		}
+	}
+
+	for path, route := range g.routes {
+		paths[PathPrefix+path] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": strings.Replace(path, "/", "_", 1),
+				"tags":        []string{path[:strings.Index(path, "/")]},
+				"summary":     route.Summary,
+				"requestBody": objectContent("request"),
+				"responses": map[string]interface{}{
+					"200":     objectContent("OK"),
+					"default": jsonContent("Error", "Error"),
+				},
+			},
+		}
+	}
+
+	return json.MarshalIndent(map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   title,
+			"version": version,
+		},
+		"paths":    paths,
+		"security": []interface{}{map[string]interface{}{"bearer": []string{}}},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+			"securitySchemes": map[string]interface{}{
+				"bearer": map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}, "", "  ")
+}
+
+// OpenAPIHandler serves the OpenAPI document of the registered RPCs.
+func (g *Gateway) OpenAPIHandler(title string, version string) (http.Handler, error) {
+	doc, err := g.OpenAPI(title, version)
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(doc)
+	}), nil
+}
+
+func jsonContent(description string, schema protoreflect.FullName) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"$ref": schemaRef + string(schema)},
+			},
+		},
+	}
+}
+
+// objectContent is the content of JSON of routes, which have no schema in tks-proto.
+func objectContent(description string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{"type": "object"},
+			},
+		},
+	}
+}
+
+func queryParameters(md protoreflect.MessageDescriptor) []interface{} {
+	parameters := []interface{}{}
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsList() || fd.IsMap() || fd.Kind() == protoreflect.MessageKind {
+			continue
+		}
+		parameters = append(parameters, map[string]interface{}{
+			"name":   string(fd.Name()),
+			"in":     "query",
+			"schema": scalarSchema(fd),
+		})
+	}
+	return parameters
+}
+
+// addSchema adds the schemas of the message and the messages in its fields.
+func addSchema(schemas map[string]interface{}, md protoreflect.MessageDescriptor) {
+	name := string(md.FullName())
+	if _, ok := schemas[name]; ok || wellKnownSchema(md) != nil {
+		return
+	}
+	properties := map[string]interface{}{}
+	schemas[name] = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		properties[string(fd.Name())] = fieldSchema(schemas, fd)
+	}
+}
+
+func fieldSchema(schemas map[string]interface{}, fd protoreflect.FieldDescriptor) map[string]interface{} {
+	switch {
+	case fd.IsMap():
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": valueSchema(schemas, fd.MapValue()),
+		}
+	case fd.IsList():
+		return map[string]interface{}{
+			"type":  "array",
+			"items": valueSchema(schemas, fd),
+		}
+	}
+	return valueSchema(schemas, fd)
+}
+
+func valueSchema(schemas map[string]interface{}, fd protoreflect.FieldDescriptor) map[string]interface{} {
+	if fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind {
+		md := fd.Message()
+		if schema := wellKnownSchema(md); schema != nil {
+			return schema
+		}
+		addSchema(schemas, md)
+		return map[string]interface{}{"$ref": schemaRef + string(md.FullName())}
+	}
+	return scalarSchema(fd)
+}
+
+// wellKnownSchema returns the schema of the JSON of a well-known type, or nil for other messages.
+func wellKnownSchema(md protoreflect.MessageDescriptor) map[string]interface{} {
+	switch md.FullName() {
+	case "google.protobuf.Timestamp":
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case "google.protobuf.Duration":
+		return map[string]interface{}{"type": "string"}
+	case "google.protobuf.Struct":
+		return map[string]interface{}{"type": "object"}
+	case "google.protobuf.Value":
+		return map[string]interface{}{}
+	}
+	return nil
+}
+
+// scalarSchema returns the schema of a scalar field as protojson writes it.
+func scalarSchema(fd protoreflect.FieldDescriptor) map[string]interface{} {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]interface{}{"type": "integer", "format": "int64"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return map[string]interface{}{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind:
+		return map[string]interface{}{"type": "number", "format": "float"}
+	case protoreflect.DoubleKind:
+		return map[string]interface{}{"type": "number", "format": "double"}
+	case protoreflect.BytesKind:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case protoreflect.EnumKind:
+		values := fd.Enum().Values()
+		names := make([]string, 0, values.Len())
+		for i := 0; i < values.Len(); i++ {
+			names = append(names, string(values.Get(i).Name()))
+		}
+		return map[string]interface{}{"type": "string", "enum": names}
+	}
+	return map[string]interface{}{"type": "string"}
+}
@@ -0,0 +1,199 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Route is an endpoint of the gateway which has no RPC in tks-proto.
+// It is served at /v1/<service>/<method> like RPCs, with POST of the JSON request, and runs
+// through the interceptor with the full method name /<Service>/<Method>, so that
+// authorization, rate limits and audit logs apply to it as they do to RPCs.
+type Route struct {
+	// Service is the full name of the service, such as "tks_info.InventoryService".
+	Service string
+	Method  string
+	// Summary describes the route in the OpenAPI document.
+	Summary string
+	// NewRequest returns a pointer to the value which the request is decoded into.
+	NewRequest func() interface{}
+	// Handler returns the response, which is written as JSON with proto messages in it
+	// written as they are in responses of RPCs. Errors should be gRPC statuses.
+	Handler func(ctx context.Context, req interface{}) (interface{}, error)
+}
+
+// FullMethod returns the name of the route which the interceptor is called with.
+func (r Route) FullMethod() string {
+	return "/" + r.Service + "/" + r.Method
+}
+
+func (r Route) path() string {
+	return r.Service[strings.LastIndex(r.Service, ".")+1:] + "/" + r.Method
+}
+
+// HandleRoute registers the route. It panics if the path is already registered.
+func (g *Gateway) HandleRoute(route Route) {
+	path := route.path()
+	if _, ok := g.methods[path]; ok {
+		panic(fmt.Sprintf("gateway: %s is already registered", path))
+	}
+	if _, ok := g.routes[path]; ok {
+		panic(fmt.Sprintf("gateway: %s is already registered", path))
+	}
+	g.routes[path] = route
+}
+
+func (g *Gateway) serveRoute(w http.ResponseWriter, r *http.Request, route Route) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeStatus(w, http.StatusMethodNotAllowed, status.Newf(codes.Unimplemented, "method %s is not allowed", r.Method))
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, g.maxBodySize))
+	if err != nil {
+		writeError(w, status.Errorf(codes.ResourceExhausted, "failed to read request: %v", err))
+		return
+	}
+	req := route.NewRequest()
+	if len(body) > 0 {
+		dec := json.NewDecoder(bytes.NewReader(body))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(req); err != nil {
+			writeError(w, status.Errorf(codes.InvalidArgument, "invalid request: %v", err))
+			return
+		}
+	}
+
+	ctx := incomingContext(r)
+	var res interface{}
+	if g.interceptor == nil {
+		res, err = route.Handler(ctx, req)
+	} else {
+		info := &grpc.UnaryServerInfo{FullMethod: route.FullMethod()}
+		res, err = g.interceptor(ctx, req, info, route.Handler)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	value, err := jsonValue(reflect.ValueOf(res))
+	if err != nil {
+		writeError(w, status.Error(codes.Internal, err.Error()))
+		return
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		writeError(w, status.Error(codes.Internal, err.Error()))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(b)
+}
+
+var messageMarshaler = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// jsonValue converts v to a value which encoding/json writes like v, except that
+// proto messages and enums are written as protojson writes them in responses of RPCs.
+func jsonValue(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil, nil
+	}
+	if v.CanInterface() {
+		switch x := v.Interface().(type) {
+		case proto.Message:
+			b, err := messageMarshaler.Marshal(x)
+			return json.RawMessage(b), err
+		case protoreflect.Enum:
+			// Generated enums write their names, or numbers if unknown.
+			return fmt.Sprint(x), nil
+		case json.Marshaler, encoding.TextMarshaler:
+			return x, nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return jsonValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return v.Interface(), nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := jsonValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item, err := jsonValue(iter.Value())
+			if err != nil {
+				return nil, err
+			}
+			m[fmt.Sprint(iter.Key().Interface())] = item
+		}
+		return m, nil
+	case reflect.Struct:
+		return structValue(v)
+	}
+	return v.Interface(), nil
+}
+
+// structValue converts the exported fields of a struct, following their json tags.
+func structValue(v reflect.Value) (interface{}, error) {
+	m := map[string]interface{}{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		omitEmpty := false
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				omitEmpty = omitEmpty || opt == "omitempty"
+			}
+		}
+		if omitEmpty && v.Field(i).IsZero() {
+			continue
+		}
+		value, err := jsonValue(v.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		m[name] = value
+	}
+	return m, nil
+}
@@ -0,0 +1,44 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"gorm.io/gorm"
+)
+
+// DBCheck returns a check which pings the database.
+func DBCheck(db *gorm.DB) CheckFunc {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// ConnCheck returns a check which fails while the client connection cannot reach its server.
+// An idle connection, such as one which never connected, is asked to connect, and the check
+// waits for the attempt to finish within the check timeout.
+func ConnCheck(conn *grpc.ClientConn) CheckFunc {
+	return func(ctx context.Context) error {
+		for {
+			state := conn.GetState()
+			switch state {
+			case connectivity.Ready:
+				return nil
+			case connectivity.Idle:
+				conn.Connect()
+			case connectivity.Connecting:
+			default:
+				return fmt.Errorf("connection to %s is %s", conn.Target(), state)
+			}
+			if !conn.WaitForStateChange(ctx, state) {
+				return fmt.Errorf("connection to %s is %s", conn.Target(), conn.GetState())
+			}
+		}
+	}
+}
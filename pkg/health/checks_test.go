@@ -0,0 +1,39 @@
+package health_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/openinfradev/tks-info/pkg/health"
+)
+
+func TestConnCheck(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	go server.Serve(lis)
+	defer server.Stop()
+
+	check := func(target string) error {
+		conn, err := grpc.Dial(target, grpc.WithInsecure())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		return health.ConnCheck(conn)(ctx)
+	}
+
+	// Connections start idle, and are checked after connecting.
+	require.NoError(t, check(lis.Addr().String()))
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, closed.Close())
+	require.Error(t, check(closed.Addr().String()))
+}
@@ -0,0 +1,109 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+)
+
+// CheckFunc returns an error if a dependency of the server is not healthy.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	services []string
+}
+
+// Prober periodically runs checks and reflects the results to the health server.
+// The overall status ("") is NOT_SERVING if any check fails, and each service
+// is NOT_SERVING if one of the checks it depends on fails.
+type Prober struct {
+	server   *health.Server
+	services []string
+	interval time.Duration
+	timeout  time.Duration
+
+	mu     sync.Mutex
+	checks []check
+	errs   map[string]error
+}
+
+// NewProber returns new Prober which updates the status of services on server.
+func NewProber(server *health.Server, services []string, interval time.Duration, timeout time.Duration) *Prober {
+	return &Prober{
+		server:   server,
+		services: services,
+		interval: interval,
+		timeout:  timeout,
+		errs:     map[string]error{},
+	}
+}
+
+// AddCheck registers a check. If services is empty, every service depends on it.
+func (p *Prober) AddCheck(name string, fn CheckFunc, services ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(services) == 0 {
+		services = p.services
+	}
+	p.checks = append(p.checks, check{name: name, fn: fn, services: services})
+}
+
+// Probe runs every check once and updates the health server.
+func (p *Prober) Probe(ctx context.Context) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	failed := map[string]bool{}
+	overall := healthpb.HealthCheckResponse_SERVING
+	for _, c := range p.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		err := c.fn(checkCtx)
+		cancel()
+
+		if err != nil {
+			if p.errs[c.name] == nil {
+				log.Warn("health check ", c.name, " failed. err : ", err)
+			}
+			overall = healthpb.HealthCheckResponse_NOT_SERVING
+			for _, service := range c.services {
+				failed[service] = true
+			}
+		} else if p.errs[c.name] != nil {
+			log.Info("health check ", c.name, " recovered")
+		}
+		p.errs[c.name] = err
+	}
+
+	p.server.SetServingStatus("", overall)
+	for _, service := range p.services {
+		status := healthpb.HealthCheckResponse_SERVING
+		if failed[service] {
+			status = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		p.server.SetServingStatus(service, status)
+	}
+}
+
+// Run probes at every interval until ctx is done.
+func (p *Prober) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	p.Probe(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.Probe(ctx)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/health"
+)
+
+func init() {
+	log.Disable()
+}
+
+func getStatus(t *testing.T, server *grpchealth.Server, service string) healthpb.HealthCheckResponse_ServingStatus {
+	res, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return res.GetStatus()
+}
+
+func TestProbe(t *testing.T) {
+	server := grpchealth.NewServer()
+	prober := health.NewProber(server, []string{"a", "b"}, time.Second, time.Second)
+
+	var dbErr, contractErr error
+	prober.AddCheck("db", func(ctx context.Context) error { return dbErr })
+	prober.AddCheck("contract", func(ctx context.Context) error { return contractErr }, "b")
+
+	prober.Probe(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, getStatus(t, server, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, getStatus(t, server, "a"))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, getStatus(t, server, "b"))
+
+	// Only the services depending on the failed check stop serving.
+	contractErr = errors.New("unreachable")
+	prober.Probe(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, getStatus(t, server, ""))
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, getStatus(t, server, "a"))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, getStatus(t, server, "b"))
+
+	dbErr = errors.New("connection refused")
+	contractErr = nil
+	prober.Probe(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, getStatus(t, server, "a"))
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, getStatus(t, server, "b"))
+
+	dbErr = nil
+	prober.Probe(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, getStatus(t, server, ""))
+}
+
+func TestProbeTimeout(t *testing.T) {
+	server := grpchealth.NewServer()
+	prober := health.NewProber(server, []string{"a"}, time.Second, 10*time.Millisecond)
+	prober.AddCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	prober.Probe(context.Background())
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, getStatus(t, server, "a"))
+}
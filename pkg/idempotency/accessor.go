@@ -0,0 +1,74 @@
+package idempotency
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	model "github.com/openinfradev/tks-info/pkg/idempotency/model"
+)
+
+// Accessor accesses to idempotency keys.
+type Accessor struct {
+	db *gorm.DB
+}
+
+// New returns new Accessor to access idempotency keys.
+func New(db *gorm.DB) *Accessor {
+	return &Accessor{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *Accessor) WithContext(ctx context.Context) *Accessor {
+	return &Accessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
+// Reserve inserts the key unless a key with the same ID, which has not expired at now, exists.
+// It returns true if the key is inserted, or false and the existing key.
+func (x *Accessor) Reserve(key model.IdempotencyKey, now time.Time) (bool, model.IdempotencyKey, error) {
+	var existing model.IdempotencyKey
+	reserved := false
+	err := x.db.Transaction(func(tx *gorm.DB) error {
+		if res := tx.Where("id = ? AND expires_at <= ?", key.ID, now).Delete(&model.IdempotencyKey{}); res.Error != nil {
+			return res.Error
+		}
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&key)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 1 {
+			reserved = true
+			return nil
+		}
+		return tx.First(&existing, "id = ?", key.ID).Error
+	})
+	if err != nil {
+		return false, model.IdempotencyKey{}, err
+	}
+	return reserved, existing, nil
+}
+
+// Complete stores the response of the reserved key, which is kept until expiresAt.
+func (x *Accessor) Complete(id string, responseType string, response []byte, expiresAt time.Time) error {
+	return x.db.Model(&model.IdempotencyKey{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"response_type": responseType, "response": response, "expires_at": expiresAt}).
+		Error
+}
+
+// Release deletes the reserved key, so that the call can be retried with it.
+func (x *Accessor) Release(id string) error {
+	return x.db.Where("id = ? AND response_type = ''", id).Delete(&model.IdempotencyKey{}).Error
+}
+
+// DeleteExpired deletes the keys which expired at now, and returns the number of them.
+func (x *Accessor) DeleteExpired(now time.Time) (int64, error) {
+	res := x.db.Where("expires_at <= ?", now).Delete(&model.IdempotencyKey{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,144 @@
+package idempotency_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+
+	"github.com/openinfradev/tks-info/pkg/idempotency"
+	"github.com/openinfradev/tks-info/pkg/idempotency/model"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+var (
+	accessor   *idempotency.Accessor
+	testDBHost string
+	testDBPort string
+)
+
+func init() {
+	log.Disable()
+}
+
+func getAccessor() (*idempotency.Accessor, error) {
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Seoul",
+		testDBHost, "postgres", "password", "tks", testDBPort)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&model.IdempotencyKey{}); err != nil {
+		return nil, err
+	}
+
+	return idempotency.New(db), nil
+}
+
+func TestMain(m *testing.M) {
+	pool, resource, err := helper.CreatePostgres()
+	if err != nil {
+		fmt.Printf("Could not create postgres: %s", err)
+		os.Exit(-1)
+	}
+	testDBHost, testDBPort = helper.GetHostAndPort(resource)
+	accessor, _ = getAccessor()
+
+	code := m.Run()
+
+	if err := helper.RemovePostgres(pool, resource); err != nil {
+		fmt.Printf("Could not remove postgres: %s", err)
+		os.Exit(-1)
+	}
+	os.Exit(code)
+}
+
+func TestReserve(t *testing.T) {
+	now := time.Now()
+	key := model.IdempotencyKey{ID: "reserve", RequestHash: "hash", ExpiresAt: now.Add(time.Minute)}
+
+	reserved, _, err := accessor.Reserve(key, now)
+	if err != nil || !reserved {
+		t.Fatalf("Failed to reserve a new key. reserved: %v, err: %v", reserved, err)
+	}
+	reserved, existing, err := accessor.Reserve(key, now)
+	if err != nil || reserved || existing.RequestHash != "hash" {
+		t.Fatalf("Reserved a key in use. reserved: %v, err: %v", reserved, err)
+	}
+
+	// An expired key can be reserved again.
+	reserved, _, err = accessor.Reserve(key, now.Add(time.Hour))
+	if err != nil || !reserved {
+		t.Fatalf("Failed to reserve an expired key. reserved: %v, err: %v", reserved, err)
+	}
+
+	if err := accessor.Complete(key.ID, "tks_pb.IDResponse", []byte{}, now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("Failed to complete the key. err: %v", err)
+	}
+	// Completed keys are not released.
+	if err := accessor.Release(key.ID); err != nil {
+		t.Fatalf("Failed to release the key. err: %v", err)
+	}
+	n, err := accessor.DeleteExpired(now.Add(3 * time.Hour))
+	if err != nil || n != 1 {
+		t.Errorf("Failed to delete the expired key. n: %d, err: %v", n, err)
+	}
+}
+
+func TestGuard(t *testing.T) {
+	method := "/tks_pb.ClusterInfoService/AddClusterInfo"
+	interceptor := idempotency.NewGuard(accessor, time.Hour, method).UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	calls := 0
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		calls++
+		if calls == 1 {
+			return &pb.IDResponse{Code: pb.Code_INTERNAL}, errors.New("temporary failure")
+		}
+		return &pb.IDResponse{Code: pb.Code_OK_UNSPECIFIED, Id: fmt.Sprintf("cluster-%d", calls)}, nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(idempotency.MetadataKey, "workflow-step-1"))
+	req := &pb.AddClusterInfoRequest{ContractId: "contract", Name: "cluster"}
+
+	// A failed call can be retried with the same key.
+	if _, err := interceptor(ctx, req, info, handler); err == nil {
+		t.Fatal("The error of the handler was not returned")
+	}
+	res, err := interceptor(ctx, req, info, handler)
+	if err != nil || res.(*pb.IDResponse).GetId() != "cluster-2" {
+		t.Fatalf("Unexpected result: %v, err: %v", res, err)
+	}
+
+	// A successful call is replayed.
+	res, err = interceptor(ctx, req, info, handler)
+	if err != nil || res.(*pb.IDResponse).GetId() != "cluster-2" || calls != 2 {
+		t.Fatalf("Result is not replayed: %v, calls: %d, err: %v", res, calls, err)
+	}
+
+	// The key can't be reused for another request.
+	_, err = interceptor(ctx, &pb.AddClusterInfoRequest{ContractId: "contract", Name: "other"}, info, handler)
+	if status.Code(err) != codes.FailedPrecondition {
+		t.Errorf("Unexpected error for a reused key: %v", err)
+	}
+
+	// Calls without keys are not replayed.
+	res, err = interceptor(context.Background(), req, info, handler)
+	if err != nil || res.(*pb.IDResponse).GetId() != "cluster-3" {
+		t.Errorf("Unexpected result without key: %v, err: %v", res, err)
+	}
+}
@@ -0,0 +1,176 @@
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/auth"
+	model "github.com/openinfradev/tks-info/pkg/idempotency/model"
+)
+
+// MetadataKey is the gRPC metadata key of idempotency keys.
+const MetadataKey = "idempotency-key"
+
+const (
+	maxKeyLength = 255
+	// pendingTimeout is how long a key stays reserved by a call which neither completes nor fails,
+	// such as one interrupted by a restart.
+	pendingTimeout = 5 * time.Minute
+)
+
+// Guard returns the result of the first successful call for calls of the same RPC
+// by the same caller with the same idempotency key, until the result expires.
+// Failed calls are not kept, so that they can be retried with the same key.
+type Guard struct {
+	accessor *Accessor
+	methods  map[string]bool
+	ttl      time.Duration
+	now      func() time.Time
+}
+
+// NewGuard returns new Guard for the RPCs of full method names, which keeps results for ttl.
+func NewGuard(accessor *Accessor, ttl time.Duration, methods ...string) *Guard {
+	g := &Guard{
+		accessor: accessor,
+		methods:  map[string]bool{},
+		ttl:      ttl,
+		now:      time.Now,
+	}
+	for _, method := range methods {
+		g.methods[method] = true
+	}
+	return g
+}
+
+// DeleteExpired deletes expired results. It is run periodically by a single replica.
+func (g *Guard) DeleteExpired(ctx context.Context) error {
+	n, err := g.accessor.WithContext(ctx).DeleteExpired(g.now())
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		log.Debug("deleted ", n, " expired idempotency keys")
+	}
+	return nil
+}
+
+// UnaryServerInterceptor replays results of calls with the idempotency key in MetadataKey.
+// It must run after authentication to tell callers apart.
+func (g *Guard) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !g.methods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		values := md.Get(MetadataKey)
+		if len(values) == 0 || values[0] == "" {
+			return handler(ctx, req)
+		}
+		key := values[0]
+		if len(key) > maxKeyLength {
+			return nil, status.Errorf(codes.InvalidArgument, "%s is longer than %d", MetadataKey, maxKeyLength)
+		}
+		msg, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		requestHash, err := hashRequest(msg)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		caller := auth.Subject(ctx)
+		now := g.now()
+		reservation := model.IdempotencyKey{
+			ID:          hash(info.FullMethod, caller, key),
+			Method:      info.FullMethod,
+			Caller:      caller,
+			RequestHash: requestHash,
+			ExpiresAt:   now.Add(pendingTimeout),
+		}
+		reserved, existing, err := g.accessor.WithContext(ctx).Reserve(reservation, now)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		if !reserved {
+			return replay(existing, requestHash, info.FullMethod)
+		}
+
+		res, err := handler(ctx, req)
+
+		// Keep the result even if the call is cancelled, keeping the trace of the call.
+		storeCtx := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+		if err != nil {
+			if err := g.accessor.WithContext(storeCtx).Release(reservation.ID); err != nil {
+				log.Error("failed to release idempotency key of ", info.FullMethod, ". err : ", err)
+			}
+			return res, err
+		}
+		if err := g.complete(storeCtx, reservation.ID, res); err != nil {
+			log.Error("failed to keep result of ", info.FullMethod, " for idempotency key. err : ", err)
+		}
+		return res, err
+	}
+}
+
+func (g *Guard) complete(ctx context.Context, id string, res interface{}) error {
+	msg, ok := res.(proto.Message)
+	if !ok {
+		return g.accessor.WithContext(ctx).Release(id)
+	}
+	response, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	responseType := string(msg.ProtoReflect().Descriptor().FullName())
+	return g.accessor.WithContext(ctx).Complete(id, responseType, response, g.now().Add(g.ttl))
+}
+
+func replay(key model.IdempotencyKey, requestHash string, method string) (interface{}, error) {
+	if key.RequestHash != requestHash {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s was used for a different request", MetadataKey)
+	}
+	if key.ResponseType == "" {
+		return nil, status.Errorf(codes.Aborted, "a call with the same %s is in progress", MetadataKey)
+	}
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(key.ResponseType))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	res := mt.New().Interface()
+	if err := proto.Unmarshal(key.Response, res); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	log.Info("replaying result of ", method, " for idempotency key")
+	return res, nil
+}
+
+func hashRequest(msg proto.Message) (string, error) {
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	if err != nil {
+		return "", err
+	}
+	return hash(string(b)), nil
+}
+
+func hash(values ...string) string {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
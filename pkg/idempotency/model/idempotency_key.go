@@ -0,0 +1,21 @@
+package model
+
+import (
+	"time"
+)
+
+// IdempotencyKey is the result of an RPC called with an idempotency key.
+// A key without a response is reserved by a call still in progress.
+type IdempotencyKey struct {
+	// ID is the hash of the RPC, the caller and the idempotency key.
+	ID     string `gorm:"primarykey"`
+	Method string
+	Caller string
+	// RequestHash is the hash of the request, to tell a replay from a reuse of the key.
+	RequestHash  string
+	ResponseType string
+	Response     []byte
+	ExpiresAt    time.Time `gorm:"index"`
+	UpdatedAt    time.Time
+	CreatedAt    time.Time
+}
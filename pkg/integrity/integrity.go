@@ -0,0 +1,55 @@
+package integrity
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
+	cspModel "github.com/openinfradev/tks-info/pkg/csp_info/model"
+)
+
+var (
+	// ErrNotFound is returned when a referenced resource does not exist.
+	ErrNotFound = errors.New("referenced resource not found")
+	// ErrContractMismatch is returned when a referenced resource belongs to another contract.
+	ErrContractMismatch = errors.New("referenced resource belongs to another contract")
+	// ErrInUse is returned when a resource which other resources still refer to is deleted.
+	ErrInUse = errors.New("resource is still in use")
+)
+
+// GetCluster returns the cluster referenced by id.
+func GetCluster(db *gorm.DB, id string) (*clusterModel.Cluster, error) {
+	var cluster clusterModel.Cluster
+	res := db.Select("id", "contract_id", "csp_id").First(&cluster, "id = ?", id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: cluster %s", ErrNotFound, id)
+	} else if res.Error != nil {
+		return nil, res.Error
+	}
+	return &cluster, nil
+}
+
+// GetCSPInfo returns the CSP info referenced by id.
+func GetCSPInfo(db *gorm.DB, id uuid.UUID) (*cspModel.CSPInfo, error) {
+	var cspInfo cspModel.CSPInfo
+	res := db.Select("id", "contract_id").First(&cspInfo, "id = ?", id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: csp %s", ErrNotFound, id)
+	} else if res.Error != nil {
+		return nil, res.Error
+	}
+	return &cspInfo, nil
+}
+
+// CheckContract returns ErrContractMismatch if the referenced resource
+// does not belong to the contract.
+func CheckContract(kind string, id string, contractId string, referencedContractId string) error {
+	if contractId != referencedContractId {
+		return fmt.Errorf("%w: %s %s belongs to contract %s, not %s",
+			ErrContractMismatch, kind, id, referencedContractId, contractId)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	asa "github.com/openinfradev/tks-info/pkg/app_serve_app"
+	"github.com/openinfradev/tks-info/pkg/application"
+	"github.com/openinfradev/tks-info/pkg/cluster"
+	"github.com/openinfradev/tks-info/pkg/csp_info"
+	"github.com/openinfradev/tks-info/pkg/keycloak_info"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// Inventory is every resource which belongs to a contract.
+type Inventory struct {
+	ContractId   string            `json:"contract_id"`
+	Csps         []*CspInventory   `json:"csps"`
+	AppServeApps []*pb.AppServeApp `json:"app_serve_apps"`
+	// Clusters whose CSP is not registered in the contract.
+	UnassignedClusters []*ClusterInventory `json:"unassigned_clusters"`
+}
+
+// CspInventory is a CSP and the clusters created on it.
+// Auth of the CSP is not included on purpose.
+type CspInventory struct {
+	Id       string              `json:"id"`
+	Name     string              `json:"name"`
+	CspType  pb.CspType          `json:"csp_type"`
+	Clusters []*ClusterInventory `json:"clusters"`
+}
+
+// ClusterInventory is a cluster and the resources installed on it.
+// Cluster.AppGroups is filled with the application groups of the cluster.
+type ClusterInventory struct {
+	Cluster *pb.Cluster `json:"cluster"`
+	// Applications are the applications of each application group by its id.
+	Applications  map[string][]*pb.Application `json:"applications"`
+	KeycloakInfos []*pb.KeycloakInfo           `json:"keycloak_infos"`
+}
+
+// StripSecrets clears kubeconfigs of the clusters and secrets and private keys of the keycloak infos.
+func (inv *Inventory) StripSecrets() {
+	clusters := append([]*ClusterInventory{}, inv.UnassignedClusters...)
+	for _, csp := range inv.Csps {
+		clusters = append(clusters, csp.Clusters...)
+	}
+	for _, ci := range clusters {
+		ci.Cluster.Kubeconfig = ""
+		for _, k := range ci.KeycloakInfos {
+			k.Secret = ""
+			k.PrivateKey = ""
+		}
+	}
+}
+
+// InventoryAccessor loads the whole resource tree of a contract.
+type InventoryAccessor struct {
+	csp      *csp_info.CspInfoAccessor
+	cluster  *cluster.ClusterAccessor
+	app      *application.Accessor
+	asa      *asa.AsaAccessor
+	keycloak *keycloak_info.KeycloakInfoAccessor
+}
+
+// New returns new Accessor to load inventories.
+func New(db *gorm.DB) *InventoryAccessor {
+	return &InventoryAccessor{
+		csp:      csp_info.New(db),
+		cluster:  cluster.New(db),
+		app:      application.New(db),
+		asa:      asa.New(db),
+		keycloak: keycloak_info.New(db),
+	}
+}
+
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *InventoryAccessor) WithContext(ctx context.Context) *InventoryAccessor {
+	return &InventoryAccessor{
+		csp:      x.csp.WithContext(ctx),
+		cluster:  x.cluster.WithContext(ctx),
+		app:      x.app.WithContext(ctx),
+		asa:      x.asa.WithContext(ctx),
+		keycloak: x.keycloak.WithContext(ctx),
+	}
+}
+
+// GetInventory returns the resource tree of the contract.
+// Each kind of resource is loaded with a single query regardless of the number of clusters.
+func (x *InventoryAccessor) GetInventory(contractId string, showAllAppServeApps bool) (*Inventory, error) {
+	cspInfos, err := x.csp.GetCSPInfosByContractID(contractId)
+	if err != nil {
+		return nil, err
+	}
+
+	clusters, err := x.cluster.GetClustersByContractID(contractId)
+	if err != nil {
+		return nil, err
+	}
+
+	clusterIds := []string{}
+	for _, c := range clusters {
+		clusterIds = append(clusterIds, c.GetId())
+	}
+
+	appGroups, err := x.app.GetAppGroupsByClusterIDs(clusterIds)
+	if err != nil {
+		return nil, fmt.Errorf("Error while finding application groups of contract %s. Err: %s", contractId, err)
+	}
+
+	appGroupIds := []string{}
+	for _, appGroup := range appGroups {
+		appGroupIds = append(appGroupIds, appGroup.GetAppGroupId())
+	}
+
+	apps, err := x.app.GetAppsByAppGroupIDs(appGroupIds)
+	if err != nil {
+		return nil, fmt.Errorf("Error while finding applications of contract %s. Err: %s", contractId, err)
+	}
+
+	keycloakInfos, err := x.keycloak.GetKeycloakInfosByClusterIDs(clusterIds)
+	if err != nil {
+		return nil, err
+	}
+
+	appServeApps, err := x.asa.GetAppServeApps(contractId, showAllAppServeApps)
+	if err != nil {
+		return nil, err
+	}
+
+	// Assemble the tree from the bottom up.
+	appsByAppGroup := map[string][]*pb.Application{}
+	for _, app := range apps {
+		appsByAppGroup[app.GetAppGroupId()] = append(appsByAppGroup[app.GetAppGroupId()], app)
+	}
+
+	clusterInventories := map[string]*ClusterInventory{}
+	for _, c := range clusters {
+		clusterInventories[c.GetId()] = &ClusterInventory{
+			Cluster:       c,
+			Applications:  map[string][]*pb.Application{},
+			KeycloakInfos: []*pb.KeycloakInfo{},
+		}
+	}
+	for _, appGroup := range appGroups {
+		ci, ok := clusterInventories[appGroup.GetClusterId()]
+		if !ok {
+			continue
+		}
+		ci.Cluster.AppGroups = append(ci.Cluster.AppGroups, appGroup)
+		ci.Applications[appGroup.GetAppGroupId()] = appsByAppGroup[appGroup.GetAppGroupId()]
+	}
+	for _, keycloakInfo := range keycloakInfos {
+		if ci, ok := clusterInventories[keycloakInfo.GetClusterId()]; ok {
+			ci.KeycloakInfos = append(ci.KeycloakInfos, keycloakInfo)
+		}
+	}
+
+	inventory := &Inventory{
+		ContractId:         contractId,
+		Csps:               []*CspInventory{},
+		AppServeApps:       appServeApps,
+		UnassignedClusters: []*ClusterInventory{},
+	}
+
+	cspInventories := map[string]*CspInventory{}
+	for _, cspInfo := range cspInfos {
+		cspInventory := &CspInventory{
+			Id:       cspInfo.ID.String(),
+			Name:     cspInfo.Name,
+			CspType:  cspInfo.CspType,
+			Clusters: []*ClusterInventory{},
+		}
+		cspInventories[cspInventory.Id] = cspInventory
+		inventory.Csps = append(inventory.Csps, cspInventory)
+	}
+
+	// Keep the order of clusters as they were loaded.
+	for _, c := range clusters {
+		ci := clusterInventories[c.GetId()]
+		if cspInventory, ok := cspInventories[c.GetCspId()]; ok {
+			cspInventory.Clusters = append(cspInventory.Clusters, ci)
+		} else {
+			inventory.UnassignedClusters = append(inventory.UnassignedClusters, ci)
+		}
+	}
+
+	return inventory, nil
+}
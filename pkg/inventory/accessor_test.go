@@ -0,0 +1,155 @@
+package inventory_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+
+	asa "github.com/openinfradev/tks-info/pkg/app_serve_app"
+	modelAsa "github.com/openinfradev/tks-info/pkg/app_serve_app/model"
+	"github.com/openinfradev/tks-info/pkg/application"
+	modelApplication "github.com/openinfradev/tks-info/pkg/application/model"
+	"github.com/openinfradev/tks-info/pkg/cluster"
+	modelCluster "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/csp_info"
+	modelCspInfo "github.com/openinfradev/tks-info/pkg/csp_info/model"
+	"github.com/openinfradev/tks-info/pkg/inventory"
+	"github.com/openinfradev/tks-info/pkg/keycloak_info"
+	modelKeycloakInfo "github.com/openinfradev/tks-info/pkg/keycloak_info/model"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+var (
+	db                *gorm.DB
+	inventoryAccessor *inventory.InventoryAccessor
+)
+
+var (
+	testDBHost string
+	testDBPort string
+)
+
+func init() {
+	log.Disable()
+}
+
+func getDB() (*gorm.DB, error) {
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Seoul",
+		testDBHost, "postgres", "password", "tks", testDBPort)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
+
+	if err := db.AutoMigrate(
+		&modelCspInfo.CSPInfo{},
+		&modelCspInfo.CSPAuth{},
+		&modelCluster.Cluster{},
+		&modelApplication.ApplicationGroup{},
+		&modelApplication.Application{},
+		&modelKeycloakInfo.KeycloakInfo{},
+		&modelAsa.AppServeApp{},
+		&modelAsa.AppServeAppTask{},
+	); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func TestMain(m *testing.M) {
+	pool, resource, err := helper.CreatePostgres()
+	if err != nil {
+		fmt.Printf("Could not create postgres: %s", err)
+		os.Exit(-1)
+	}
+	testDBHost, testDBPort = helper.GetHostAndPort(resource)
+	db, err = getDB()
+	if err != nil {
+		fmt.Printf("Could not initialize database: %s", err)
+		os.Exit(-1)
+	}
+	inventoryAccessor = inventory.New(db)
+
+	code := m.Run()
+
+	if err := helper.RemovePostgres(pool, resource); err != nil {
+		fmt.Printf("Could not remove postgres: %s", err)
+		os.Exit(-1)
+	}
+	os.Exit(code)
+}
+
+func TestGetInventory(t *testing.T) {
+	contractId := helper.GenerateContractId()
+
+	cspId, err := csp_info.New(db).Create(contractId, "csp", "auth", pb.CspType_AWS)
+	require.NoError(t, err)
+
+	conf := &pb.ClusterConf{}
+	clusterIds := []string{}
+	for i := 0; i < 2; i++ {
+		clusterId, err := cluster.New(db).CreateClusterInfo(contractId, cspId, fmt.Sprintf("cluster-%d", i), conf, uuid.Nil, "")
+		require.NoError(t, err)
+		clusterIds = append(clusterIds, clusterId)
+	}
+	// A cluster whose CSP was registered to another contract by mistake.
+	unassignedCluster := modelCluster.Cluster{ContractID: contractId, CspID: uuid.New(), Name: "unassigned"}
+	require.NoError(t, db.Create(&unassignedCluster).Error)
+	unassignedClusterId := unassignedCluster.ID
+
+	appAccessor := application.New(db)
+	appGroupId, err := appAccessor.Create(clusterIds[0], &pb.AppGroup{AppGroupName: "lma", Type: pb.AppGroupType_LMA})
+	require.NoError(t, err)
+	require.NoError(t, appAccessor.UpdateApp(appGroupId, pb.AppType_GRAFANA, "http://grafana", "{}"))
+
+	_, err = keycloak_info.New(db).Create(clusterIds[1], "realm", "clientId", "secret", "privateKey")
+	require.NoError(t, err)
+
+	_, _, err = asa.New(db).Create(contractId,
+		&pb.AppServeApp{Name: "asa", TargetClusterId: clusterIds[0]},
+		&pb.AppServeAppTask{Version: "1"})
+	require.NoError(t, err)
+
+	inv, err := inventoryAccessor.GetInventory(contractId, true)
+	require.NoError(t, err)
+
+	require.Equal(t, contractId, inv.ContractId)
+	require.Len(t, inv.Csps, 1)
+	require.Equal(t, cspId.String(), inv.Csps[0].Id)
+	require.Len(t, inv.Csps[0].Clusters, 2)
+	require.Len(t, inv.UnassignedClusters, 1)
+	require.Equal(t, unassignedClusterId, inv.UnassignedClusters[0].Cluster.GetId())
+	require.Len(t, inv.AppServeApps, 1)
+
+	for _, ci := range inv.Csps[0].Clusters {
+		switch ci.Cluster.GetId() {
+		case clusterIds[0]:
+			require.Len(t, ci.Cluster.GetAppGroups(), 1)
+			require.Len(t, ci.Applications[appGroupId], 1)
+			require.Empty(t, ci.KeycloakInfos)
+		case clusterIds[1]:
+			require.Empty(t, ci.Cluster.GetAppGroups())
+			require.Len(t, ci.KeycloakInfos, 1)
+		}
+	}
+}
+
+func TestGetInventoryEmpty(t *testing.T) {
+	inv, err := inventoryAccessor.GetInventory(helper.GenerateContractId(), false)
+	require.NoError(t, err)
+	require.Empty(t, inv.Csps)
+	require.Empty(t, inv.UnassignedClusters)
+	require.Empty(t, inv.AppServeApps)
+}
@@ -1,11 +1,13 @@
 package keycloak_info
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 
+	"github.com/openinfradev/tks-info/pkg/integrity"
 	model "github.com/openinfradev/tks-info/pkg/keycloak_info/model"
 	pb "github.com/openinfradev/tks-proto/tks_pb"
 )
@@ -20,7 +22,19 @@ func New(db *gorm.DB) *KeycloakInfoAccessor {
 	}
 }
 
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *KeycloakInfoAccessor) WithContext(ctx context.Context) *KeycloakInfoAccessor {
+	return &KeycloakInfoAccessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
+// Create creates new keycloak info for the cluster. The cluster must exist.
 func (x *KeycloakInfoAccessor) Create(clusterId string, realm string, clientId string, secret string, privateKey string) (uuid.UUID, error) {
+	if _, err := integrity.GetCluster(x.db, clusterId); err != nil {
+		return uuid.Nil, err
+	}
+
 	keycloackInfo := model.KeycloakInfo{ClusterId: clusterId, Realm: realm, ClientId: clientId, Secret: secret, PrivateKey: privateKey}
 
 	res := x.db.Create(&keycloackInfo)
@@ -48,6 +62,25 @@ func (x *KeycloakInfoAccessor) GetKeycloakInfos(clusterId string) ([]*pb.Keycloa
 	return pbKeycloakInfos, nil
 }
 
+// GetKeycloakInfosByClusterIDs returns keycloak infos of several clusters at once.
+func (x *KeycloakInfoAccessor) GetKeycloakInfosByClusterIDs(clusterIds []string) ([]*pb.KeycloakInfo, error) {
+	pbKeycloakInfos := []*pb.KeycloakInfo{}
+	if len(clusterIds) == 0 {
+		return pbKeycloakInfos, nil
+	}
+
+	var keycloakInfos []model.KeycloakInfo
+	res := x.db.Find(&keycloakInfos, "cluster_id IN ?", clusterIds)
+	if res.Error != nil {
+		return nil, fmt.Errorf("Error while finding KeycloakInfo with cluster IDs: %v", clusterIds)
+	}
+
+	for _, item := range keycloakInfos {
+		pbKeycloakInfos = append(pbKeycloakInfos, ConvertToPbKeycloakInfo(item))
+	}
+	return pbKeycloakInfos, nil
+}
+
 func ConvertToPbKeycloakInfo(keycloakInfo model.KeycloakInfo) *pb.KeycloakInfo {
 	return &pb.KeycloakInfo{
 		ClusterId:  keycloakInfo.ClusterId,
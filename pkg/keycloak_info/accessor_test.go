@@ -1,6 +1,7 @@
 package keycloak_info_test
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"testing"
@@ -12,6 +13,8 @@ import (
 	"github.com/openinfradev/tks-common/pkg/helper"
 	"github.com/openinfradev/tks-common/pkg/log"
 
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/integrity"
 	"github.com/openinfradev/tks-info/pkg/keycloak_info"
 	"github.com/openinfradev/tks-info/pkg/keycloak_info/model"
 )
@@ -29,8 +32,6 @@ var (
 )
 
 func init() {
-	clusterId = helper.GenerateClusterId()
-
 	log.Disable()
 }
 
@@ -45,10 +46,17 @@ func getAccessor() (*keycloak_info.KeycloakInfoAccessor, error) {
 
 	db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
 
-	if err := db.AutoMigrate(&model.KeycloakInfo{}); err != nil {
+	if err := db.AutoMigrate(&model.KeycloakInfo{}, &clusterModel.Cluster{}); err != nil {
 		return nil, err
 	}
 
+	// Keycloak infos can only be created for an existing cluster.
+	cluster := clusterModel.Cluster{Name: "testCluster"}
+	if res := db.Create(&cluster); res.Error != nil {
+		return nil, res.Error
+	}
+	clusterId = cluster.ID
+
 	return keycloak_info.New(db), nil
 }
 
@@ -76,3 +84,10 @@ func TestCreateKeycloakInfo(t *testing.T) {
 		t.Errorf("An error occurred while creating new cspInfo. Err: %s", err)
 	}
 }
+
+func TestCreateKeycloakInfoWithInvalidCluster(t *testing.T) {
+	_, err := keycloakInfoAccessor.Create(helper.GenerateClusterId(), "realm", "clientId", "secret", "privatekey")
+	if !errors.Is(err, integrity.ErrNotFound) {
+		t.Errorf("An error for not existing cluster was expected, but got: %v", err)
+	}
+}
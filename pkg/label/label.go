@@ -0,0 +1,123 @@
+package label
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/datatypes"
+)
+
+const (
+	maxNameLength   = 63
+	maxPrefixLength = 253
+	// maxAnnotationsSize is the maximum total size of the keys and values of annotations.
+	maxAnnotationsSize = 256 * 1024
+)
+
+var (
+	namePattern   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]*[A-Za-z0-9])?$`)
+	prefixPattern = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+)
+
+// ValidateKey checks that the key is a name of up to 63 characters, optionally prefixed by a DNS subdomain
+// and a slash, such as tks.io/team, as keys of Kubernetes labels are.
+func ValidateKey(key string) error {
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		prefix := key[:i]
+		name = key[i+1:]
+		if len(prefix) > maxPrefixLength || !prefixPattern.MatchString(prefix) {
+			return fmt.Errorf("prefix of key %q must be a DNS subdomain", key)
+		}
+	}
+	if len(name) > maxNameLength || !namePattern.MatchString(name) {
+		return fmt.Errorf("key %q must be up to %d alphanumeric characters, '-', '_' or '.', beginning and ending with an alphanumeric character", key, maxNameLength)
+	}
+	return nil
+}
+
+// ValidateValue checks that the value of a label is empty or up to 63 characters as names of keys.
+func ValidateValue(value string) error {
+	if value != "" && (len(value) > maxNameLength || !namePattern.MatchString(value)) {
+		return fmt.Errorf("value %q must be up to %d alphanumeric characters, '-', '_' or '.', beginning and ending with an alphanumeric character", value, maxNameLength)
+	}
+	return nil
+}
+
+// Validate checks the keys and the values of labels.
+func Validate(labels map[string]string) error {
+	for key, value := range labels {
+		if err := ValidateKey(key); err != nil {
+			return err
+		}
+		if err := ValidateValue(value); err != nil {
+			return fmt.Errorf("label %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ValidateAnnotations checks the keys of annotations, whose values may be any text up to 256KiB in total.
+func ValidateAnnotations(annotations map[string]string) error {
+	size := 0
+	for key, value := range annotations {
+		if err := ValidateKey(key); err != nil {
+			return err
+		}
+		size += len(key) + len(value)
+	}
+	if size > maxAnnotationsSize {
+		return fmt.Errorf("annotations must be up to %d bytes in total", maxAnnotationsSize)
+	}
+	return nil
+}
+
+// Columns validates labels and annotations, and returns the columns to update to them.
+// A nil map is left out, and an empty map clears the column.
+func Columns(labels map[string]string, annotations map[string]string) (map[string]interface{}, error) {
+	columns := map[string]interface{}{}
+	if labels != nil {
+		if err := Validate(labels); err != nil {
+			return nil, err
+		}
+		data, err := Marshal(labels)
+		if err != nil {
+			return nil, err
+		}
+		columns["labels"] = data
+	}
+	if annotations != nil {
+		if err := ValidateAnnotations(annotations); err != nil {
+			return nil, err
+		}
+		data, err := Marshal(annotations)
+		if err != nil {
+			return nil, err
+		}
+		columns["annotations"] = data
+	}
+	return columns, nil
+}
+
+// Marshal returns the JSON of labels or annotations to store, which is NULL if they are empty.
+func Marshal(m map[string]string) (datatypes.JSON, error) {
+	if len(m) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(m)
+	return datatypes.JSON(data), err
+}
+
+// Unmarshal returns labels or annotations stored as JSON, which is an empty map if the column is NULL.
+func Unmarshal(data datatypes.JSON) (map[string]string, error) {
+	m := map[string]string{}
+	if len(data) == 0 {
+		return m, nil
+	}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal labels: %w", err)
+	}
+	return m, nil
+}
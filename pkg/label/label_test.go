@@ -0,0 +1,91 @@
+package label
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/datatypes"
+)
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate(map[string]string{"env": "prod", "tks.io/team": "infra_1", "empty": ""}))
+	for _, labels := range []map[string]string{
+		{"": "prod"},
+		{"-env": "prod"},
+		{"Tks.io/team": "infra"},
+		{strings.Repeat("a", 64): "prod"},
+		{"env": "prod!"},
+		{"env": strings.Repeat("a", 64)},
+	} {
+		require.Error(t, Validate(labels), labels)
+	}
+
+	require.NoError(t, ValidateAnnotations(map[string]string{"description": "any text, even with spaces!"}))
+	require.Error(t, ValidateAnnotations(map[string]string{"big": strings.Repeat("a", maxAnnotationsSize)}))
+}
+
+func TestColumns(t *testing.T) {
+	columns, err := Columns(map[string]string{"env": "prod"}, nil)
+	require.NoError(t, err)
+	require.Len(t, columns, 1)
+	require.JSONEq(t, `{"env":"prod"}`, string(columns["labels"].(datatypes.JSON)))
+
+	columns, err = Columns(nil, map[string]string{})
+	require.NoError(t, err)
+	require.Nil(t, columns["annotations"])
+	require.Contains(t, columns, "annotations")
+
+	labels, err := Unmarshal(nil)
+	require.NoError(t, err)
+	require.Empty(t, labels)
+}
+
+func TestParseSelector(t *testing.T) {
+	s, err := ParseSelector(" env = prod, team!=infra,tier in (web, api),region notin (us),owner,!deprecated ")
+	require.NoError(t, err)
+	require.Equal(t, Selector{
+		{Key: "env", Operator: Equals, Values: []string{"prod"}},
+		{Key: "team", Operator: NotEquals, Values: []string{"infra"}},
+		{Key: "tier", Operator: In, Values: []string{"web", "api"}},
+		{Key: "region", Operator: NotIn, Values: []string{"us"}},
+		{Key: "owner", Operator: Exists},
+		{Key: "deprecated", Operator: DoesNotExist},
+	}, s)
+	require.Equal(t, "env=prod,team!=infra,tier in (api,web),region notin (us),owner,!deprecated", s.String())
+
+	s, err = ParseSelector("env==prod")
+	require.NoError(t, err)
+	require.Equal(t, Selector{{Key: "env", Operator: Equals, Values: []string{"prod"}}}, s)
+
+	s, err = ParseSelector("")
+	require.NoError(t, err)
+	require.Empty(t, s)
+
+	for _, selector := range []string{"env=pr od", "tier in (web,", "=prod", "!", "env in (a b)"} {
+		_, err := ParseSelector(selector)
+		require.Error(t, err, selector)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	labels := map[string]string{"env": "prod", "tier": "web", "owner": "kim"}
+	for selector, matches := range map[string]bool{
+		"":                          true,
+		"env=prod":                  true,
+		"env=dev":                   false,
+		"env!=dev,team!=infra":      true,
+		"tier in (web,api)":         true,
+		"tier notin (web)":          false,
+		"region notin (us)":         true,
+		"owner":                     true,
+		"team":                      false,
+		"!team":                     true,
+		"!owner":                    false,
+		"env=prod,tier in (api,db)": false,
+	} {
+		s, err := ParseSelector(selector)
+		require.NoError(t, err, selector)
+		require.Equal(t, matches, s.Matches(labels), selector)
+	}
+}
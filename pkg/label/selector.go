@@ -0,0 +1,184 @@
+package label
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Operators of requirements.
+const (
+	Equals       = "="
+	NotEquals    = "!="
+	In           = "in"
+	NotIn        = "notin"
+	Exists       = "exists"
+	DoesNotExist = "!"
+)
+
+var setPattern = regexp.MustCompile(`^(\S+)\s+(in|notin)\s*\((.*)\)$`)
+
+// Requirement is a condition on a label.
+type Requirement struct {
+	Key      string
+	Operator string
+	Values   []string
+}
+
+// Selector selects resources whose labels meet all of its requirements, as Kubernetes label selectors do.
+// The empty selector selects every resource.
+type Selector []Requirement
+
+// ParseSelector parses a selector of comma separated requirements, such as
+// "env=prod,team!=infra,tier in (web,api),region notin (us),owner,!deprecated".
+// != and notin select resources without the label too.
+func ParseSelector(selector string) (Selector, error) {
+	s := Selector{}
+	for _, item := range splitRequirements(selector) {
+		r, err := parseRequirement(item)
+		if err != nil {
+			return nil, err
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// splitRequirements splits the selector by commas out of parentheses.
+func splitRequirements(selector string) []string {
+	items := []string{}
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				items = append(items, selector[start:i])
+				start = i + 1
+			}
+		}
+	}
+	items = append(items, selector[start:])
+
+	requirements := []string{}
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			requirements = append(requirements, item)
+		}
+	}
+	return requirements
+}
+
+func parseRequirement(item string) (Requirement, error) {
+	var r Requirement
+	switch {
+	case strings.HasPrefix(item, "!") && !strings.Contains(item, "="):
+		r = Requirement{Key: strings.TrimSpace(item[1:]), Operator: DoesNotExist}
+	case setPattern.MatchString(item):
+		m := setPattern.FindStringSubmatch(item)
+		r = Requirement{Key: m[1], Operator: m[2]}
+		for _, value := range strings.Split(m[3], ",") {
+			r.Values = append(r.Values, strings.TrimSpace(value))
+		}
+	case strings.Contains(item, "!="):
+		parts := strings.SplitN(item, "!=", 2)
+		r = Requirement{Key: strings.TrimSpace(parts[0]), Operator: NotEquals, Values: []string{strings.TrimSpace(parts[1])}}
+	case strings.Contains(item, "=="):
+		parts := strings.SplitN(item, "==", 2)
+		r = Requirement{Key: strings.TrimSpace(parts[0]), Operator: Equals, Values: []string{strings.TrimSpace(parts[1])}}
+	case strings.Contains(item, "="):
+		parts := strings.SplitN(item, "=", 2)
+		r = Requirement{Key: strings.TrimSpace(parts[0]), Operator: Equals, Values: []string{strings.TrimSpace(parts[1])}}
+	default:
+		r = Requirement{Key: item, Operator: Exists}
+	}
+
+	if err := ValidateKey(r.Key); err != nil {
+		return Requirement{}, fmt.Errorf("invalid requirement %q: %w", item, err)
+	}
+	for _, value := range r.Values {
+		if err := ValidateValue(value); err != nil {
+			return Requirement{}, fmt.Errorf("invalid requirement %q: %w", item, err)
+		}
+	}
+	return r, nil
+}
+
+// Matches reports whether the labels meet the requirements of the selector.
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s {
+		value, ok := labels[r.Key]
+		var matches bool
+		switch r.Operator {
+		case Equals, In:
+			matches = ok && contains(r.Values, value)
+		case NotEquals, NotIn:
+			matches = !ok || !contains(r.Values, value)
+		case Exists:
+			matches = ok
+		case DoesNotExist:
+			matches = !ok
+		}
+		if !matches {
+			return false
+		}
+	}
+	return true
+}
+
+// Where adds the requirements of the selector to the conditions of the query on the JSON column of labels.
+// The column is put in SQL as it is, and must not come from users.
+func (s Selector) Where(db *gorm.DB, column string) *gorm.DB {
+	value := column + "->>?"
+	for _, r := range s {
+		switch r.Operator {
+		case Equals:
+			db = db.Where(value+" = ?", r.Key, r.Values[0])
+		case In:
+			db = db.Where(value+" IN ?", r.Key, r.Values)
+		case NotEquals:
+			db = db.Where("("+value+" IS NULL OR "+value+" <> ?)", r.Key, r.Key, r.Values[0])
+		case NotIn:
+			db = db.Where("("+value+" IS NULL OR "+value+" NOT IN ?)", r.Key, r.Key, r.Values)
+		case Exists:
+			db = db.Where(value+" IS NOT NULL", r.Key)
+		case DoesNotExist:
+			db = db.Where(value+" IS NULL", r.Key)
+		}
+	}
+	return db
+}
+
+func (s Selector) String() string {
+	items := []string{}
+	for _, r := range s {
+		switch r.Operator {
+		case Exists:
+			items = append(items, r.Key)
+		case DoesNotExist:
+			items = append(items, "!"+r.Key)
+		case In, NotIn:
+			values := append([]string{}, r.Values...)
+			sort.Strings(values)
+			items = append(items, fmt.Sprintf("%s %s (%s)", r.Key, r.Operator, strings.Join(values, ",")))
+		default:
+			items = append(items, r.Key+r.Operator+r.Values[0])
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package leader
+
+import (
+	"context"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	model "github.com/openinfradev/tks-info/pkg/leader/model"
+)
+
+// acquireQuery inserts the lease, or takes it over if it is held by the replica or has expired.
+// Expiry is decided by the clock of the database, so that clocks of replicas don't have to agree.
+const acquireQuery = `INSERT INTO leases (name, holder, expires_at, updated_at, created_at)
+VALUES (?, ?, now() + make_interval(secs => ?), now(), now())
+ON CONFLICT (name) DO UPDATE
+SET holder = EXCLUDED.holder, expires_at = EXCLUDED.expires_at, updated_at = EXCLUDED.updated_at
+WHERE leases.holder = EXCLUDED.holder OR leases.expires_at < now()`
+
+// Elector elects a leader among the replicas sharing the database with a lease in the leases table.
+// The replica holding the lease is the leader until the lease expires, and renews the lease before then.
+type Elector struct {
+	db   *gorm.DB
+	name string
+	id   string
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu sync.Mutex
+	// validUntil is when the lease expires at the latest by the clock of the replica.
+	validUntil time.Time
+}
+
+// NewElector returns new Elector which elects the leader of name as the replica with id.
+// ttl is how long the lease lasts without being renewed.
+func NewElector(db *gorm.DB, name string, id string, ttl time.Duration) *Elector {
+	return &Elector{
+		db:   db,
+		name: name,
+		id:   id,
+		ttl:  ttl,
+		now:  time.Now,
+	}
+}
+
+// DefaultID returns an ID of the replica unique among the replicas, made of the host name.
+func DefaultID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "tks-info"
+	}
+	return host + "-" + uuid.NewString()[:8]
+}
+
+// ID returns the ID of the replica.
+func (e *Elector) ID() string {
+	return e.id
+}
+
+// IsLeader tells whether the replica holds the lease.
+func (e *Elector) IsLeader() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.now().Before(e.validUntil)
+}
+
+// TryAcquire acquires or renews the lease, and tells whether the replica is the leader.
+func (e *Elector) TryAcquire(ctx context.Context) (bool, error) {
+	// The lease expires no earlier than ttl after the query is sent.
+	start := e.now()
+	res := e.db.WithContext(ctx).Exec(acquireQuery, e.name, e.id, e.ttl.Seconds())
+	if res.Error != nil {
+		return e.IsLeader(), res.Error
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	wasLeader := e.now().Before(e.validUntil)
+	if res.RowsAffected == 1 {
+		e.validUntil = start.Add(e.ttl)
+		if !wasLeader {
+			log.Info("became the leader of ", e.name, " as ", e.id)
+		}
+		return true, nil
+	}
+	e.validUntil = time.Time{}
+	if wasLeader {
+		log.Warn("lost the leadership of ", e.name, " as ", e.id)
+	}
+	return false, nil
+}
+
+// Run acquires or renews the lease at a third of ttl until ctx is done.
+func (e *Elector) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		if _, err := e.TryAcquire(ctx); err != nil && ctx.Err() == nil {
+			log.Warn("failed to acquire the lease of ", e.name, ". err : ", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Release gives up the lease if the replica holds it, so that another replica takes over without waiting for expiry.
+func (e *Elector) Release(ctx context.Context) error {
+	e.mu.Lock()
+	e.validUntil = time.Time{}
+	e.mu.Unlock()
+	return e.db.WithContext(ctx).Where("name = ? AND holder = ?", e.name, e.id).Delete(&model.Lease{}).Error
+}
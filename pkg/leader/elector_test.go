@@ -0,0 +1,113 @@
+package leader_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+
+	"github.com/openinfradev/tks-info/pkg/leader"
+	"github.com/openinfradev/tks-info/pkg/leader/model"
+)
+
+var (
+	db         *gorm.DB
+	testDBHost string
+	testDBPort string
+)
+
+func init() {
+	log.Disable()
+}
+
+func getDB() (*gorm.DB, error) {
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Seoul",
+		testDBHost, "postgres", "password", "tks", testDBPort)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&model.Lease{}); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func TestMain(m *testing.M) {
+	pool, resource, err := helper.CreatePostgres()
+	if err != nil {
+		fmt.Printf("Could not create postgres: %s", err)
+		os.Exit(-1)
+	}
+	testDBHost, testDBPort = helper.GetHostAndPort(resource)
+	db, _ = getDB()
+
+	code := m.Run()
+
+	if err := helper.RemovePostgres(pool, resource); err != nil {
+		fmt.Printf("Could not remove postgres: %s", err)
+		os.Exit(-1)
+	}
+	os.Exit(code)
+}
+
+func TestElector(t *testing.T) {
+	ctx := context.Background()
+	first := leader.NewElector(db, "elector", "first", time.Hour)
+	second := leader.NewElector(db, "elector", "second", time.Hour)
+
+	acquired, err := first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.True(t, first.IsLeader())
+
+	// The lease is renewed by the leader only.
+	acquired, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	acquired, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.False(t, acquired)
+	require.False(t, second.IsLeader())
+
+	require.NoError(t, first.Release(ctx))
+	require.False(t, first.IsLeader())
+	acquired, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	// Other groups have their own leaders.
+	acquired, err = leader.NewElector(db, "other", "first", time.Hour).TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+}
+
+func TestElectorTakesOverExpiredLease(t *testing.T) {
+	ctx := context.Background()
+	first := leader.NewElector(db, "expiry", "first", 500*time.Millisecond)
+	second := leader.NewElector(db, "expiry", "second", 500*time.Millisecond)
+
+	acquired, err := first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	time.Sleep(time.Second)
+	require.False(t, first.IsLeader())
+	acquired, err = second.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	acquired, err = first.TryAcquire(ctx)
+	require.NoError(t, err)
+	require.False(t, acquired)
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// Lease is held by the leader of the replicas until it expires.
+type Lease struct {
+	// Name is the name of the group of replicas electing the leader.
+	Name string `gorm:"primarykey"`
+	// Holder is the ID of the replica holding the lease.
+	Holder    string
+	ExpiresAt time.Time
+	UpdatedAt time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,112 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+)
+
+// ShutdownFunc releases a resource. It should return when ctx is done.
+type ShutdownFunc func(ctx context.Context) error
+
+type hook struct {
+	name string
+	fn   ShutdownFunc
+}
+
+// Manager runs the servers of the process and shuts them down
+// when a signal is received or one of them fails.
+type Manager struct {
+	timeout time.Duration
+	signals []os.Signal
+	hooks   []hook
+	errs    chan error
+}
+
+// New returns new Manager which gives shutdown hooks up to timeout to finish.
+func New(timeout time.Duration) *Manager {
+	return &Manager{
+		timeout: timeout,
+		signals: []os.Signal{syscall.SIGINT, syscall.SIGTERM},
+		errs:    make(chan error, 1),
+	}
+}
+
+// OnShutdown registers a hook. Hooks run in the reverse order of registration,
+// so resources should be registered in the order they are created.
+func (m *Manager) OnShutdown(name string, fn ShutdownFunc) {
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// Go runs fn in a goroutine. If fn returns an error, the process is shut down.
+func (m *Manager) Go(name string, fn func() error) {
+	go func() {
+		if err := fn(); err != nil {
+			select {
+			case m.errs <- fmt.Errorf("%s: %w", name, err):
+			default:
+			}
+		}
+	}()
+}
+
+// Wait blocks until a signal is received, ctx is done or a function started by Go fails,
+// and then runs the shutdown hooks. It returns the error of the failed function, if any.
+func (m *Manager) Wait(ctx context.Context) error {
+	ctx, stop := signal.NotifyContext(ctx, m.signals...)
+	defer stop()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Info("shutting down...")
+	case err = <-m.errs:
+		log.Error("shutting down on error : ", err)
+	}
+
+	m.Shutdown()
+	return err
+}
+
+// Shutdown runs the shutdown hooks within the timeout.
+// A failed hook is logged and does not stop the following ones.
+func (m *Manager) Shutdown() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	for i := len(m.hooks) - 1; i >= 0; i-- {
+		h := m.hooks[i]
+		log.Info("shutting down ", h.name)
+		if err := h.fn(ctx); err != nil {
+			log.Error("failed to shutdown ", h.name, ". err : ", err)
+		}
+	}
+	log.Info("shutdown completed")
+}
+
+// StopGRPCServer returns a hook which stops accepting new RPCs and waits for in-flight ones.
+// RPCs still running when ctx is done are cancelled.
+func StopGRPCServer(s *grpc.Server) ShutdownFunc {
+	return func(ctx context.Context) error {
+		done := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			s.Stop()
+			return fmt.Errorf("in-flight requests were cancelled: %w", ctx.Err())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package lifecycle_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/lifecycle"
+)
+
+func init() {
+	log.Disable()
+}
+
+func TestShutdownOrder(t *testing.T) {
+	lc := lifecycle.New(time.Second)
+
+	order := []string{}
+	for _, name := range []string{"database", "client", "server"} {
+		name := name
+		lc.OnShutdown(name, func(ctx context.Context) error {
+			order = append(order, name)
+			return errors.New("ignored")
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, lc.Wait(ctx))
+	require.Equal(t, []string{"server", "client", "database"}, order)
+}
+
+func TestShutdownOnError(t *testing.T) {
+	lc := lifecycle.New(time.Second)
+
+	stopped := false
+	lc.OnShutdown("server", func(ctx context.Context) error {
+		stopped = true
+		return nil
+	})
+	lc.Go("server", func() error {
+		return errors.New("failed to listen")
+	})
+
+	err := lc.Wait(context.Background())
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to listen")
+	require.True(t, stopped)
+}
+
+func startServer(t *testing.T) (*grpc.Server, *grpc.ClientConn) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := grpc.NewServer()
+	healthpb.RegisterHealthServer(s, grpchealth.NewServer())
+	go s.Serve(lis)
+
+	conn, err := grpc.Dial(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return s, conn
+}
+
+func TestStopGRPCServer(t *testing.T) {
+	s, conn := startServer(t)
+	_, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, lifecycle.StopGRPCServer(s)(ctx))
+}
+
+func TestStopGRPCServerDeadline(t *testing.T) {
+	s, conn := startServer(t)
+
+	// Watch never returns by itself, so the server can't drain it.
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err = lifecycle.StopGRPCServer(s)(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = stream.Recv()
+	require.Error(t, err)
+}
@@ -0,0 +1,110 @@
+package limits
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// FieldLengths are the maximum lengths in characters of request fields,
+// which are the sizes of the columns they are stored in (see scripts/*.sql).
+var FieldLengths = map[protoreflect.FullName]int{
+	"tks_pb.AddClusterInfoRequest.contract_id": 10,
+	"tks_pb.AddClusterInfoRequest.name":        50,
+	"tks_pb.AddClusterInfoRequest.description": 100,
+	"tks_pb.ClusterConf.ssh_key_name":          50,
+	"tks_pb.ClusterConf.region":                50,
+	"tks_pb.ClusterConf.machine_type":          50,
+
+	"tks_pb.UpdateClusterStatusRequest.status_desc": 10000,
+	"tks_pb.UpdateClusterStatusRequest.workflow_id": 100,
+
+	"tks_pb.CreateCSPInfoRequest.contract_id": 10,
+	"tks_pb.CreateCSPInfoRequest.csp_name":    50,
+	"tks_pb.CreateCSPInfoRequest.auth":        10000,
+	"tks_pb.UpdateCSPAuthRequest.auth":        10000,
+
+	"tks_pb.AppGroup.app_group_name":                 50,
+	"tks_pb.AppGroup.workflow_id":                    100,
+	"tks_pb.AppGroup.status_desc":                    10000,
+	"tks_pb.AppGroup.external_label":                 50,
+	"tks_pb.AppGroup.description":                    100,
+	"tks_pb.UpdateAppGroupStatusRequest.status_desc": 10000,
+	"tks_pb.UpdateAppGroupStatusRequest.workflow_id": 100,
+	"tks_pb.UpdateAppRequest.endpoint":               200,
+
+	"tks_pb.CreateKeycloakInfoRequest.realm":       100,
+	"tks_pb.CreateKeycloakInfoRequest.client_id":   100,
+	"tks_pb.CreateKeycloakInfoRequest.secret":      1000,
+	"tks_pb.CreateKeycloakInfoRequest.private_key": 1000,
+
+	"tks_pb.UpdateAppServeAppStatusRequest.status": 20,
+	"tks_pb.UpdateAppServeAppStatusRequest.output": 10000,
+
+	"tks_pb.UpdateAppServeAppEndpointRequest.endpoint":         300,
+	"tks_pb.UpdateAppServeAppEndpointRequest.preview_endpoint": 300,
+
+	"tks_pb.AppServeApp.name":                 50,
+	"tks_pb.AppServeApp.contract_id":          10,
+	"tks_pb.AppServeApp.type":                 10,
+	"tks_pb.AppServeApp.app_type":             20,
+	"tks_pb.AppServeApp.status":               20,
+	"tks_pb.AppServeApp.endpoint_url":         300,
+	"tks_pb.AppServeApp.preview_endpoint_url": 300,
+	"tks_pb.AppServeApp.target_cluster_id":    10,
+
+	"tks_pb.AppServeAppTask.version":         20,
+	"tks_pb.AppServeAppTask.strategy":        20,
+	"tks_pb.AppServeAppTask.status":          20,
+	"tks_pb.AppServeAppTask.output":          10000,
+	"tks_pb.AppServeAppTask.artifact_url":    300,
+	"tks_pb.AppServeAppTask.image_url":       300,
+	"tks_pb.AppServeAppTask.executable_path": 200,
+	"tks_pb.AppServeAppTask.resource_spec":   20,
+	"tks_pb.AppServeAppTask.profile":         20,
+	"tks_pb.AppServeAppTask.app_config":      10000,
+	"tks_pb.AppServeAppTask.app_secret":      10000,
+	"tks_pb.AppServeAppTask.extra_env":       1000,
+	"tks_pb.AppServeAppTask.port":            10,
+}
+
+// CheckFieldLengths returns ResourceExhausted if a string field of msg, including fields of
+// nested messages, is longer than its limit in lengths.
+func CheckFieldLengths(msg protoreflect.Message, lengths map[protoreflect.FullName]int) error {
+	var err error
+	msg.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsList() || fd.IsMap():
+			return true
+		case fd.Kind() == protoreflect.StringKind:
+			if max, ok := lengths[fd.FullName()]; ok {
+				if n := utf8.RuneCountInString(v.String()); n > max {
+					err = status.Errorf(codes.ResourceExhausted, "%s is %d characters long, over the limit of %d", fd.Name(), n, max)
+					return false
+				}
+			}
+		case fd.Kind() == protoreflect.MessageKind:
+			err = CheckFieldLengths(v.Message(), lengths)
+			return err == nil
+		}
+		return true
+	})
+	return err
+}
+
+// FieldLengthInterceptor rejects requests with fields over FieldLengths.
+func FieldLengthInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if msg, ok := req.(proto.Message); ok {
+			if err := CheckFieldLengths(msg.ProtoReflect(), FieldLengths); err != nil {
+				return nil, err
+			}
+		}
+		return handler(ctx, req)
+	}
+}
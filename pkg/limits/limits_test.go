@@ -0,0 +1,132 @@
+package limits
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("0.5:10")
+	require.NoError(t, err)
+	require.Equal(t, Limit{Rate: 0.5, Burst: 10}, limit)
+
+	for _, s := range []string{"", "10", "a:1", "1:a", "-1:1", "1:-1", "1:2:3"} {
+		_, err := ParseLimit(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseMethodLimits(t *testing.T) {
+	limits, err := ParseMethodLimits("UpdateAppServeAppStatus=1:5, GetClusters=50:100")
+	require.NoError(t, err)
+	require.Equal(t, map[string]Limit{
+		"UpdateAppServeAppStatus": {Rate: 1, Burst: 5},
+		"GetClusters":             {Rate: 50, Burst: 100},
+	}, limits)
+
+	limits, err = ParseMethodLimits("")
+	require.NoError(t, err)
+	require.Empty(t, limits)
+
+	for _, s := range []string{"GetClusters", "=1:1", "GetClusters=1"} {
+		_, err := ParseMethodLimits(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewRateLimiter(Limit{Rate: 1, Burst: 2}, map[string]Limit{"UpdateAppServeAppStatus": {Rate: 1, Burst: 1}})
+	l.now = func() time.Time { return now }
+
+	require.True(t, l.Allow("workflow", "GetClusters"))
+	require.True(t, l.Allow("workflow", "GetClusters"))
+	require.False(t, l.Allow("workflow", "GetClusters"))
+	require.True(t, l.Allow("console", "GetClusters"))
+
+	require.True(t, l.Allow("workflow", "UpdateAppServeAppStatus"))
+	require.False(t, l.Allow("workflow", "UpdateAppServeAppStatus"))
+
+	now = now.Add(time.Second)
+	require.True(t, l.Allow("workflow", "UpdateAppServeAppStatus"))
+	require.True(t, l.Allow("workflow", "GetClusters"))
+
+	now = now.Add(time.Minute)
+	require.True(t, l.Allow("console", "GetClusters"))
+	l.Evict(time.Second)
+	require.Len(t, l.buckets, 1)
+}
+
+func TestRateLimiterInterceptor(t *testing.T) {
+	l := NewRateLimiter(Limit{Rate: 0, Burst: 1}, nil)
+	interceptor := l.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/tks_pb.AppServeAppService/UpdateAppServeAppStatus"}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.SimpleResponse{}, nil
+	}
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4000}})
+
+	_, err := interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	_, err = interceptor(ctx, nil, info, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	// Another connection of the same host shares the limit.
+	ctx = peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 4001}})
+	_, err = interceptor(ctx, nil, info, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
+
+func TestCheckFieldLengths(t *testing.T) {
+	req := &pb.UpdateAppServeAppStatusRequest{Status: "SUCCESS", Output: strings.Repeat("가", 10000)}
+	require.NoError(t, CheckFieldLengths(req.ProtoReflect(), FieldLengths))
+
+	req.Output += "a"
+	err := CheckFieldLengths(req.ProtoReflect(), FieldLengths)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	nested := &pb.CreateAppServeAppRequest{
+		AppServeApp:     &pb.AppServeApp{Name: "app"},
+		AppServeAppTask: &pb.AppServeAppTask{Version: strings.Repeat("1", 21)},
+	}
+	err = CheckFieldLengths(nested.ProtoReflect(), FieldLengths)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Contains(t, err.Error(), "version")
+}
+
+func TestFieldLengthInterceptor(t *testing.T) {
+	interceptor := FieldLengthInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/tks_pb.AppServeAppService/UpdateAppServeAppStatus"}
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return &pb.SimpleResponse{}, nil
+	}
+
+	_, err := interceptor(context.Background(), &pb.UpdateAppServeAppStatusRequest{Status: strings.Repeat("A", 21)}, info, handler)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.False(t, called)
+
+	_, err = interceptor(context.Background(), &pb.UpdateAppServeAppStatusRequest{Status: "SUCCESS"}, info, handler)
+	require.NoError(t, err)
+	require.True(t, called)
+}
+
+func TestFieldLengthsExist(t *testing.T) {
+	for name := range FieldLengths {
+		_, err := protoregistry.GlobalFiles.FindDescriptorByName(name)
+		require.NoError(t, err, name)
+	}
+}
@@ -0,0 +1,171 @@
+package limits
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/auth"
+)
+
+// Limit is a token bucket which is refilled at Rate tokens per second up to Burst tokens.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// ParseLimit parses a limit written as "<rate>:<burst>", such as "10:20".
+func ParseLimit(s string) (Limit, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("limit %q must be <rate>:<burst>", s)
+	}
+	r, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil || r < 0 {
+		return Limit{}, fmt.Errorf("invalid rate of limit %q", s)
+	}
+	burst, err := strconv.Atoi(parts[1])
+	if err != nil || burst < 0 {
+		return Limit{}, fmt.Errorf("invalid burst of limit %q", s)
+	}
+	return Limit{Rate: r, Burst: burst}, nil
+}
+
+// ParseMethodLimits parses comma separated limits of RPCs, such as
+// "UpdateAppServeAppStatus=1:5,GetClusters=50:100". Methods are names without service.
+func ParseMethodLimits(s string) (map[string]Limit, error) {
+	limits := map[string]Limit{}
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("method limit %q must be <method>=<rate>:<burst>", item)
+		}
+		limit, err := ParseLimit(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		limits[parts[0]] = limit
+	}
+	return limits, nil
+}
+
+type bucketKey struct {
+	caller string
+	method string
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimiter limits requests of each caller to each RPC.
+// Callers are authenticated subjects, or peer addresses if requests are not authenticated.
+type RateLimiter struct {
+	defaultLimit Limit
+	methodLimits map[string]Limit
+	now          func() time.Time
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+}
+
+// NewRateLimiter returns new RateLimiter. RPCs which are not in methodLimits get defaultLimit.
+func NewRateLimiter(defaultLimit Limit, methodLimits map[string]Limit) *RateLimiter {
+	return &RateLimiter{
+		defaultLimit: defaultLimit,
+		methodLimits: methodLimits,
+		now:          time.Now,
+		buckets:      map[bucketKey]*bucket{},
+	}
+}
+
+// Allow takes a token of the caller for the method, and returns false if there is none.
+func (l *RateLimiter) Allow(caller string, method string) bool {
+	limit, ok := l.methodLimits[method]
+	if !ok {
+		limit = l.defaultLimit
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	key := bucketKey{caller: caller, method: method}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.Rate), limit.Burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+	return b.limiter.AllowN(now, 1)
+}
+
+// Evict removes the buckets which were not used for idle.
+func (l *RateLimiter) Evict(idle time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for key, b := range l.buckets {
+		if now.Sub(b.lastSeen) > idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Run evicts idle buckets at every interval until ctx is done.
+func (l *RateLimiter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			l.Evict(interval)
+		}
+	}
+}
+
+// UnaryServerInterceptor rejects requests over the limits with ResourceExhausted.
+// It must run after authentication to limit authenticated callers separately.
+func (l *RateLimiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		method := info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]
+		caller := callerOf(ctx)
+		if !l.Allow(caller, method) {
+			log.Warn("rate limit of ", method, " exceeded by ", caller)
+			return nil, status.Errorf(codes.ResourceExhausted, "rate limit of %s exceeded", method)
+		}
+		return handler(ctx, req)
+	}
+}
+
+func callerOf(ctx context.Context) string {
+	if subject := auth.Subject(ctx); subject != "" {
+		return subject
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return host
+		}
+		return p.Addr.String()
+	}
+	return "unknown"
+}
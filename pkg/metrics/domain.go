@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+
+	asaModel "github.com/openinfradev/tks-info/pkg/app_serve_app/model"
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// domainCollector counts resources by status on each scrape.
+type domainCollector struct {
+	db *gorm.DB
+
+	clusters     *prometheus.Desc
+	appServeApps *prometheus.Desc
+}
+
+func newDomainCollector(db *gorm.DB) *domainCollector {
+	return &domainCollector{
+		db: db,
+		clusters: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "clusters"),
+			"Number of clusters by status.",
+			[]string{"status"}, nil),
+		appServeApps: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "app_serve_apps"),
+			"Number of app serve apps by status.",
+			[]string{"status"}, nil),
+	}
+}
+
+func (c *domainCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.clusters
+	ch <- c.appServeApps
+}
+
+type statusCount struct {
+	Status string
+	Count  int64
+}
+
+func (c *domainCollector) Collect(ch chan<- prometheus.Metric) {
+	var clusters []struct {
+		Status pb.ClusterStatus
+		Count  int64
+	}
+	if err := c.db.Model(&clusterModel.Cluster{}).
+		Select("status, count(*) as count").Group("status").Scan(&clusters).Error; err != nil {
+		log.Warn("failed to count clusters. err : ", err)
+	} else {
+		for _, s := range clusters {
+			ch <- prometheus.MustNewConstMetric(c.clusters, prometheus.GaugeValue, float64(s.Count), s.Status.String())
+		}
+	}
+
+	var asas []statusCount
+	if err := c.db.Model(&asaModel.AppServeApp{}).
+		Select("status, count(*) as count").Group("status").Scan(&asas).Error; err != nil {
+		log.Warn("failed to count app serve apps. err : ", err)
+	} else {
+		for _, s := range asas {
+			ch <- prometheus.MustNewConstMetric(c.appServeApps, prometheus.GaugeValue, float64(s.Count), s.Status)
+		}
+	}
+}
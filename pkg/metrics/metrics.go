@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+	"gorm.io/gorm"
+
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+const namespace = "tks_info"
+
+// Metrics holds every metric exposed by tks-info.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	rpcDuration *prometheus.HistogramVec
+	rpcTotal    *prometheus.CounterVec
+	dbDuration  *prometheus.HistogramVec
+}
+
+// New returns new Metrics registered to its own registry.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		rpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "grpc_request_duration_seconds",
+			Help:      "Latency of gRPC requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"service", "method"}),
+		rpcTotal: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "grpc_requests_total",
+			Help:      "Number of gRPC requests by response code.",
+		}, []string{"service", "method", "code"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Latency of database queries by accessor method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "operation"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.rpcDuration,
+		m.rpcTotal,
+		m.dbDuration,
+	)
+	return m
+}
+
+// Handler returns the HTTP handler which exposes the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// RegisterDB exposes connection pool stats and domain gauges of the database.
+func (m *Metrics) RegisterDB(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	if err := m.registry.Register(collectors.NewDBStatsCollector(sqlDB, "tks")); err != nil {
+		return err
+	}
+	if err := m.registry.Register(newDomainCollector(db)); err != nil {
+		return err
+	}
+	return db.Use(&gormPlugin{duration: m.dbDuration})
+}
+
+// UnaryServerInterceptor records the latency and the response code of each request.
+// The code is taken from the Code field of the response if it has one,
+// because handlers report errors through it.
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		res, err := handler(ctx, req)
+
+		service, method := splitMethodName(info.FullMethod)
+		m.rpcDuration.WithLabelValues(service, method).Observe(time.Since(start).Seconds())
+		m.rpcTotal.WithLabelValues(service, method, responseCode(res, err)).Inc()
+		return res, err
+	}
+}
+
+type codeResponse interface {
+	GetCode() pb.Code
+}
+
+func responseCode(res interface{}, err error) string {
+	if r, ok := res.(codeResponse); ok && res != nil {
+		return r.GetCode().String()
+	}
+	return status.Code(err).String()
+}
+
+func splitMethodName(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "unknown", fullMethod
+}
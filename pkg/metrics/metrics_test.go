@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	m := New()
+	interceptor := m.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/tks_pb.ClusterInfoService/GetCluster"}
+
+	handlers := []grpc.UnaryHandler{
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &pb.GetClusterResponse{Code: pb.Code_OK_UNSPECIFIED}, nil
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return &pb.GetClusterResponse{Code: pb.Code_NOT_FOUND}, nil
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, status.Error(codes.Unavailable, "unavailable")
+		},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, errors.New("error")
+		},
+	}
+	for _, handler := range handlers {
+		_, _ = interceptor(context.Background(), nil, info, handler)
+	}
+
+	service, method := "tks_pb.ClusterInfoService", "GetCluster"
+	require.Equal(t, 1.0, testutil.ToFloat64(m.rpcTotal.WithLabelValues(service, method, pb.Code_OK_UNSPECIFIED.String())))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.rpcTotal.WithLabelValues(service, method, pb.Code_NOT_FOUND.String())))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.rpcTotal.WithLabelValues(service, method, codes.Unavailable.String())))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.rpcTotal.WithLabelValues(service, method, codes.Unknown.String())))
+	require.Equal(t, 1, testutil.CollectAndCount(m.rpcDuration))
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	require.True(t, strings.Contains(string(body), "tks_info_grpc_requests_total"))
+}
+
+func TestSplitMethodName(t *testing.T) {
+	service, method := splitMethodName("/tks_pb.CspInfoService/CreateCSPInfo")
+	require.Equal(t, "tks_pb.CspInfoService", service)
+	require.Equal(t, "CreateCSPInfo", method)
+
+	service, method = splitMethodName("Invalid")
+	require.Equal(t, "unknown", service)
+	require.Equal(t, "Invalid", method)
+}
@@ -0,0 +1,248 @@
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	appModel "github.com/openinfradev/tks-info/pkg/application/model"
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// Kinds of resources in events.
+const (
+	KindCluster  = "cluster"
+	KindAppGroup = "app_group"
+)
+
+const (
+	// batchSize is the number of stale resources of a status handled by a reconciliation.
+	batchSize = 100
+	// maxStatusDescLength is the size of the status_desc columns.
+	maxStatusDescLength = 10000
+)
+
+// Event is a change of the status of a resource made by the reconciler.
+type Event struct {
+	Kind       string    `json:"kind"`
+	ID         string    `json:"id"`
+	ContractID string    `json:"contract_id"`
+	WorkflowID string    `json:"workflow_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	StatusDesc string    `json:"status_desc"`
+	Time       time.Time `json:"time"`
+}
+
+// Reconciler marks clusters and app groups as errored when they stay in an in-progress status
+// longer than the timeout of the status, which happens when the workflow updating them dies.
+type Reconciler struct {
+	db               *gorm.DB
+	clusterTimeouts  map[pb.ClusterStatus]time.Duration
+	appGroupTimeouts map[pb.AppGroupStatus]time.Duration
+	onEvent          func(Event)
+	now              func() time.Time
+}
+
+// New returns new Reconciler with the timeouts of statuses, which calls onEvent for each resource marked as errored.
+func New(db *gorm.DB, clusterTimeouts map[pb.ClusterStatus]time.Duration, appGroupTimeouts map[pb.AppGroupStatus]time.Duration, onEvent func(Event)) *Reconciler {
+	if onEvent == nil {
+		onEvent = func(Event) {}
+	}
+	return &Reconciler{
+		db:               db,
+		clusterTimeouts:  clusterTimeouts,
+		appGroupTimeouts: appGroupTimeouts,
+		onEvent:          onEvent,
+		now:              time.Now,
+	}
+}
+
+// staleResource is a resource whose status has not been updated for the timeout.
+type staleResource struct {
+	ID         string
+	ContractID string
+	WorkflowID string
+	StatusDesc string
+}
+
+// Reconcile marks the resources which have not been updated for the timeout of their status as errored.
+// A resource updated in the meantime, such as by its workflow, is left as it is.
+func (r *Reconciler) Reconcile(ctx context.Context) error {
+	db := r.db.WithContext(ctx)
+	failed := 0
+	var firstErr error
+	fail := func(err error) {
+		if firstErr == nil {
+			firstErr = err
+		}
+		failed++
+	}
+
+	for _, status := range sortedClusterStatuses(r.clusterTimeouts) {
+		timeout := r.clusterTimeouts[status]
+		cutoff := r.now().Add(-timeout)
+		var stale []staleResource
+		if err := db.Model(&clusterModel.Cluster{}).
+			Select("id, contract_id, workflow_id, status_desc").
+			Where("status = ? AND updated_at < ?", status, cutoff).
+			Order("updated_at").Limit(batchSize).
+			Scan(&stale).Error; err != nil {
+			fail(fmt.Errorf("failed to find stale clusters: %w", err))
+			continue
+		}
+		for _, s := range stale {
+			desc := staleStatusDesc(status.String(), timeout, s)
+			res := db.Model(&clusterModel.Cluster{}).
+				Where("id = ? AND status = ? AND updated_at < ?", s.ID, status, cutoff).
+				Updates(map[string]interface{}{"Status": pb.ClusterStatus_ERROR, "StatusDesc": desc})
+			if res.Error != nil {
+				fail(fmt.Errorf("failed to mark cluster %s as errored: %w", s.ID, res.Error))
+				continue
+			}
+			if res.RowsAffected == 1 {
+				r.emit(KindCluster, s, status.String(), pb.ClusterStatus_ERROR.String(), desc)
+			}
+		}
+	}
+
+	for _, status := range sortedAppGroupStatuses(r.appGroupTimeouts) {
+		timeout := r.appGroupTimeouts[status]
+		cutoff := r.now().Add(-timeout)
+		var stale []staleResource
+		if err := db.Model(&appModel.ApplicationGroup{}).
+			Select("application_groups.id, clusters.contract_id, application_groups.workflow_id, application_groups.status_desc").
+			Joins("LEFT JOIN clusters ON clusters.id = application_groups.cluster_id").
+			Where("application_groups.status = ? AND application_groups.updated_at < ?", status, cutoff).
+			Order("application_groups.updated_at").Limit(batchSize).
+			Scan(&stale).Error; err != nil {
+			fail(fmt.Errorf("failed to find stale app groups: %w", err))
+			continue
+		}
+		for _, s := range stale {
+			desc := staleStatusDesc(status.String(), timeout, s)
+			res := db.Model(&appModel.ApplicationGroup{}).
+				Where("id = ? AND status = ? AND updated_at < ?", s.ID, status, cutoff).
+				Updates(map[string]interface{}{"Status": pb.AppGroupStatus_APP_GROUP_ERROR, "StatusDesc": desc})
+			if res.Error != nil {
+				fail(fmt.Errorf("failed to mark app group %s as errored: %w", s.ID, res.Error))
+				continue
+			}
+			if res.RowsAffected == 1 {
+				r.emit(KindAppGroup, s, status.String(), pb.AppGroupStatus_APP_GROUP_ERROR.String(), desc)
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d errors in reconciliation, the first of which is: %w", failed, firstErr)
+	}
+	return nil
+}
+
+func (r *Reconciler) emit(kind string, s staleResource, from string, to string, desc string) {
+	log.Warn(kind, " ", s.ID, " was in ", from, " for too long, and is marked as ", to)
+	r.onEvent(Event{
+		Kind:       kind,
+		ID:         s.ID,
+		ContractID: s.ContractID,
+		WorkflowID: s.WorkflowID,
+		FromStatus: from,
+		ToStatus:   to,
+		StatusDesc: desc,
+		Time:       r.now(),
+	})
+}
+
+// staleStatusDesc explains why the resource is marked as errored, followed by the previous description.
+func staleStatusDesc(status string, timeout time.Duration, s staleResource) string {
+	desc := fmt.Sprintf("marked as error by tks-info: status %s was not updated for %s", status, timeout)
+	if s.WorkflowID != "" {
+		desc += fmt.Sprintf(", and workflow %s may have died", s.WorkflowID)
+	}
+	if s.StatusDesc != "" {
+		desc += ". last status desc: " + s.StatusDesc
+	}
+	if utf8.RuneCountInString(desc) > maxStatusDescLength {
+		desc = string([]rune(desc)[:maxStatusDescLength])
+	}
+	return desc
+}
+
+func sortedClusterStatuses(timeouts map[pb.ClusterStatus]time.Duration) []pb.ClusterStatus {
+	statuses := make([]pb.ClusterStatus, 0, len(timeouts))
+	for status := range timeouts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+	return statuses
+}
+
+func sortedAppGroupStatuses(timeouts map[pb.AppGroupStatus]time.Duration) []pb.AppGroupStatus {
+	statuses := make([]pb.AppGroupStatus, 0, len(timeouts))
+	for status := range timeouts {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+	return statuses
+}
+
+// ParseClusterTimeouts parses timeouts of cluster statuses such as "INSTALLING=3h,DELETING=1h".
+// Only in-progress statuses can have timeouts.
+func ParseClusterTimeouts(value string) (map[pb.ClusterStatus]time.Duration, error) {
+	timeouts := map[pb.ClusterStatus]time.Duration{}
+	err := parseTimeouts(value, func(name string, timeout time.Duration) error {
+		switch status := pb.ClusterStatus(pb.ClusterStatus_value[name]); status {
+		case pb.ClusterStatus_INSTALLING, pb.ClusterStatus_DELETING:
+			timeouts[status] = timeout
+			return nil
+		}
+		return fmt.Errorf("%s is not an in-progress cluster status", name)
+	})
+	return timeouts, err
+}
+
+// ParseAppGroupTimeouts parses timeouts of app group statuses such as "INSTALLING=1h,DELETING=1h".
+// The prefix APP_GROUP_ of the statuses may be omitted.
+func ParseAppGroupTimeouts(value string) (map[pb.AppGroupStatus]time.Duration, error) {
+	timeouts := map[pb.AppGroupStatus]time.Duration{}
+	err := parseTimeouts(value, func(name string, timeout time.Duration) error {
+		if !strings.HasPrefix(name, "APP_GROUP_") {
+			name = "APP_GROUP_" + name
+		}
+		switch status := pb.AppGroupStatus(pb.AppGroupStatus_value[name]); status {
+		case pb.AppGroupStatus_APP_GROUP_INSTALLING, pb.AppGroupStatus_APP_GROUP_DELETING:
+			timeouts[status] = timeout
+			return nil
+		}
+		return fmt.Errorf("%s is not an in-progress app group status", name)
+	})
+	return timeouts, err
+}
+
+func parseTimeouts(value string, set func(name string, timeout time.Duration) error) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("%q must be <status>=<timeout>", item)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("timeout of %s must be a positive duration", parts[0])
+		}
+		if err := set(strings.ToUpper(strings.TrimSpace(parts[0])), timeout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package reconcile_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+
+	"github.com/openinfradev/tks-info/pkg/application"
+	modelApplication "github.com/openinfradev/tks-info/pkg/application/model"
+	"github.com/openinfradev/tks-info/pkg/cluster"
+	modelCluster "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/csp_info"
+	modelCspInfo "github.com/openinfradev/tks-info/pkg/csp_info/model"
+	"github.com/openinfradev/tks-info/pkg/reconcile"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+var (
+	db         *gorm.DB
+	testDBHost string
+	testDBPort string
+)
+
+func init() {
+	log.Disable()
+}
+
+func getDB() (*gorm.DB, error) {
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Seoul",
+		testDBHost, "postgres", "password", "tks", testDBPort)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
+
+	if err := db.AutoMigrate(
+		&modelCspInfo.CSPInfo{},
+		&modelCluster.Cluster{},
+		&modelApplication.ApplicationGroup{},
+	); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func TestMain(m *testing.M) {
+	pool, resource, err := helper.CreatePostgres()
+	if err != nil {
+		fmt.Printf("Could not create postgres: %s", err)
+		os.Exit(-1)
+	}
+	testDBHost, testDBPort = helper.GetHostAndPort(resource)
+	db, _ = getDB()
+
+	code := m.Run()
+
+	if err := helper.RemovePostgres(pool, resource); err != nil {
+		fmt.Printf("Could not remove postgres: %s", err)
+		os.Exit(-1)
+	}
+	os.Exit(code)
+}
+
+// createCluster creates a cluster in the status, which was last updated at updatedAt.
+func createCluster(t *testing.T, status pb.ClusterStatus, updatedAt time.Time) string {
+	cspId, err := csp_info.New(db).Create("P0000stale", "csp", "", pb.CspType_AWS)
+	require.NoError(t, err)
+	id, err := cluster.New(db).CreateClusterInfo("P0000stale", cspId, "cluster", &pb.ClusterConf{}, uuid.Nil, "")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&modelCluster.Cluster{}).Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"status": status, "workflow_id": "wf-" + id, "updated_at": updatedAt}).Error)
+	return id
+}
+
+func TestReconcile(t *testing.T) {
+	now := time.Now()
+	stale := createCluster(t, pb.ClusterStatus_INSTALLING, now.Add(-4*time.Hour))
+	recent := createCluster(t, pb.ClusterStatus_INSTALLING, now.Add(-time.Hour))
+	running := createCluster(t, pb.ClusterStatus_RUNNING, now.Add(-4*time.Hour))
+	deleting := createCluster(t, pb.ClusterStatus_DELETING, now.Add(-4*time.Hour))
+
+	appGroupId, err := application.New(db).Create(recent, &pb.AppGroup{AppGroupName: "lma", Type: pb.AppGroupType_LMA, Status: pb.AppGroupStatus_APP_GROUP_INSTALLING})
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&modelApplication.ApplicationGroup{}).Where("id = ?", appGroupId).
+		UpdateColumn("updated_at", now.Add(-2*time.Hour)).Error)
+
+	clusterTimeouts, err := reconcile.ParseClusterTimeouts("INSTALLING=3h")
+	require.NoError(t, err)
+	appGroupTimeouts, err := reconcile.ParseAppGroupTimeouts("INSTALLING=1h")
+	require.NoError(t, err)
+	events := []reconcile.Event{}
+	r := reconcile.New(db, clusterTimeouts, appGroupTimeouts, func(e reconcile.Event) {
+		events = append(events, e)
+	})
+	require.NoError(t, r.Reconcile(context.Background()))
+
+	c, err := cluster.New(db).GetCluster(stale)
+	require.NoError(t, err)
+	require.Equal(t, pb.ClusterStatus_ERROR, c.GetStatus())
+	require.True(t, strings.HasPrefix(c.GetStatusDesc(), "marked as error by tks-info"), c.GetStatusDesc())
+	require.Contains(t, c.GetStatusDesc(), "wf-"+stale)
+	for _, id := range []string{recent, running, deleting} {
+		c, err := cluster.New(db).GetCluster(id)
+		require.NoError(t, err)
+		require.NotEqual(t, pb.ClusterStatus_ERROR, c.GetStatus(), id)
+	}
+
+	appGroup, err := application.New(db).GetAppGroup(appGroupId)
+	require.NoError(t, err)
+	require.Equal(t, pb.AppGroupStatus_APP_GROUP_ERROR, appGroup.GetStatus())
+
+	require.Len(t, events, 2)
+	require.Equal(t, reconcile.KindCluster, events[0].Kind)
+	require.Equal(t, stale, events[0].ID)
+	require.Equal(t, "INSTALLING", events[0].FromStatus)
+	require.Equal(t, reconcile.KindAppGroup, events[1].Kind)
+	require.Equal(t, "P0000stale", events[1].ContractID)
+
+	// Errored resources are not marked again.
+	events = events[:0]
+	require.NoError(t, r.Reconcile(context.Background()))
+	require.Empty(t, events)
+}
+
+func TestParseTimeouts(t *testing.T) {
+	clusterTimeouts, err := reconcile.ParseClusterTimeouts(" installing=3h, DELETING=30m ")
+	require.NoError(t, err)
+	require.Equal(t, map[pb.ClusterStatus]time.Duration{
+		pb.ClusterStatus_INSTALLING: 3 * time.Hour,
+		pb.ClusterStatus_DELETING:   30 * time.Minute,
+	}, clusterTimeouts)
+
+	appGroupTimeouts, err := reconcile.ParseAppGroupTimeouts("APP_GROUP_INSTALLING=1h,DELETING=2h")
+	require.NoError(t, err)
+	require.Len(t, appGroupTimeouts, 2)
+
+	for _, value := range []string{"RUNNING=1h", "ERROR=1h", "INSTALLING", "INSTALLING=-1h", "UNKNOWN=1h"} {
+		_, err := reconcile.ParseClusterTimeouts(value)
+		require.Error(t, err, value)
+	}
+}
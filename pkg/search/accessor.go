@@ -0,0 +1,292 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-info/pkg/application"
+	appModel "github.com/openinfradev/tks-info/pkg/application/model"
+	"github.com/openinfradev/tks-info/pkg/cluster"
+	clusterModel "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/label"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+// Kinds of resources to search.
+const (
+	KindCluster  = "cluster"
+	KindAppGroup = "app_group"
+)
+
+// Ways to match the text.
+const (
+	MatchSubstring = "substring"
+	MatchPrefix    = "prefix"
+)
+
+// Fields to sort results by.
+const (
+	SortCreatedAt = "created_at"
+	SortUpdatedAt = "updated_at"
+)
+
+// ErrInvalidQuery is wrapped by the errors of queries which are not valid.
+var ErrInvalidQuery = errors.New("invalid search query")
+
+const (
+	defaultLimit = 100
+	maxLimit     = 1000
+)
+
+// Query is a search of clusters and app groups. Empty fields don't filter.
+type Query struct {
+	// Text is matched case-insensitively with names and descriptions, and external labels of app groups.
+	Text string `json:"text"`
+	// Match is how Text is matched, MatchSubstring by default.
+	Match string `json:"match"`
+	// Kinds are the kinds of resources to search, all kinds if empty.
+	Kinds []string `json:"kinds"`
+	// ContractID limits the search to the resources of the contract.
+	ContractID string `json:"contract_id"`
+	// Statuses are names of statuses such as RUNNING. The prefix APP_GROUP_ may be omitted for app groups.
+	// Statuses which a kind doesn't have, such as APP_GROUP_RUNNING for clusters, don't filter that kind.
+	Statuses []string `json:"statuses"`
+	// Creator is the id of the user who created the resources.
+	Creator string `json:"creator"`
+	// LabelSelector is a selector of labels such as "env=prod,tier in (web,api)".
+	LabelSelector string `json:"label_selector"`
+	// SortBy is SortCreatedAt, which is the default, or SortUpdatedAt.
+	SortBy     string `json:"sort_by"`
+	Descending bool   `json:"descending"`
+	// Offset and Limit page the results of each kind. Limit is 100 by default and up to 1000.
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+
+	selector label.Selector
+}
+
+// Result is the resources found, and the numbers of all resources matching the query regardless of paging.
+// Kubeconfigs of clusters are not included.
+type Result struct {
+	Clusters       []*pb.Cluster  `json:"clusters"`
+	AppGroups      []*pb.AppGroup `json:"app_groups"`
+	TotalClusters  int64          `json:"total_clusters"`
+	TotalAppGroups int64          `json:"total_app_groups"`
+}
+
+// Accessor searches clusters and app groups.
+type Accessor struct {
+	db *gorm.DB
+}
+
+// New returns new Accessor to search resources.
+func New(db *gorm.DB) *Accessor {
+	return &Accessor{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *Accessor) WithContext(ctx context.Context) *Accessor {
+	return &Accessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
+// Search returns the clusters and app groups matching the query.
+func (x *Accessor) Search(q Query) (*Result, error) {
+	if err := q.normalize(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidQuery, err)
+	}
+	result := &Result{
+		Clusters:  []*pb.Cluster{},
+		AppGroups: []*pb.AppGroup{},
+	}
+
+	if q.has(KindCluster) {
+		if err := x.searchClusters(q, result); err != nil {
+			return nil, err
+		}
+	}
+	if q.has(KindAppGroup) {
+		if err := x.searchAppGroups(q, result); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func (x *Accessor) searchClusters(q Query, result *Result) error {
+	db := x.db.Model(&clusterModel.Cluster{})
+	if q.Text != "" {
+		pattern := q.pattern()
+		db = db.Where("(name ILIKE ? OR description ILIKE ?)", pattern, pattern)
+	}
+	if q.ContractID != "" {
+		db = db.Where("contract_id = ?", q.ContractID)
+	}
+	// Statuses which only app groups have don't filter clusters.
+	statuses := []pb.ClusterStatus{}
+	for _, s := range q.Statuses {
+		if status, ok := pb.ClusterStatus_value[s]; ok {
+			statuses = append(statuses, pb.ClusterStatus(status))
+		}
+	}
+	if len(statuses) > 0 {
+		db = db.Where("status IN ?", statuses)
+	}
+	if q.Creator != "" {
+		db = db.Where("creator = ?", q.Creator)
+	}
+	db = q.selector.Where(db, "labels")
+
+	if err := db.Session(&gorm.Session{}).Count(&result.TotalClusters).Error; err != nil {
+		return fmt.Errorf("failed to count clusters: %w", err)
+	}
+	var clusters []clusterModel.Cluster
+	if err := db.Order(q.order("")).Offset(q.Offset).Limit(q.Limit).Find(&clusters).Error; err != nil {
+		return fmt.Errorf("failed to search clusters: %w", err)
+	}
+	for _, c := range clusters {
+		pbCluster := cluster.ConvertToPbCluster(c)
+		pbCluster.Kubeconfig = ""
+		result.Clusters = append(result.Clusters, pbCluster)
+	}
+	return nil
+}
+
+func (x *Accessor) searchAppGroups(q Query, result *Result) error {
+	db := x.db.Model(&appModel.ApplicationGroup{})
+	if q.Text != "" {
+		pattern := q.pattern()
+		db = db.Where("(application_groups.name ILIKE ? OR application_groups.description ILIKE ? OR application_groups.external_label ILIKE ?)",
+			pattern, pattern, pattern)
+	}
+	if q.ContractID != "" {
+		db = db.Joins("JOIN clusters ON clusters.id = application_groups.cluster_id").
+			Where("clusters.contract_id = ?", q.ContractID)
+	}
+	// Statuses which only clusters have don't filter app groups.
+	statuses := []pb.AppGroupStatus{}
+	for _, s := range q.Statuses {
+		if status, ok := pb.AppGroupStatus_value[appGroupStatusName(s)]; ok {
+			statuses = append(statuses, pb.AppGroupStatus(status))
+		}
+	}
+	if len(statuses) > 0 {
+		db = db.Where("application_groups.status IN ?", statuses)
+	}
+	if q.Creator != "" {
+		db = db.Where("application_groups.creator = ?", q.Creator)
+	}
+	db = q.selector.Where(db, "application_groups.labels")
+
+	if err := db.Session(&gorm.Session{}).Count(&result.TotalAppGroups).Error; err != nil {
+		return fmt.Errorf("failed to count app groups: %w", err)
+	}
+	var appGroups []appModel.ApplicationGroup
+	if err := db.Select("application_groups.*").Order(q.order("application_groups.")).Offset(q.Offset).Limit(q.Limit).
+		Find(&appGroups).Error; err != nil {
+		return fmt.Errorf("failed to search app groups: %w", err)
+	}
+	for _, appGroup := range appGroups {
+		result.AppGroups = append(result.AppGroups, application.ConvertToPbAppGroup(appGroup))
+	}
+	return nil
+}
+
+// normalize validates the query, and fills in the defaults.
+func (q *Query) normalize() error {
+	switch q.Match {
+	case "":
+		q.Match = MatchSubstring
+	case MatchSubstring, MatchPrefix:
+	default:
+		return fmt.Errorf("match must be %s or %s", MatchSubstring, MatchPrefix)
+	}
+	if len(q.Kinds) == 0 {
+		q.Kinds = []string{KindCluster, KindAppGroup}
+	}
+	for _, kind := range q.Kinds {
+		if kind != KindCluster && kind != KindAppGroup {
+			return fmt.Errorf("kind must be %s or %s", KindCluster, KindAppGroup)
+		}
+	}
+	statuses := make([]string, 0, len(q.Statuses))
+	for _, s := range q.Statuses {
+		s = strings.ToUpper(strings.TrimSpace(s))
+		_, isCluster := pb.ClusterStatus_value[s]
+		_, isAppGroup := pb.AppGroupStatus_value[appGroupStatusName(s)]
+		if !(isCluster && q.has(KindCluster)) && !(isAppGroup && q.has(KindAppGroup)) {
+			return fmt.Errorf("unknown status %s", s)
+		}
+		statuses = append(statuses, s)
+	}
+	q.Statuses = statuses
+	if q.Creator != "" {
+		if _, err := uuid.Parse(q.Creator); err != nil {
+			return fmt.Errorf("creator must be a uuid: %w", err)
+		}
+	}
+	selector, err := label.ParseSelector(q.LabelSelector)
+	if err != nil {
+		return err
+	}
+	q.selector = selector
+	switch q.SortBy {
+	case "":
+		q.SortBy = SortCreatedAt
+	case SortCreatedAt, SortUpdatedAt:
+	default:
+		return fmt.Errorf("sort must be %s or %s", SortCreatedAt, SortUpdatedAt)
+	}
+	if q.Offset < 0 {
+		return fmt.Errorf("offset must not be negative")
+	}
+	if q.Limit <= 0 {
+		q.Limit = defaultLimit
+	} else if q.Limit > maxLimit {
+		q.Limit = maxLimit
+	}
+	return nil
+}
+
+func (q *Query) has(kind string) bool {
+	for _, k := range q.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// pattern returns the ILIKE pattern of the text, in which wildcards of the text match themselves.
+func (q *Query) pattern() string {
+	text := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(q.Text)
+	if q.Match == MatchPrefix {
+		return text + "%"
+	}
+	return "%" + text + "%"
+}
+
+// order returns the ORDER BY clause of the columns of a table with the prefix.
+// The id breaks ties, so that pages don't overlap.
+func (q *Query) order(prefix string) string {
+	direction := "ASC"
+	if q.Descending {
+		direction = "DESC"
+	}
+	return fmt.Sprintf("%s%s %s, %sid %s", prefix, q.SortBy, direction, prefix, direction)
+}
+
+func appGroupStatusName(status string) string {
+	if strings.HasPrefix(status, "APP_GROUP_") {
+		return status
+	}
+	return "APP_GROUP_" + status
+}
@@ -0,0 +1,178 @@
+package search_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+
+	"github.com/openinfradev/tks-info/pkg/application"
+	modelApplication "github.com/openinfradev/tks-info/pkg/application/model"
+	"github.com/openinfradev/tks-info/pkg/cluster"
+	modelCluster "github.com/openinfradev/tks-info/pkg/cluster/model"
+	"github.com/openinfradev/tks-info/pkg/csp_info"
+	modelCspInfo "github.com/openinfradev/tks-info/pkg/csp_info/model"
+	"github.com/openinfradev/tks-info/pkg/search"
+	pb "github.com/openinfradev/tks-proto/tks_pb"
+)
+
+var (
+	db         *gorm.DB
+	testDBHost string
+	testDBPort string
+)
+
+func init() {
+	log.Disable()
+}
+
+func getDB() (*gorm.DB, error) {
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Seoul",
+		testDBHost, "postgres", "password", "tks", testDBPort)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`)
+
+	if err := db.AutoMigrate(
+		&modelCspInfo.CSPInfo{},
+		&modelCluster.Cluster{},
+		&modelApplication.ApplicationGroup{},
+	); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func TestMain(m *testing.M) {
+	pool, resource, err := helper.CreatePostgres()
+	if err != nil {
+		fmt.Printf("Could not create postgres: %s", err)
+		os.Exit(-1)
+	}
+	testDBHost, testDBPort = helper.GetHostAndPort(resource)
+	db, _ = getDB()
+
+	code := m.Run()
+
+	if err := helper.RemovePostgres(pool, resource); err != nil {
+		fmt.Printf("Could not remove postgres: %s", err)
+		os.Exit(-1)
+	}
+	os.Exit(code)
+}
+
+func createCluster(t *testing.T, contractId string, name string, description string, creator uuid.UUID) string {
+	cspId, err := csp_info.New(db).Create(contractId, "csp-"+name, "", pb.CspType_AWS)
+	require.NoError(t, err)
+	id, err := cluster.New(db).CreateClusterInfo(contractId, cspId, name, &pb.ClusterConf{}, creator, description)
+	require.NoError(t, err)
+	return id
+}
+
+func ids(result *search.Result) []string {
+	ids := []string{}
+	for _, c := range result.Clusters {
+		ids = append(ids, c.GetId())
+	}
+	for _, g := range result.AppGroups {
+		ids = append(ids, g.GetAppGroupId())
+	}
+	return ids
+}
+
+func TestSearch(t *testing.T) {
+	creator := uuid.New()
+	prod := createCluster(t, "P0000srch", "Prod-Seoul", "production", creator)
+	time.Sleep(10 * time.Millisecond)
+	dev := createCluster(t, "P0000srch", "dev-seoul", "100% for developers", uuid.Nil)
+	other := createCluster(t, "P0000othr", "prod-tokyo", "", uuid.Nil)
+	require.NoError(t, cluster.New(db).UpdateStatus(prod, pb.ClusterStatus_RUNNING, "", ""))
+	require.NoError(t, cluster.New(db).UpdateLabels(prod, map[string]string{"env": "prod", "tier": "web"}, nil))
+	require.NoError(t, cluster.New(db).UpdateLabels(dev, map[string]string{"env": "dev"}, nil))
+	lma, err := application.New(db).Create(dev, &pb.AppGroup{AppGroupName: "lma", Type: pb.AppGroupType_LMA, ExternalLabel: "seoul-lma", Status: pb.AppGroupStatus_APP_GROUP_RUNNING})
+	require.NoError(t, err)
+
+	accessor := search.New(db)
+	for name, tc := range map[string]struct {
+		query    search.Query
+		expected []string
+	}{
+		"substring ignoring case": {
+			query:    search.Query{Text: "SEOUL", ContractID: "P0000srch", Kinds: []string{search.KindCluster}},
+			expected: []string{prod, dev},
+		},
+		"prefix": {
+			query:    search.Query{Text: "prod", Match: search.MatchPrefix},
+			expected: []string{prod, other},
+		},
+		"external label": {
+			query:    search.Query{Text: "seoul-l"},
+			expected: []string{lma},
+		},
+		"wildcards match themselves": {
+			query:    search.Query{Text: "100%", ContractID: "P0000srch"},
+			expected: []string{dev},
+		},
+		"status": {
+			query:    search.Query{ContractID: "P0000srch", Statuses: []string{"running"}},
+			expected: []string{prod, lma},
+		},
+		"label selector": {
+			query:    search.Query{ContractID: "P0000srch", LabelSelector: "env in (prod,dev),tier!=web"},
+			expected: []string{dev},
+		},
+		"label selector of app groups": {
+			query:    search.Query{ContractID: "P0000srch", Kinds: []string{search.KindAppGroup}, LabelSelector: "!env"},
+			expected: []string{lma},
+		},
+		"status of the other kind": {
+			query:    search.Query{ContractID: "P0000srch", Statuses: []string{"APP_GROUP_RUNNING"}},
+			expected: []string{prod, dev, lma},
+		},
+		"creator": {
+			query:    search.Query{Creator: creator.String()},
+			expected: []string{prod},
+		},
+		"sort descending": {
+			query:    search.Query{ContractID: "P0000srch", Kinds: []string{search.KindCluster}, SortBy: search.SortCreatedAt, Descending: true},
+			expected: []string{dev, prod},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			result, err := accessor.Search(tc.query)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, ids(result))
+		})
+	}
+
+	result, err := accessor.Search(search.Query{ContractID: "P0000srch", Kinds: []string{search.KindCluster}, Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Equal(t, []string{dev}, ids(result))
+	require.Equal(t, int64(2), result.TotalClusters)
+	require.Empty(t, result.Clusters[0].GetKubeconfig())
+
+	for _, q := range []search.Query{
+		{Match: "regex"},
+		{Kinds: []string{"csp"}},
+		{Statuses: []string{"UNKNOWN"}},
+		{Creator: "me"},
+		{LabelSelector: "env in (prod"},
+		{SortBy: "name"},
+		{Offset: -1},
+	} {
+		_, err := accessor.Search(q)
+		require.ErrorIs(t, err, search.ErrInvalidQuery, q)
+	}
+}
@@ -0,0 +1,182 @@
+package tlsconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+)
+
+// Reloader keeps the server certificate and the client CA bundle up to date
+// with the files on disk, so that rotated certificates are used without restart.
+type Reloader struct {
+	certPath string
+	keyPath  string
+	caPath   string
+	interval time.Duration
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	digest    []byte
+}
+
+// NewReloader returns new Reloader which loads the files at once and checks them at every interval.
+// Client certificates are not verified if caPath is empty.
+func NewReloader(certPath string, keyPath string, caPath string, interval time.Duration) (*Reloader, error) {
+	r := &Reloader{
+		certPath: certPath,
+		keyPath:  keyPath,
+		caPath:   caPath,
+		interval: interval,
+	}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Reload loads the files if they changed, and returns true if they did.
+// The certificates in use are kept if the new files are invalid.
+func (r *Reloader) Reload() (bool, error) {
+	paths := []string{r.certPath, r.keyPath}
+	if r.caPath != "" {
+		paths = append(paths, r.caPath)
+	}
+	contents := make([][]byte, len(paths))
+	h := sha256.New()
+	for i, p := range paths {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return false, err
+		}
+		contents[i] = b
+		h.Write(b)
+	}
+	digest := h.Sum(nil)
+
+	r.mu.RLock()
+	unchanged := bytes.Equal(digest, r.digest)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(contents[0], contents[1])
+	if err != nil {
+		return false, fmt.Errorf("failed to load key pair: %w", err)
+	}
+	var clientCAs *x509.CertPool
+	if r.caPath != "" {
+		clientCAs = x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(contents[2]) {
+			return false, fmt.Errorf("no certificates in %s", r.caPath)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.digest = digest
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Run reloads the files at every interval until ctx is done.
+func (r *Reloader) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil {
+				log.Error("failed to reload TLS certificates. keep using the previous ones. err : ", err)
+			} else if reloaded {
+				log.Info("reloaded TLS certificates")
+			}
+		}
+	}
+}
+
+// ServerConfig returns the TLS config of the server. Each handshake uses the latest certificates.
+// If the reloader has a client CA bundle, clients must present a certificate signed by it,
+// and if allowedSANs is not empty, one of the SANs of the certificate must match one of the patterns.
+func (r *Reloader) ServerConfig(allowedSANs []string) *tls.Config {
+	return r.serverConfig(allowedSANs, []string{"h2"})
+}
+
+// HTTPServerConfig works like ServerConfig, but for HTTP servers accepting HTTP/1.1 as well.
+func (r *Reloader) HTTPServerConfig(allowedSANs []string) *tls.Config {
+	return r.serverConfig(allowedSANs, []string{"h2", "http/1.1"})
+}
+
+func (r *Reloader) serverConfig(allowedSANs []string, nextProtos []string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			config := &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   nextProtos,
+			}
+			if r.clientCAs != nil {
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+				config.ClientCAs = r.clientCAs
+				config.VerifyPeerCertificate = verifySANs(allowedSANs)
+			}
+			return config, nil
+		},
+	}
+}
+
+// verifySANs checks the SANs of the verified client certificate against the patterns
+// of path.Match, such as "*.tks.svc" or "spiffe://cluster.local/ns/tks/sa/*".
+func verifySANs(allowed []string) func([][]byte, [][]*x509.Certificate) error {
+	return func(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+		if len(allowed) == 0 {
+			return nil
+		}
+		if len(verifiedChains) == 0 || len(verifiedChains[0]) == 0 {
+			return errors.New("no verified client certificate")
+		}
+		leaf := verifiedChains[0][0]
+		for _, san := range SANs(leaf) {
+			for _, pattern := range allowed {
+				if ok, _ := path.Match(pattern, san); ok {
+					return nil
+				}
+			}
+		}
+		return fmt.Errorf("client certificate %q is not allowed", leaf.Subject.CommonName)
+	}
+}
+
+// SANs returns the subject alternative names of the certificate.
+func SANs(cert *x509.Certificate) []string {
+	sans := []string{}
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	return sans
+}
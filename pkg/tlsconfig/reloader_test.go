@@ -0,0 +1,212 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/tlsconfig"
+)
+
+func init() {
+	log.Disable()
+}
+
+type authority struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+var serial int64
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func newAuthority(t *testing.T) *authority {
+	key := newKey(t)
+	serial++
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(serial),
+		Subject:               pkix.Name{CommonName: "tks-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &authority{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue returns a certificate and key in PEM signed by the authority.
+func (a *authority) issue(t *testing.T, cn string, dnsNames []string, uris []string) ([]byte, []byte) {
+	key := newKey(t)
+	serial++
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		DNSNames:     dnsNames,
+	}
+	for _, u := range uris {
+		parsed, err := url.Parse(u)
+		require.NoError(t, err)
+		template.URIs = append(template.URIs, parsed)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, a.cert, &key.PublicKey, a.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, content []byte) {
+	require.NoError(t, os.WriteFile(path, content, 0600))
+}
+
+// handshake connects to a server with config and returns the serial number of the server certificate.
+func handshake(t *testing.T, serverConfig *tls.Config, clientConfig *tls.Config) (*big.Int, error) {
+	lis, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	require.NoError(t, err)
+	defer lis.Close()
+
+	errs := make(chan error, 1)
+	go func() {
+		conn, err := lis.Accept()
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer conn.Close()
+		err = conn.(*tls.Conn).Handshake()
+		if err == nil {
+			_, err = conn.Write([]byte("ok"))
+		}
+		errs <- err
+	}()
+
+	client, err := tls.Dial("tcp", lis.Addr().String(), clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	// With TLS 1.3 the server rejects client certificates after the client finished
+	// its handshake, so read the reply to see the result of the server.
+	client.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := client.Read(make([]byte, 2)); err != nil {
+		return nil, err
+	}
+	if err := <-errs; err != nil {
+		return nil, err
+	}
+	return client.ConnectionState().PeerCertificates[0].SerialNumber, nil
+}
+
+func clientConfig(t *testing.T, ca *authority, certPEM []byte, keyPEM []byte) *tls.Config {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	config := &tls.Config{RootCAs: roots, ServerName: "tks-info"}
+	if certPEM != nil {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		require.NoError(t, err)
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config
+}
+
+func TestServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t)
+	certPath, keyPath, caPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+	certPEM, keyPEM := ca.issue(t, "tks-info", []string{"tks-info"}, nil)
+	writeFile(t, certPath, certPEM)
+	writeFile(t, keyPath, keyPEM)
+	writeFile(t, caPath, ca.pem)
+
+	reloader, err := tlsconfig.NewReloader(certPath, keyPath, caPath, time.Minute)
+	require.NoError(t, err)
+	config := reloader.ServerConfig([]string{"*.tks.svc", "spiffe://cluster.local/ns/tks/sa/*"})
+
+	lcmCert, lcmKey := ca.issue(t, "lcm", []string{"tks-cluster-lcm.tks.svc"}, nil)
+	_, err = handshake(t, config, clientConfig(t, ca, lcmCert, lcmKey))
+	require.NoError(t, err)
+
+	spiffeCert, spiffeKey := ca.issue(t, "api", nil, []string{"spiffe://cluster.local/ns/tks/sa/tks-api"})
+	_, err = handshake(t, config, clientConfig(t, ca, spiffeCert, spiffeKey))
+	require.NoError(t, err)
+
+	otherCert, otherKey := ca.issue(t, "other", []string{"other.default.svc"}, nil)
+	_, err = handshake(t, config, clientConfig(t, ca, otherCert, otherKey))
+	require.Error(t, err)
+
+	_, err = handshake(t, config, clientConfig(t, ca, nil, nil))
+	require.Error(t, err)
+
+	untrusted := newAuthority(t)
+	untrustedCert, untrustedKey := untrusted.issue(t, "lcm", []string{"tks-cluster-lcm.tks.svc"}, nil)
+	_, err = handshake(t, config, clientConfig(t, ca, untrustedCert, untrustedKey))
+	require.Error(t, err)
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	ca := newAuthority(t)
+	certPath, keyPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key")
+	certPEM, keyPEM := ca.issue(t, "tks-info", []string{"tks-info"}, nil)
+	writeFile(t, certPath, certPEM)
+	writeFile(t, keyPath, keyPEM)
+
+	reloader, err := tlsconfig.NewReloader(certPath, keyPath, "", time.Minute)
+	require.NoError(t, err)
+	config := reloader.ServerConfig(nil)
+
+	first, err := handshake(t, config, clientConfig(t, ca, nil, nil))
+	require.NoError(t, err)
+
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	certPEM, keyPEM = ca.issue(t, "tks-info", []string{"tks-info"}, nil)
+	writeFile(t, certPath, certPEM)
+	writeFile(t, keyPath, keyPEM)
+	reloaded, err = reloader.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+
+	second, err := handshake(t, config, clientConfig(t, ca, nil, nil))
+	require.NoError(t, err)
+	require.NotEqual(t, first, second)
+
+	// A broken file, such as one half written, keeps the current certificate.
+	writeFile(t, keyPath, []byte("broken"))
+	_, err = reloader.Reload()
+	require.Error(t, err)
+	third, err := handshake(t, config, clientConfig(t, ca, nil, nil))
+	require.NoError(t, err)
+	require.Equal(t, second, third)
+}
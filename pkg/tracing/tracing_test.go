@@ -0,0 +1,36 @@
+package tracing_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+
+	"github.com/openinfradev/tks-info/pkg/tracing"
+)
+
+func TestInitFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := tracing.Init(context.Background(), tracing.Config{
+		Exporter:    tracing.ExporterFile,
+		File:        path,
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+
+	_, span := otel.Tracer("test").Start(context.Background(), "GetClusters")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(b), "GetClusters")
+}
+
+func TestInitUnknownExporter(t *testing.T) {
+	_, err := tracing.Init(context.Background(), tracing.Config{Exporter: "jaeger"})
+	require.Error(t, err)
+}
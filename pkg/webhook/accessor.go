@@ -0,0 +1,220 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-info/pkg/webhook/model"
+)
+
+// ErrNotFound is returned when a subscription or a dead letter does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Accessor accesses to webhook subscriptions and their deliveries.
+type Accessor struct {
+	db *gorm.DB
+}
+
+// New returns new Accessor to access webhooks.
+func New(db *gorm.DB) *Accessor {
+	return &Accessor{
+		db: db,
+	}
+}
+
+// WithContext returns a copy of the accessor which runs queries with ctx.
+func (x *Accessor) WithContext(ctx context.Context) *Accessor {
+	return &Accessor{
+		db: x.db.WithContext(ctx),
+	}
+}
+
+// CreateSubscription validates and inserts the subscription, and sets its ID.
+func (x *Accessor) CreateSubscription(s *model.Subscription) error {
+	if err := ValidateSubscription(*s); err != nil {
+		return err
+	}
+	return x.db.Create(s).Error
+}
+
+// GetSubscriptions returns the subscriptions of the contract, or of all contracts if contractId is empty.
+func (x *Accessor) GetSubscriptions(contractId string) ([]model.Subscription, error) {
+	var subscriptions []model.Subscription
+	db := x.db.Order("created_at")
+	if contractId != "" {
+		db = db.Where("contract_id = ?", contractId)
+	}
+	return subscriptions, db.Find(&subscriptions).Error
+}
+
+// GetSubscription returns the subscription.
+func (x *Accessor) GetSubscription(id uuid.UUID) (model.Subscription, error) {
+	var subscription model.Subscription
+	res := x.db.First(&subscription, "id = ?", id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return model.Subscription{}, fmt.Errorf("%w: subscription %s", ErrNotFound, id)
+	}
+	return subscription, res.Error
+}
+
+// DeleteSubscription deletes the subscription with the deliveries waiting to be sent to it.
+func (x *Accessor) DeleteSubscription(id uuid.UUID) error {
+	return x.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("subscription_id = ?", id).Delete(&model.Delivery{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&model.Subscription{}, "id = ?", id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%w: subscription %s", ErrNotFound, id)
+		}
+		return nil
+	})
+}
+
+// Publish queues the event for the subscriptions which match it, and returns the number of them.
+func (x *Accessor) Publish(e Event) (int, error) {
+	var subscriptions []model.Subscription
+	if err := x.db.Find(&subscriptions, "contract_id = ?", e.ContractID).Error; err != nil {
+		return 0, err
+	}
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return 0, err
+	}
+
+	deliveries := []model.Delivery{}
+	for _, s := range subscriptions {
+		if Matches(s, e) {
+			deliveries = append(deliveries, model.Delivery{
+				SubscriptionID: s.ID,
+				EventType:      e.Type,
+				Payload:        payload,
+				NextAttemptAt:  e.Time,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+	return len(deliveries), x.db.Create(&deliveries).Error
+}
+
+// Claim returns up to limit deliveries due at now, and pushes back their next attempts to until,
+// so that other replicas don't send them in the meantime.
+func (x *Accessor) Claim(now time.Time, until time.Time, limit int) ([]model.Delivery, error) {
+	var deliveries []model.Delivery
+	err := x.db.Raw(`
+		UPDATE webhook_deliveries SET next_attempt_at = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries WHERE next_attempt_at <= ?
+			ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, until, now, now, limit).
+		Scan(&deliveries).Error
+	return deliveries, err
+}
+
+// GetSubscriptionsByIDs returns the subscriptions with the ids by their ids.
+func (x *Accessor) GetSubscriptionsByIDs(ids []uuid.UUID) (map[uuid.UUID]model.Subscription, error) {
+	var subscriptions []model.Subscription
+	if err := x.db.Find(&subscriptions, "id IN ?", ids).Error; err != nil {
+		return nil, err
+	}
+	byID := map[uuid.UUID]model.Subscription{}
+	for _, s := range subscriptions {
+		byID[s.ID] = s
+	}
+	return byID, nil
+}
+
+// Complete deletes the delivery which has been sent.
+func (x *Accessor) Complete(id uuid.UUID) error {
+	return x.db.Delete(&model.Delivery{}, "id = ?", id).Error
+}
+
+// Reschedule stores the failed attempts of the delivery, which is sent again at nextAttemptAt.
+func (x *Accessor) Reschedule(id uuid.UUID, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return x.db.Model(&model.Delivery{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"attempts": attempts, "next_attempt_at": nextAttemptAt, "last_error": lastError}).
+		Error
+}
+
+// Bury moves the delivery, which failed to be sent to the subscription too many times, to the dead letters.
+func (x *Accessor) Bury(d model.Delivery, s model.Subscription, lastError string) error {
+	return x.db.Transaction(func(tx *gorm.DB) error {
+		deadLetter := model.DeadLetter{
+			SubscriptionID: s.ID,
+			ContractID:     s.ContractID,
+			URL:            s.URL,
+			EventType:      d.EventType,
+			Payload:        d.Payload,
+			Attempts:       d.Attempts,
+			LastError:      lastError,
+		}
+		if err := tx.Create(&deadLetter).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Delivery{}, "id = ?", d.ID).Error
+	})
+}
+
+// GetDeadLetters returns the dead letters of the contract, or of all contracts if contractId is empty.
+func (x *Accessor) GetDeadLetters(contractId string) ([]model.DeadLetter, error) {
+	var deadLetters []model.DeadLetter
+	db := x.db.Order("created_at")
+	if contractId != "" {
+		db = db.Where("contract_id = ?", contractId)
+	}
+	return deadLetters, db.Find(&deadLetters).Error
+}
+
+// GetDeadLetter returns the dead letter.
+func (x *Accessor) GetDeadLetter(id uuid.UUID) (model.DeadLetter, error) {
+	var deadLetter model.DeadLetter
+	res := x.db.First(&deadLetter, "id = ?", id)
+	if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+		return model.DeadLetter{}, fmt.Errorf("%w: dead letter %s", ErrNotFound, id)
+	}
+	return deadLetter, res.Error
+}
+
+// Redeliver queues the dead letter to be sent to its subscription again, and deletes it.
+func (x *Accessor) Redeliver(id uuid.UUID, now time.Time) error {
+	return x.db.Transaction(func(tx *gorm.DB) error {
+		var deadLetter model.DeadLetter
+		res := tx.First(&deadLetter, "id = ?", id)
+		if errors.Is(res.Error, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: dead letter %s", ErrNotFound, id)
+		} else if res.Error != nil {
+			return res.Error
+		}
+		var count int64
+		if err := tx.Model(&model.Subscription{}).Where("id = ?", deadLetter.SubscriptionID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return fmt.Errorf("%w: subscription %s of dead letter %s", ErrNotFound, deadLetter.SubscriptionID, id)
+		}
+
+		delivery := model.Delivery{
+			SubscriptionID: deadLetter.SubscriptionID,
+			EventType:      deadLetter.EventType,
+			Payload:        deadLetter.Payload,
+			NextAttemptAt:  now,
+		}
+		if err := tx.Create(&delivery).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.DeadLetter{}, "id = ?", id).Error
+	})
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/openinfradev/tks-common/pkg/log"
+	"github.com/openinfradev/tks-info/pkg/webhook/model"
+)
+
+// Headers of requests to webhooks.
+const (
+	HeaderEvent     = "X-TKS-Event"
+	HeaderDelivery  = "X-TKS-Delivery"
+	HeaderTimestamp = "X-TKS-Timestamp"
+	// HeaderSignature is "sha256=" followed by the signature of the request by Sign.
+	HeaderSignature = "X-TKS-Signature"
+)
+
+const (
+	// workers is the number of deliveries sent at the same time.
+	workers = 10
+	// maxBackoff is the maximum delay of retries.
+	maxBackoff = time.Hour
+	// maxErrorBody is the length of response bodies kept in errors.
+	maxErrorBody = 200
+)
+
+// Sign returns the hex encoded HMAC-SHA256 of "<timestamp>.<payload>" with the secret of a subscription.
+// Receivers verify requests by comparing it with HeaderSignature, and reject old timestamps to prevent replays.
+func Sign(secret string, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher sends queued deliveries to webhooks. A failed delivery is retried with exponential backoff,
+// and moved to the dead letters after maxAttempts.
+type Dispatcher struct {
+	accessor    *Accessor
+	client      *http.Client
+	timeout     time.Duration
+	maxAttempts int
+	minBackoff  time.Duration
+	now         func() time.Time
+}
+
+// NewDispatcher returns new Dispatcher, which gives each request the timeout and retries failed ones
+// after minBackoff, doubling it up to an hour for each failure.
+func NewDispatcher(accessor *Accessor, timeout time.Duration, maxAttempts int, minBackoff time.Duration) *Dispatcher {
+	return &Dispatcher{
+		accessor:    accessor,
+		client:      &http.Client{},
+		timeout:     timeout,
+		maxAttempts: maxAttempts,
+		minBackoff:  minBackoff,
+		now:         time.Now,
+	}
+}
+
+// Deliver sends the deliveries due now until none is left, or the deadline of ctx is too close to send more.
+func (d *Dispatcher) Deliver(ctx context.Context) error {
+	for ctx.Err() == nil {
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d.timeout {
+			return nil
+		}
+		now := d.now()
+		deliveries, err := d.accessor.WithContext(ctx).Claim(now, now.Add(d.timeout+time.Minute), workers)
+		if err != nil {
+			return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+		ids := make([]uuid.UUID, 0, len(deliveries))
+		for _, delivery := range deliveries {
+			ids = append(ids, delivery.SubscriptionID)
+		}
+		subscriptions, err := d.accessor.WithContext(ctx).GetSubscriptionsByIDs(ids)
+		if err != nil {
+			return fmt.Errorf("failed to get webhook subscriptions: %w", err)
+		}
+
+		var wg sync.WaitGroup
+		errs := make(chan error, len(deliveries))
+		for _, delivery := range deliveries {
+			wg.Add(1)
+			go func(delivery model.Delivery) {
+				defer wg.Done()
+				errs <- d.deliver(ctx, delivery, subscriptions[delivery.SubscriptionID])
+			}(delivery)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// deliver sends the delivery, and records the result. It returns errors of the database only.
+func (d *Dispatcher) deliver(ctx context.Context, delivery model.Delivery, s model.Subscription) error {
+	// The subscription was deleted after the delivery was claimed.
+	if s.ID == uuid.Nil {
+		return d.accessor.Complete(delivery.ID)
+	}
+
+	err := d.send(ctx, delivery, s)
+	if err == nil {
+		return d.accessor.Complete(delivery.ID)
+	}
+	// The dispatcher is stopping, which is not a failure of the webhook.
+	if ctx.Err() != nil {
+		return d.accessor.Reschedule(delivery.ID, delivery.Attempts, d.now(), delivery.LastError)
+	}
+
+	delivery.Attempts++
+	if delivery.Attempts >= d.maxAttempts {
+		log.Warn("webhook delivery ", delivery.ID, " to ", s.URL, " failed ", delivery.Attempts, " times, and is moved to the dead letters. err : ", err)
+		return d.accessor.Bury(delivery, s, err.Error())
+	}
+	log.Debug("webhook delivery ", delivery.ID, " to ", s.URL, " failed. err : ", err)
+	return d.accessor.Reschedule(delivery.ID, delivery.Attempts, d.now().Add(d.backoff(delivery.Attempts)), err.Error())
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery model.Delivery, s model.Subscription) error {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tks-info")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, "sha256="+Sign(s.Secret, timestamp, delivery.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, maxErrorBody))
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s: %s", res.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
+
+// backoff returns the delay after the failed attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.minBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/openinfradev/tks-info/pkg/webhook/model"
+)
+
+// Types of resources in events.
+const (
+	ResourceCluster     = "cluster"
+	ResourceAppGroup    = "app_group"
+	ResourceAppServeApp = "app_serve_app"
+)
+
+// EventStatusChanged is the type of events of status updates.
+const EventStatusChanged = "status_changed"
+
+const (
+	// maxTextLength is the maximum length of the summary of an event.
+	maxTextLength = 500
+	// maxStatusDescLength is the maximum length of the status description of an event.
+	maxStatusDescLength = 10000
+)
+
+var resourceTypes = map[string]bool{
+	ResourceCluster:     true,
+	ResourceAppGroup:    true,
+	ResourceAppServeApp: true,
+}
+
+// Event is a change of a resource, which is sent to the subscriptions of its contract as a JSON payload.
+type Event struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	ResourceType string    `json:"resource_type"`
+	ResourceID   string    `json:"resource_id"`
+	ResourceName string    `json:"resource_name,omitempty"`
+	ContractID   string    `json:"contract_id"`
+	Status       string    `json:"status"`
+	StatusDesc   string    `json:"status_desc,omitempty"`
+	Time         time.Time `json:"time"`
+	// Text summarizes the event, so that payloads can be posted to Slack incoming webhooks as they are.
+	Text string `json:"text"`
+}
+
+// NewStatusEvent returns the event of a status update of the resource.
+func NewStatusEvent(resourceType, id, name, contractId, status, statusDesc string) Event {
+	statusDesc = truncate(statusDesc, maxStatusDescLength)
+	text := fmt.Sprintf("%s %s", resourceType, id)
+	if name != "" {
+		text = fmt.Sprintf("%s %s (%s)", resourceType, name, id)
+	}
+	text += fmt.Sprintf(" of contract %s is %s", contractId, status)
+	if statusDesc != "" {
+		text += ": " + statusDesc
+	}
+
+	return Event{
+		ID:           uuid.New().String(),
+		Type:         EventStatusChanged,
+		ResourceType: resourceType,
+		ResourceID:   id,
+		ResourceName: name,
+		ContractID:   contractId,
+		Status:       status,
+		StatusDesc:   statusDesc,
+		Time:         time.Now(),
+		Text:         truncate(text, maxTextLength),
+	}
+}
+
+// truncate cuts value to the length, ending it with "..." if it is cut.
+func truncate(value string, length int) string {
+	if utf8.RuneCountInString(value) <= length {
+		return value
+	}
+	return string([]rune(value)[:length-3]) + "..."
+}
+
+// Matches reports whether the subscription is notified of the event by its filters.
+// The prefix APP_GROUP_ of app group statuses may be omitted in filters.
+func Matches(s model.Subscription, e Event) bool {
+	if s.ContractID != e.ContractID {
+		return false
+	}
+	if types := SplitList(s.ResourceTypes); len(types) > 0 && !contains(types, e.ResourceType) {
+		return false
+	}
+	if statuses := SplitList(s.Statuses); len(statuses) > 0 && !contains(statuses, e.Status) &&
+		!(e.ResourceType == ResourceAppGroup && contains(statuses, strings.TrimPrefix(e.Status, "APP_GROUP_"))) {
+		return false
+	}
+	return true
+}
+
+// ValidateSubscription checks the URL and the filters of the subscription.
+func ValidateSubscription(s model.Subscription) error {
+	if !strings.HasPrefix(s.URL, "http://") && !strings.HasPrefix(s.URL, "https://") {
+		return fmt.Errorf("url %q must be http or https", s.URL)
+	}
+	if s.Secret == "" {
+		return fmt.Errorf("secret must not be empty")
+	}
+	for _, t := range SplitList(s.ResourceTypes) {
+		if !resourceTypes[t] {
+			return fmt.Errorf("unknown resource type %q, which must be one of %s, %s and %s",
+				t, ResourceCluster, ResourceAppGroup, ResourceAppServeApp)
+		}
+	}
+	return nil
+}
+
+// SplitList splits a comma separated filter of a subscription.
+func SplitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if strings.EqualFold(i, item) {
+			return true
+		}
+	}
+	return false
+}
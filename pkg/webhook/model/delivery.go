@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	uuid "github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Delivery is a payload waiting to be sent to a subscription.
+type Delivery struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;index"`
+	EventType      string
+	Payload        datatypes.JSON
+	// Attempts is the number of failed attempts to send the payload.
+	Attempts int
+	// NextAttemptAt is when the payload is sent next. It is pushed back while a replica is sending it.
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+func (c *Delivery) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// DeadLetter is a payload which failed to be sent within the maximum number of attempts.
+type DeadLetter struct {
+	ID             uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	SubscriptionID uuid.UUID `gorm:"type:uuid;index"`
+	ContractID     string    `gorm:"index"`
+	URL            string
+	EventType      string
+	Payload        datatypes.JSON
+	Attempts       int
+	LastError      string
+	CreatedAt      time.Time
+}
+
+func (c *DeadLetter) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
+
+func (DeadLetter) TableName() string {
+	return "webhook_dead_letters"
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	uuid "github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Subscription is a webhook which is notified of status changes of resources of a contract.
+type Subscription struct {
+	ID         uuid.UUID `gorm:"primarykey;type:uuid;default:uuid_generate_v4()"`
+	ContractID string    `gorm:"index"`
+	URL        string
+	// Secret is the key of the HMAC signatures of payloads.
+	Secret string
+	// ResourceTypes is a comma separated list of resource types to notify, all types if empty.
+	ResourceTypes string
+	// Statuses is a comma separated list of statuses to notify, all statuses if empty.
+	Statuses  string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (c *Subscription) BeforeCreate(tx *gorm.DB) (err error) {
+	c.ID = uuid.New()
+	return nil
+}
+
+func (Subscription) TableName() string {
+	return "webhook_subscriptions"
+}
@@ -0,0 +1,212 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/openinfradev/tks-common/pkg/helper"
+	"github.com/openinfradev/tks-common/pkg/log"
+
+	"github.com/openinfradev/tks-info/pkg/webhook"
+	"github.com/openinfradev/tks-info/pkg/webhook/model"
+)
+
+var (
+	db         *gorm.DB
+	testDBHost string
+	testDBPort string
+)
+
+func init() {
+	log.Disable()
+}
+
+func getDB() (*gorm.DB, error) {
+	dsn := fmt.Sprintf(
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Seoul",
+		testDBHost, "postgres", "password", "tks", testDBPort)
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.AutoMigrate(&model.Subscription{}, &model.Delivery{}, &model.DeadLetter{}); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func TestMain(m *testing.M) {
+	pool, resource, err := helper.CreatePostgres()
+	if err != nil {
+		fmt.Printf("Could not create postgres: %s", err)
+		os.Exit(-1)
+	}
+	testDBHost, testDBPort = helper.GetHostAndPort(resource)
+	db, _ = getDB()
+
+	code := m.Run()
+
+	if err := helper.RemovePostgres(pool, resource); err != nil {
+		fmt.Printf("Could not remove postgres: %s", err)
+		os.Exit(-1)
+	}
+	os.Exit(code)
+}
+
+// receiver is a webhook which records the requests it receives, and fails until failures run out.
+type receiver struct {
+	mu       sync.Mutex
+	failures int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	if r.failures > 0 {
+		r.failures--
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}
+}
+
+func TestDeliver(t *testing.T) {
+	recv := &receiver{failures: 1}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	accessor := webhook.New(db)
+	running := &model.Subscription{ContractID: "P0000hook", URL: server.URL, Secret: "secret", ResourceTypes: "cluster", Statuses: "RUNNING"}
+	require.NoError(t, accessor.CreateSubscription(running))
+	all := &model.Subscription{ContractID: "P0000hook", URL: server.URL + "/all", Secret: "secret"}
+	require.NoError(t, accessor.CreateSubscription(all))
+	other := &model.Subscription{ContractID: "P0000other", URL: server.URL + "/other", Secret: "secret"}
+	require.NoError(t, accessor.CreateSubscription(other))
+
+	n, err := accessor.Publish(webhook.NewStatusEvent(webhook.ResourceCluster, "c0000001", "dev", "P0000hook", "RUNNING", ""))
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	n, err = accessor.Publish(webhook.NewStatusEvent(webhook.ResourceCluster, "c0000001", "dev", "P0000hook", "INSTALLING", ""))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	dispatcher := webhook.NewDispatcher(accessor, time.Second, 3, time.Millisecond)
+	require.Eventually(t, func() bool {
+		if err := dispatcher.Deliver(context.Background()); err != nil {
+			t.Log(err)
+			return false
+		}
+		recv.mu.Lock()
+		defer recv.mu.Unlock()
+		return len(recv.requests) == 4
+	}, 5*time.Second, 10*time.Millisecond, "the failed delivery must be retried")
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	for i, req := range recv.requests {
+		require.NotEqual(t, "/other", req.URL.Path)
+		require.Equal(t, webhook.EventStatusChanged, req.Header.Get(webhook.HeaderEvent))
+		signature := webhook.Sign("secret", req.Header.Get(webhook.HeaderTimestamp), recv.bodies[i])
+		require.Equal(t, "sha256="+signature, req.Header.Get(webhook.HeaderSignature))
+
+		var e webhook.Event
+		require.NoError(t, json.Unmarshal(recv.bodies[i], &e))
+		require.Equal(t, "c0000001", e.ResourceID)
+		require.True(t, strings.HasPrefix(e.Text, "cluster dev (c0000001) of contract P0000hook is "), e.Text)
+	}
+	require.NoError(t, accessor.DeleteSubscription(other.ID))
+}
+
+func TestDeadLetters(t *testing.T) {
+	recv := &receiver{failures: 3}
+	server := httptest.NewServer(recv)
+	defer server.Close()
+
+	accessor := webhook.New(db)
+	s := &model.Subscription{ContractID: "P0000dead", URL: server.URL, Secret: "secret"}
+	require.NoError(t, accessor.CreateSubscription(s))
+	_, err := accessor.Publish(webhook.NewStatusEvent(webhook.ResourceAppServeApp, "a0000001", "", "P0000dead", "DEPLOY_FAILED", "image not found"))
+	require.NoError(t, err)
+
+	dispatcher := webhook.NewDispatcher(accessor, time.Second, 2, time.Millisecond)
+	var deadLetters []model.DeadLetter
+	require.Eventually(t, func() bool {
+		if err := dispatcher.Deliver(context.Background()); err != nil {
+			t.Log(err)
+			return false
+		}
+		deadLetters, err = accessor.GetDeadLetters("P0000dead")
+		return err == nil && len(deadLetters) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 2, deadLetters[0].Attempts)
+	require.Contains(t, deadLetters[0].LastError, "503")
+	deadLetter, err := accessor.GetDeadLetter(deadLetters[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, s.ID, deadLetter.SubscriptionID)
+	require.Equal(t, "P0000dead", deadLetter.ContractID)
+
+	// A redelivered dead letter is sent again, which fails once more before it succeeds.
+	require.NoError(t, accessor.Redeliver(deadLetters[0].ID, time.Now()))
+	require.Eventually(t, func() bool {
+		if err := dispatcher.Deliver(context.Background()); err != nil {
+			t.Log(err)
+			return false
+		}
+		recv.mu.Lock()
+		defer recv.mu.Unlock()
+		return len(recv.requests) == 4
+	}, 5*time.Second, 10*time.Millisecond)
+	deadLetters, err = accessor.GetDeadLetters("P0000dead")
+	require.NoError(t, err)
+	require.Empty(t, deadLetters)
+
+	_, err = accessor.GetDeadLetter(deadLetter.ID)
+	require.ErrorIs(t, err, webhook.ErrNotFound)
+
+	subscription, err := accessor.GetSubscription(s.ID)
+	require.NoError(t, err)
+	require.Equal(t, "P0000dead", subscription.ContractID)
+	require.NoError(t, accessor.DeleteSubscription(s.ID))
+	require.ErrorIs(t, accessor.DeleteSubscription(s.ID), webhook.ErrNotFound)
+	_, err = accessor.GetSubscription(s.ID)
+	require.ErrorIs(t, err, webhook.ErrNotFound)
+}
+
+func TestMatches(t *testing.T) {
+	e := webhook.NewStatusEvent(webhook.ResourceAppGroup, "a0000001", "lma", "P0000001", "APP_GROUP_ERROR", "")
+	for filter, matches := range map[[2]string]bool{
+		{"", ""}:                               true,
+		{"app_group", "APP_GROUP_ERROR"}:       true,
+		{"cluster,app_group", "error,RUNNING"}: true,
+		{"cluster", ""}:                        false,
+		{"", "APP_GROUP_RUNNING"}:              false,
+	} {
+		s := model.Subscription{ContractID: "P0000001", ResourceTypes: filter[0], Statuses: filter[1]}
+		require.Equal(t, matches, webhook.Matches(s, e), filter)
+	}
+	require.False(t, webhook.Matches(model.Subscription{ContractID: "P0000002"}, e))
+}
+
+func TestValidateSubscription(t *testing.T) {
+	require.NoError(t, webhook.ValidateSubscription(model.Subscription{URL: "https://hooks.slack.com/services/x", Secret: "s", ResourceTypes: "cluster, app_serve_app"}))
+	require.Error(t, webhook.ValidateSubscription(model.Subscription{URL: "ftp://host", Secret: "s"}))
+	require.Error(t, webhook.ValidateSubscription(model.Subscription{URL: "https://host"}))
+	require.Error(t, webhook.ValidateSubscription(model.Subscription{URL: "https://host", Secret: "s", ResourceTypes: "csp"}))
+}
@@ -10,9 +10,12 @@ CREATE TABLE app_serve_apps
     endpoint_url character varying(300) COLLATE pg_catalog."default",
     preview_endpoint_url character varying(300) COLLATE pg_catalog."default",
     target_cluster_id character varying(10) COLLATE pg_catalog."default",
+    labels jsonb,
+    annotations jsonb,
     updated_at timestamp with time zone,
     created_at timestamp with time zone
 );
+ALTER TABLE app_serve_apps ADD COLUMN IF NOT EXISTS labels jsonb, ADD COLUMN IF NOT EXISTS annotations jsonb;
 CREATE TABLE app_serve_app_tasks
 (
     id uuid primary key,
@@ -12,9 +12,12 @@ CREATE TABLE application_groups
     external_label character varying(50) COLLATE pg_catalog."default",
     creator uuid,
     description character varying(100) COLLATE pg_catalog."default",
+    labels jsonb,
+    annotations jsonb,
     updated_at timestamp with time zone,
     created_at timestamp with time zone
 );
+ALTER TABLE application_groups ADD COLUMN IF NOT EXISTS labels jsonb, ADD COLUMN IF NOT EXISTS annotations jsonb;
 CREATE TABLE applications
 (
     id uuid primary key,
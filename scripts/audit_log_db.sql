@@ -0,0 +1,27 @@
+\c tks;
+CREATE TABLE audit_logs
+(
+    id uuid primary key,
+    caller character varying(100) COLLATE pg_catalog."default",
+    peer character varying(100) COLLATE pg_catalog."default",
+    method character varying(100) COLLATE pg_catalog."default",
+    contract_id character varying(10) COLLATE pg_catalog."default",
+    resource_ids jsonb,
+    request jsonb,
+    code character varying(30) COLLATE pg_catalog."default",
+    error character varying(10000) COLLATE pg_catalog."default",
+    created_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_contract_id ON audit_logs (contract_id);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at ON audit_logs (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource_ids ON audit_logs USING gin (resource_ids);
+
+-- Audit logs are append-only.
+CREATE OR REPLACE FUNCTION reject_audit_log_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit logs are append-only';
+END;
+$$ LANGUAGE plpgsql;
+CREATE TRIGGER audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION reject_audit_log_change();
@@ -17,6 +17,9 @@ CREATE TABLE clusters
     kubeconfig character varying(1000) COLLATE pg_catalog."default",
     creator uuid,
     description character varying(100) COLLATE pg_catalog."default",
+    labels jsonb,
+    annotations jsonb,
     updated_at timestamp with time zone,
     created_at timestamp with time zone
 );
+ALTER TABLE clusters ADD COLUMN IF NOT EXISTS labels jsonb, ADD COLUMN IF NOT EXISTS annotations jsonb;
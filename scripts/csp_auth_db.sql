@@ -0,0 +1,19 @@
+\c tks;
+CREATE TABLE csp_auths
+(
+    id uuid primary key,
+    csp_id uuid,
+    version integer,
+    auth character varying(10000) COLLATE pg_catalog."default",
+    rotated_at timestamp with time zone,
+    rotated_by character varying(100) COLLATE pg_catalog."default",
+    valid_until timestamp with time zone,
+    revoked_at timestamp with time zone,
+    revoked_by character varying(100) COLLATE pg_catalog."default",
+    updated_at timestamp with time zone,
+    created_at timestamp with time zone,
+    CONSTRAINT idx_csp_auths_csp_id_version UNIQUE (csp_id, version),
+    FOREIGN KEY (csp_id)
+    REFERENCES csp_infos(id) ON UPDATE CASCADE ON DELETE CASCADE
+);
+ALTER TABLE csp_infos ADD COLUMN IF NOT EXISTS auth_version integer DEFAULT 0;
@@ -4,8 +4,9 @@ CREATE TABLE csp_infos
     id uuid primary key,
     contract_id character varying(10) COLLATE pg_catalog."default",
     name character varying(50) COLLATE pg_catalog."default",
-    auth character varying(200) COLLATE pg_catalog."default",
+    auth character varying(10000) COLLATE pg_catalog."default",
     csp_type integer,
     updated_at timestamp with time zone,
     created_at timestamp with time zone
 );
+ALTER TABLE csp_infos ALTER COLUMN auth TYPE character varying(10000);
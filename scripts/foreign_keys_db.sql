@@ -0,0 +1,19 @@
+\c tks;
+-- Foreign keys between tks-info tables.
+-- Constraints are added as NOT VALID so that the migration succeeds on databases
+-- which already contain orphaned rows. Remove the orphans and run
+-- "ALTER TABLE ... VALIDATE CONSTRAINT ..." afterwards to check existing rows as well.
+ALTER TABLE clusters
+    ADD CONSTRAINT clusters_csp_id_fkey FOREIGN KEY (csp_id)
+    REFERENCES csp_infos(id) ON UPDATE CASCADE ON DELETE RESTRICT NOT VALID;
+ALTER TABLE application_groups
+    ADD CONSTRAINT application_groups_cluster_id_fkey FOREIGN KEY (cluster_id)
+    REFERENCES clusters(id) ON UPDATE CASCADE ON DELETE RESTRICT NOT VALID;
+ALTER TABLE applications
+    ADD CONSTRAINT applications_app_group_id_fkey FOREIGN KEY (app_group_id)
+    REFERENCES application_groups(id) ON UPDATE CASCADE ON DELETE CASCADE NOT VALID;
+ALTER TABLE keycloak_infos
+    ADD CONSTRAINT keycloak_infos_cluster_id_fkey FOREIGN KEY (cluster_id)
+    REFERENCES clusters(id) ON UPDATE CASCADE ON DELETE RESTRICT NOT VALID;
+-- app_serve_apps.target_cluster_id is optional and stored as an empty string
+-- when unset, so it is checked by tks-info instead of a foreign key.
@@ -0,0 +1,14 @@
+\c tks;
+CREATE TABLE idempotency_keys
+(
+    id character varying(64) COLLATE pg_catalog."default" primary key,
+    method character varying(100) COLLATE pg_catalog."default",
+    caller character varying(100) COLLATE pg_catalog."default",
+    request_hash character varying(64) COLLATE pg_catalog."default",
+    response_type character varying(100) COLLATE pg_catalog."default",
+    response bytea,
+    expires_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    created_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
@@ -0,0 +1,9 @@
+\c tks;
+CREATE TABLE leases
+(
+    name character varying(100) COLLATE pg_catalog."default" primary key,
+    holder character varying(255) COLLATE pg_catalog."default",
+    expires_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    created_at timestamp with time zone
+);
@@ -0,0 +1,8 @@
+\c tks;
+-- Trigram indexes serve case-insensitive substring and prefix searches of clusters and app groups.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+CREATE INDEX IF NOT EXISTS idx_clusters_name_trgm ON clusters USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_clusters_description_trgm ON clusters USING gin (description gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_application_groups_name_trgm ON application_groups USING gin (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_application_groups_description_trgm ON application_groups USING gin (description gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_application_groups_external_label_trgm ON application_groups USING gin (external_label gin_trgm_ops);
@@ -0,0 +1,43 @@
+\c tks;
+CREATE TABLE webhook_subscriptions
+(
+    id uuid primary key,
+    contract_id character varying(10) COLLATE pg_catalog."default",
+    url character varying(2048) COLLATE pg_catalog."default",
+    secret character varying(255) COLLATE pg_catalog."default",
+    resource_types character varying(255) COLLATE pg_catalog."default",
+    statuses character varying(1000) COLLATE pg_catalog."default",
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_contract_id ON webhook_subscriptions (contract_id);
+
+CREATE TABLE webhook_deliveries
+(
+    id uuid primary key,
+    subscription_id uuid REFERENCES webhook_subscriptions (id) ON DELETE CASCADE,
+    event_type character varying(100) COLLATE pg_catalog."default",
+    payload jsonb,
+    attempts integer,
+    next_attempt_at timestamp with time zone,
+    last_error character varying(10000) COLLATE pg_catalog."default",
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries (subscription_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_next_attempt_at ON webhook_deliveries (next_attempt_at);
+
+CREATE TABLE webhook_dead_letters
+(
+    id uuid primary key,
+    subscription_id uuid,
+    contract_id character varying(10) COLLATE pg_catalog."default",
+    url character varying(2048) COLLATE pg_catalog."default",
+    event_type character varying(100) COLLATE pg_catalog."default",
+    payload jsonb,
+    attempts integer,
+    last_error character varying(10000) COLLATE pg_catalog."default",
+    created_at timestamp with time zone
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_subscription_id ON webhook_dead_letters (subscription_id);
+CREATE INDEX IF NOT EXISTS idx_webhook_dead_letters_contract_id ON webhook_dead_letters (contract_id);